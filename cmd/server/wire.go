@@ -15,21 +15,75 @@ func InitApp() *App {
 	wire.Build(
 		ioc.InitDB,
 		ioc.InitRedis,
+		ioc.InitPasswordHasher,
+		ioc.InitPasswordPolicyOptions,
+		ioc.InitKeyRing,
+		ioc.InitOIDCOptions,
+		ioc.InitMFAOptions,
+		ioc.InitLoginLimitOptions,
+		ioc.InitRateLimiter,
+		ioc.InitCaptchaOptions,
+		ioc.InitMailer,
+		ioc.InitPasswordResetOptions,
+		ioc.InitEmailVerificationOptions,
+		ioc.InitSMSSender,
+		ioc.InitSMSOptions,
+		ioc.InitBlobStorage,
+		ioc.InitAvatarOptions,
+		ioc.InitDefaultAvatarOptions,
+		ioc.InitUserImportOptions,
 
 		// DAO 部分
 		mysql.NewUserDao,
+		mysql.NewOAuthClientDao,
+		mysql.NewUserMFADao,
+		mysql.NewPasswordHistoryDao,
+		mysql.NewAuditLogDao,
 		// Cache 部分
 
 		// repository 部分
 		repository.NewUserRepository,
+		repository.NewOAuthClientRepository,
+		repository.NewUserMFARepository,
+		repository.NewPasswordHistoryRepository,
+		repository.NewAuditLogRepository,
 
 		// service 部分
 		service.NewUserService,
+		service.NewRedisSessionService,
+		service.NewLoginStateService,
+		service.NewPasswordPolicyService,
+		service.NewProfileService,
+		service.NewUserAdminService,
+		service.NewAvatarService,
+		service.NewDefaultAvatarService,
+		service.NewUserImportService,
+		service.NewUserExportService,
+		service.NewRedisJWTService,
+		service.NewOIDCService,
+		service.NewMFAService,
+		service.NewLoginLimitService,
+		service.NewCaptchaService,
+		service.NewPasswordResetService,
+		service.NewEmailVerificationService,
+		service.NewSMSService,
 
 		// handler 部分
 		web.NewUserHandler,
+		web.NewSessionHandler,
+		web.NewProfileHandler,
+		web.NewUserAdminHandler,
+		web.NewDefaultAvatarHandler,
+		web.NewMFAHandler,
+		web.NewCaptchaHandler,
+		web.NewPasswordResetHandler,
+		web.NewEmailVerificationHandler,
+		web.NewWellKnownHandler,
+		web.NewOIDCHandler,
+		web.NewOAuthClientHandler,
 
 		// hertz 的中间件
+		ioc.InitAuthMode,
 		ioc.CommonMiddlewares,
 
 		// Web 服务器
@@ -17,13 +17,68 @@ import (
 // Injectors from wire.go:
 
 func InitApp() *App {
-	v := ioc.CommonMiddlewares()
+	authMode := ioc.InitAuthMode()
+	cmdable := ioc.InitRedis()
+	keyRing := ioc.InitKeyRing()
+	sessionService := service.NewRedisSessionService(cmdable)
+	jwtService := service.NewRedisJWTService(cmdable, keyRing, sessionService)
 	db := ioc.InitDB()
 	userDAO := mysql.NewUserDao(db)
 	userRepository := repository.NewUserRepository(userDAO)
-	userService := service.NewUserService(userRepository)
-	userHandler := web.NewUserHandler(userService)
-	hertz := ioc.InitWebServer(v, userHandler)
+	loginStateService := service.NewLoginStateService(userRepository, cmdable)
+	limiter := ioc.InitRateLimiter(cmdable)
+	v := ioc.CommonMiddlewares(authMode, jwtService, loginStateService, limiter)
+	auditLogDAO := mysql.NewAuditLogDao(db)
+	auditLogRepository := repository.NewAuditLogRepository(auditLogDAO)
+	passwordHasher := ioc.InitPasswordHasher()
+	passwordPolicyOptions := ioc.InitPasswordPolicyOptions()
+	passwordHistoryDAO := mysql.NewPasswordHistoryDao(db)
+	passwordHistoryRepository := repository.NewPasswordHistoryRepository(passwordHistoryDAO)
+	passwordPolicyService := service.NewPasswordPolicyService(passwordPolicyOptions, passwordHistoryRepository, passwordHasher)
+	loginLimitOptions := ioc.InitLoginLimitOptions()
+	loginLimitService := service.NewLoginLimitService(loginLimitOptions, cmdable)
+	userService := service.NewUserService(userRepository, auditLogRepository, passwordHasher, loginStateService, passwordPolicyService, sessionService, loginLimitService)
+	mfaOptions := ioc.InitMFAOptions()
+	userMFADAO := mysql.NewUserMFADao(db)
+	userMFARepository := repository.NewUserMFARepository(userMFADAO)
+	mfaService := service.NewMFAService(mfaOptions, cmdable, userMFARepository, userRepository)
+	captchaOptions := ioc.InitCaptchaOptions()
+	captchaService := service.NewCaptchaService(captchaOptions, cmdable, loginLimitService)
+	emailVerificationOptions := ioc.InitEmailVerificationOptions()
+	mailer := ioc.InitMailer()
+	emailVerificationService := service.NewEmailVerificationService(emailVerificationOptions, cmdable, keyRing, userRepository, mailer)
+	smsOptions := ioc.InitSMSOptions()
+	sender := ioc.InitSMSSender()
+	defaultAvatarOptions := ioc.InitDefaultAvatarOptions()
+	defaultAvatarService := service.NewDefaultAvatarService(defaultAvatarOptions)
+	smsService := service.NewSMSService(smsOptions, cmdable, sender, userRepository, passwordHasher, defaultAvatarService)
+	userHandler := web.NewUserHandler(userService, jwtService, sessionService, mfaService, loginLimitService, captchaService, emailVerificationService, smsService, defaultAvatarService, authMode)
+	sessionHandler := web.NewSessionHandler(sessionService)
+	profileService := service.NewProfileService(userRepository, userService, smsOptions, smsService, emailVerificationService, loginStateService)
+	avatarOptions := ioc.InitAvatarOptions()
+	blobStorage := ioc.InitBlobStorage()
+	avatarService := service.NewAvatarService(avatarOptions, blobStorage, userRepository, userService)
+	profileHandler := web.NewProfileHandler(profileService, avatarService, smsService)
+	userAdminService := service.NewUserAdminService(userRepository, auditLogRepository, userService, profileService, loginStateService, sessionService, defaultAvatarService)
+	userImportOptions := ioc.InitUserImportOptions()
+	userImportService := service.NewUserImportService(userImportOptions, cmdable, userService, userAdminService)
+	userExportService := service.NewUserExportService(userRepository, auditLogRepository)
+	userAdminHandler := web.NewUserAdminHandler(userAdminService, userImportService, userExportService)
+	defaultAvatarHandler := web.NewDefaultAvatarHandler(defaultAvatarService)
+	mfaHandler := web.NewMFAHandler(mfaService)
+	captchaHandler := web.NewCaptchaHandler(captchaService)
+	passwordResetOptions := ioc.InitPasswordResetOptions()
+	passwordResetService := service.NewPasswordResetService(passwordResetOptions, cmdable, userRepository, passwordHasher, mailer, sessionService, loginStateService, passwordPolicyService)
+	passwordResetHandler := web.NewPasswordResetHandler(passwordResetService)
+	emailVerificationHandler := web.NewEmailVerificationHandler(emailVerificationService)
+	wellKnownHandler := web.NewWellKnownHandler(jwtService)
+	oidcOptions := ioc.InitOIDCOptions()
+	oAuthClientDAO := mysql.NewOAuthClientDao(db)
+	oAuthClientRepository := repository.NewOAuthClientRepository(oAuthClientDAO)
+	oidcService := service.NewOIDCService(oidcOptions, cmdable, oAuthClientRepository, userService, jwtService, passwordHasher)
+	oidcHandler := web.NewOIDCHandler(oidcService)
+	oAuthClientHandler := web.NewOAuthClientHandler(oidcService)
+	hertz := ioc.InitWebServer(v, userHandler, sessionHandler, profileHandler, userAdminHandler, defaultAvatarHandler, mfaHandler, captchaHandler, passwordResetHandler, emailVerificationHandler, wellKnownHandler, oidcHandler, oAuthClientHandler)
 	app := &App{
 		web: hertz,
 	}
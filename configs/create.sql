@@ -6,20 +6,123 @@ create table if not exists user
 (
   `id`            bigint auto_increment comment '主键ID'
     primary key,
-  `username`      varchar(256)                       null comment '用户昵称',
-  `user_account`  varchar(256)                       null comment '账号',
+  `username`      varchar(256) default ''            not null comment '用户昵称',
+  `user_account`  varchar(256) default ''            not null comment '账号',
   `avatar_url`    varchar(1024)                      null comment '用户头像',
   `gender`        tinyint                            null comment '性别',
   `user_password` varchar(512)                       not null comment '密码',
-  `phone`         varchar(128)                       null comment '电话',
+  `phone`         varchar(128)                       null comment '电话, E.164 格式',
   `email`         varchar(512)                       null comment '邮箱',
   `user_status`   int      default 0                 not null comment '用户状态 0-正常',
-  `create_time`   datetime default CURRENT_TIMESTAMP null comment '创建时间',
-  `update_time`   datetime default CURRENT_TIMESTAMP null on update CURRENT_TIMESTAMP comment '更新时间',
+  `create_time`   datetime default CURRENT_TIMESTAMP not null comment '创建时间',
+  `update_time`   datetime default CURRENT_TIMESTAMP not null on update CURRENT_TIMESTAMP comment '更新时间',
   `is_delete`     tinyint  default 0                 not null comment '是否删除（逻辑删除）',
   `user_role`     int      default 0                 not null comment '用户角色 0-普通用户 1-管理员',
-  `planet_code`   varchar(512)                       null comment '星球编号'
+  `planet_code`   varchar(512) default ''            not null comment '星球编号',
+  `security_version` int   default 0                 not null comment '安全版本, 修改密码后递增, 已签发的登录态随之失效',
+  `freeze_reason` varchar(512)                       null comment '冻结原因',
+  `freeze_until`  datetime                           null comment '冻结截止时间, 为空表示永久冻结',
+  `pre_freeze_status` int  default 0                 not null comment '冻结前的用户状态, 解冻时恢复',
+  key idx_phone (`phone`),
+  key idx_email (`email`),
+  -- 允许排序的列, 游标分页按 (列, id) 比较和排序
+  key idx_username_id (`username`, `id`),
+  key idx_user_account_id (`user_account`, `id`),
+  key idx_planet_code_id (`planet_code`, `id`),
+  key idx_user_status_id (`user_status`, `id`),
+  key idx_user_role_id (`user_role`, `id`),
+  key idx_create_time_id (`create_time`, `id`),
+  key idx_update_time_id (`update_time`, `id`)
 )
   comment '用户';
 
+-- 历史数据中的手机号规范化为 E.164 格式, 规则与 sms.NormalizePhone 一致:
+-- 去掉分隔符, 00 开头的国际号码改为 + 开头, 未携带国家码的号码去掉开头的 0 后补充 sms.default-country-code
+set @default_country_code = '86';
+update user set phone = regexp_replace(phone, '[ ().-]', '') where phone regexp '[ ().-]';
+update user set phone = concat('+', substring(phone, 3)) where phone regexp '^00[0-9]+$';
+update user set phone = concat('+', @default_country_code, if(phone like '0%', substring(phone, 2), phone))
+  where phone regexp '^[0-9]+$';
+-- 规范化后仍然不符合 E.164 格式的号码无法用于短信登录, 需要人工处理:
+-- select id, phone from user where phone <> '' and phone not regexp '^\\+[1-9][0-9]{7,14}$';
+
+-- 已有的数据库需要补充安全版本字段:
+-- alter table user add column `security_version` int default 0 not null comment '安全版本, 修改密码后递增, 已签发的登录态随之失效';
+-- 邮箱未填写时保存为空字符串, 不能建唯一索引, 按邮箱查找时匹配到多个用户会被拒绝:
+-- alter table user add key idx_email (`email`);
+-- alter table user add column `freeze_reason` varchar(512) null comment '冻结原因', add column `freeze_until` datetime null comment '冻结截止时间, 为空表示永久冻结';
+-- alter table user add column `pre_freeze_status` int default 0 not null comment '冻结前的用户状态, 解冻时恢复';
+-- 游标分页直接比较排序列, 排序列不能为 NULL, 并且需要 (列, id) 联合索引:
+-- update user set username = '' where username is null;
+-- update user set user_account = '' where user_account is null;
+-- update user set planet_code = '' where planet_code is null;
+-- update user set create_time = '1970-01-01 00:00:01' where create_time is null;
+-- update user set update_time = create_time where update_time is null;
+-- alter table user
+--   modify `username` varchar(256) default '' not null comment '用户昵称',
+--   modify `user_account` varchar(256) default '' not null comment '账号',
+--   modify `planet_code` varchar(512) default '' not null comment '星球编号',
+--   modify `create_time` datetime default CURRENT_TIMESTAMP not null comment '创建时间',
+--   modify `update_time` datetime default CURRENT_TIMESTAMP not null on update CURRENT_TIMESTAMP comment '更新时间',
+--   add key idx_username_id (`username`, `id`),
+--   add key idx_user_account_id (`user_account`, `id`),
+--   add key idx_planet_code_id (`planet_code`, `id`),
+--   add key idx_user_status_id (`user_status`, `id`),
+--   add key idx_user_role_id (`user_role`, `id`),
+--   add key idx_create_time_id (`create_time`, `id`),
+--   add key idx_update_time_id (`update_time`, `id`);
+
 insert into user(`username`, `user_account`, avatar_url, gender, user_password, user_role, planet_code) value ('Lewin', 'lewin', 'https://cos-coder-lu-1302078010.cos.ap-guangzhou.myqcloud.com/pics%2Fmylogo.png', 0, '9825417a996f1b031543e79ab88ec7ea', 1, '1');
+
+create table if not exists oauth_client
+(
+  `id`            bigint auto_increment comment '主键ID'
+    primary key,
+  `client_id`     varchar(64)                        not null comment '客户端ID',
+  `client_secret` varchar(512)                       not null comment '客户端密钥(哈希)',
+  `name`          varchar(256)                       not null comment '应用名称',
+  `redirect_uris` varchar(4096)                      not null comment '回调地址, 多个以换行分隔',
+  `create_time`   datetime default CURRENT_TIMESTAMP null comment '创建时间',
+  `update_time`   datetime default CURRENT_TIMESTAMP null on update CURRENT_TIMESTAMP comment '更新时间',
+  `is_delete`     tinyint  default 0                 not null comment '是否删除（逻辑删除）',
+  unique key uk_client_id (`client_id`)
+)
+  comment 'OIDC 客户端应用';
+
+create table if not exists user_mfa
+(
+  `user_id`        bigint                             not null comment '用户ID'
+    primary key,
+  `totp_secret`    varchar(128)                       not null comment 'TOTP 密钥',
+  `enabled`        tinyint  default 0                 not null comment '是否已启用 0-待确认 1-已启用',
+  `recovery_codes` varchar(1024)                      null comment '恢复码哈希, 多个以换行分隔',
+  `create_time`    datetime default CURRENT_TIMESTAMP null comment '创建时间',
+  `update_time`    datetime default CURRENT_TIMESTAMP null on update CURRENT_TIMESTAMP comment '更新时间'
+)
+  comment '用户两步验证';
+
+create table if not exists user_password_history
+(
+  `id`            bigint auto_increment comment '主键ID'
+    primary key,
+  `user_id`       bigint                             not null comment '用户ID',
+  `user_password` varchar(512)                       not null comment '密码哈希',
+  `create_time`   datetime default CURRENT_TIMESTAMP null comment '创建时间',
+  key idx_user_id (`user_id`, `id`)
+)
+  comment '用户历史密码';
+
+create table if not exists user_audit_log
+(
+  `id`          bigint auto_increment comment '主键ID'
+    primary key,
+  `user_id`     bigint                             not null comment '被操作的用户ID',
+  `operator_id` bigint                             not null comment '操作人ID',
+  `action`      varchar(64)                        not null comment '操作类型',
+  `detail`      varchar(1024)                      null comment '操作详情, 如冻结原因',
+  `ip`          varchar(64)                        null comment '操作人 IP',
+  `user_agent`  varchar(512)                       null comment '操作人 User-Agent',
+  `create_time` datetime default CURRENT_TIMESTAMP null comment '创建时间',
+  key idx_user_id (`user_id`, `id`)
+)
+  comment '用户操作审计日志';
@@ -9,14 +9,18 @@ const (
 
 	UserStatusDisabled = 1
 
+	// UserStatusPending 注册后等待验证邮箱
+	UserStatusPending = 2
+
+	// 性别 0-未知 1-男 2-女
+	GenderUnknown = 0
+	GenderMale    = 1
+	GenderFemale  = 2
+
 	UserLoginState = "userLoginState"
 
 	LoginUser = "loginUser"
 
-	DefaultAvatar = "https://cos-coder-lu-1302078010.cos.ap-guangzhou.myqcloud.com/pics%2Fmylogo.png"
-)
-
-var (
-	AccessTokenKey  = []byte("moyn8y9abnd7q4zkq2m73yw8tu9j5ixm")
-	RefreshTokenKey = []byte("moyn8y9abnd7q4zkq2m73yw8tu9j5ixA")
+	// SessionID 会话中保存的登录会话标识, 用于吊销会话
+	SessionID = "ssid"
 )
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// 审计日志的操作类型
+const (
+	AuditActionPasswordChange = "password_change"
+	AuditActionUserFreeze     = "user_freeze"
+	AuditActionUserUnfreeze   = "user_unfreeze"
+	AuditActionRoleChange     = "role_change"
+	AuditActionProfileUpdate  = "profile_update"
+	AuditActionUserCreate     = "user_create"
+	AuditActionUserExport     = "user_export"
+)
+
+// AuditLog 用户账号相关的敏感操作记录
+type AuditLog struct {
+	ID int64
+	// UserID 被操作的用户, 导出等批量操作时为 0
+	UserID int64
+	// OperatorID 操作人, 用户自己操作时与 UserID 相同
+	OperatorID int64
+	Action     string
+	// Detail 操作详情, 如冻结原因、修改后的角色
+	Detail     string
+	IP         string
+	UserAgent  string
+	CreateTime time.Time
+}
+
+// Operator 操作人及其客户端信息, 用于记录审计日志
+type Operator struct {
+	ID        int64
+	IP        string
+	UserAgent string
+}
@@ -0,0 +1,7 @@
+package domain
+
+// Avatar 上传后的头像, URL 为用户头像地址, Sizes 为各尺寸的地址
+type Avatar struct {
+	URL   string
+	Sizes map[int]string
+}
@@ -0,0 +1,7 @@
+package domain
+
+// Captcha 图形验证码, 答案只保存在服务端
+type Captcha struct {
+	ID    string
+	Image []byte
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+const (
+	LoginLockByAccount = "account"
+	LoginLockByIP      = "ip"
+)
+
+// LoginLock 登录失败的统计和锁定状态
+type LoginLock struct {
+	// Type 统计维度, 按账号或按客户端 IP
+	Type     string
+	Target   string
+	Failures int64
+	// RetryAfter 剩余锁定时长, 为 0 表示未锁定
+	RetryAfter time.Duration
+}
+
+func (l LoginLock) Locked() bool {
+	return l.RetryAfter > 0
+}
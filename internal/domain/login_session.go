@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+const (
+	LoginSessionCookie = "session"
+	LoginSessionJWT    = "jwt"
+)
+
+// LoginSession 一次登录产生的会话, 会话认证和 JWT 认证共用
+type LoginSession struct {
+	ID string
+	// Type 认证方式, session 或 jwt
+	Type         string
+	UserID       int64
+	UserAgent    string
+	IP           string
+	CreateTime   time.Time
+	LastSeenTime time.Time
+}
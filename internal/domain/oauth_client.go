@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/utils"
+	"github.com/duke-git/lancet/v2/slice"
+	"net/url"
+	"time"
+)
+
+// OAuthClient 接入 OIDC 的客户端应用
+type OAuthClient struct {
+	ID           int64
+	ClientID     string
+	ClientSecret string   // 客户端密钥, 保存时为哈希值
+	Name         string   // 应用名称
+	RedirectURIs []string // 允许的回调地址, 需要完全匹配
+	CreateTime   time.Time
+	UpdateTime   time.Time
+}
+
+// HasRedirectURI 判断回调地址是否已登记
+func (c *OAuthClient) HasRedirectURI(uri string) bool {
+	return slice.Contain(c.RedirectURIs, uri)
+}
+
+func (c *OAuthClient) ValidateParameters() error {
+	if utils.IsAnyStringBlank(c.Name) || len(c.RedirectURIs) == 0 {
+		return errno.ErrParameterInvalid
+	}
+
+	// 回调地址必须是绝对地址且不能带有 fragment
+	for _, uri := range c.RedirectURIs {
+		u, err := url.Parse(uri)
+		if err != nil || !u.IsAbs() || u.Host == "" || u.Fragment != "" {
+			return errno.ErrParameterInvalid.SetDescription("回调地址 %s 不合法", uri)
+		}
+	}
+	return nil
+}
+
+// AuthorizeRequest 授权码流程中的授权请求
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               []string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// AuthorizationCode 授权码对应的授权信息
+type AuthorizationCode struct {
+	ClientID            string    `json:"client_id"`
+	RedirectURI         string    `json:"redirect_uri"`
+	UserID              int64     `json:"user_id"`
+	Scope               []string  `json:"scope"`
+	Nonce               string    `json:"nonce"`
+	CodeChallenge       string    `json:"code_challenge"`
+	CodeChallengeMethod string    `json:"code_challenge_method"`
+	AuthTime            time.Time `json:"auth_time"`
+}
+
+// TokenRequest 令牌端点的请求
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	ClientID     string
+	ClientSecret string
+	CodeVerifier string
+}
+
+// TokenResult 令牌端点的响应
+type TokenResult struct {
+	AccessToken string
+	TokenType   string
+	ExpiresIn   int64
+	IDToken     string
+	Scope       []string
+}
+
+// HasScope 判断授权范围中是否包含 scope
+func HasScope(scopes []string, scope string) bool {
+	return slice.Contain(scopes, scope)
+}
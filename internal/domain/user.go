@@ -1,11 +1,13 @@
 package domain
 
 import (
+	"errors"
 	"github.com/coderlewin/ucenter/internal/constants"
 	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/hasher"
 	"github.com/coderlewin/ucenter/pkg/utils"
 	"github.com/duke-git/lancet/v2/compare"
-	"github.com/duke-git/lancet/v2/cryptor"
+	"github.com/duke-git/lancet/v2/validator"
 	"time"
 )
 
@@ -13,25 +15,37 @@ type User struct {
 	ID            int64
 	Username      string
 	UserAccount   string
-	AvatarURL     string    // 用户头像
-	Gender        int32     // 性别
-	UserPassword  string    // 密码
-	CheckPassword string    // 确认密码
-	Phone         string    // 电话
-	Email         string    // 邮箱
-	UserStatus    int32     // 用户状态 0-正常
-	UserRole      int32     // 用户角色 0-普通用户 1-管理员
-	PlanetCode    string    // 星球编号
-	CreateTime    time.Time // 创建时间
-	UpdateTime    time.Time // 更新时间
+	AvatarURL     string // 用户头像
+	Gender        int32  // 性别
+	UserPassword  string // 密码
+	CheckPassword string // 确认密码
+	Phone         string // 电话
+	Email         string // 邮箱
+	UserStatus    int32  // 用户状态 0-正常
+	UserRole      int32  // 用户角色 0-普通用户 1-管理员
+	PlanetCode    string // 星球编号
+	// SecurityVersion 安全版本, 与登录态中记录的不一致时登录态失效
+	SecurityVersion int32
+	FreezeReason    string    // 冻结原因
+	FreezeUntil     time.Time // 冻结截止时间, 零值表示永久冻结
+	CreateTime      time.Time // 创建时间
+	UpdateTime      time.Time // 更新时间
 }
 
 func (u *User) IsAdmin() bool {
 	return u.UserRole == constants.AdminRole
 }
 
+// IsFreeze 是否处于冻结状态, 设置了截止时间的到期后自动解冻
 func (u *User) IsFreeze() bool {
-	return u.UserStatus == constants.UserStatusDisabled
+	if u.UserStatus != constants.UserStatusDisabled {
+		return false
+	}
+	return u.FreezeUntil.IsZero() || time.Now().Before(u.FreezeUntil)
+}
+
+func (u *User) IsPending() bool {
+	return u.UserStatus == constants.UserStatusPending
 }
 
 func (u *User) ValidateLoginParameters() error {
@@ -58,38 +72,84 @@ func (u *User) ValidateLoginParameters() error {
 	return nil
 }
 
+// ValidateRegisterParameters 校验注册参数, 密码的长度、复杂度等由密码策略校验
 func (u *User) ValidateRegisterParameters() error {
 	// 参数不能为空
 	if utils.IsAnyStringBlank(u.UserAccount, u.UserPassword, u.CheckPassword, u.PlanetCode) {
 		return errno.ErrParameterInvalid
 	}
+	if reason := u.CheckRegisterParameters(); reason != "" {
+		return errno.ErrParameterInvalid.SetDescription(reason)
+	}
+	return nil
+}
+
+// CheckRegisterParameters 返回注册参数不合法的原因, 合法时返回空字符串. 不修改共享的错误变量
+func (u *User) CheckRegisterParameters() string {
+	if utils.IsAnyStringBlank(u.UserAccount, u.UserPassword, u.CheckPassword, u.PlanetCode) {
+		return "账号、密码和星球编号不能为空"
+	}
 
 	// 账号长度不小于 4
 	if len(u.UserAccount) < 4 {
-		return errno.ErrParameterInvalid.SetDescription("账号长度过短")
+		return "账号长度过短"
 	}
 
-	// 密码长度不小于 8
-	if len(u.UserPassword) < 8 || len(u.CheckPassword) < 8 {
-		return errno.ErrParameterInvalid.SetDescription("密码长度过短")
-	}
 	// 账号不能包含特殊字符
 	if utils.HasSpecialText(u.UserAccount) {
-		return errno.ErrParameterInvalid.SetDescription("账号包含特殊字符")
+		return "账号包含特殊字符"
 	}
 
 	// 密码和确认密码相等
 	if !compare.Equal(u.UserPassword, u.CheckPassword) {
-		return errno.ErrParameterInvalid.SetDescription("密码和校验密码不一致")
+		return "密码和校验密码不一致"
 	}
 
 	// 星球编号长度不大于 5
 	if len(u.PlanetCode) > 5 {
-		return errno.ErrParameterInvalid.SetDescription("星球编号长度过长")
+		return "星球编号长度过长"
+	}
+
+	// 邮箱选填, 填写时需要校验格式
+	if u.Email != "" && !validator.IsEmail(u.Email) {
+		return "邮箱格式错误"
+	}
+	return ""
+}
+
+// ValidatePassword 校验新密码和确认密码, 用于重置密码等只设置密码的场景
+// 密码的长度、复杂度等由密码策略校验
+func (u *User) ValidatePassword() error {
+	if utils.IsAnyStringBlank(u.UserPassword, u.CheckPassword) {
+		return errno.ErrParameterInvalid
+	}
+
+	// 密码和确认密码相等
+	if !compare.Equal(u.UserPassword, u.CheckPassword) {
+		return errno.ErrParameterInvalid.SetDescription("密码和校验密码不一致")
+	}
+	return nil
+}
+
+// EncryptPassword 使用 h 对明文密码进行哈希
+func (u *User) EncryptPassword(h hasher.PasswordHasher) error {
+	encoded, err := h.Hash(u.UserPassword)
+	if errors.Is(err, hasher.ErrPasswordTooLong) {
+		return errno.ErrParameterInvalid.SetDescription("密码过长")
 	}
+	if err != nil {
+		return err
+	}
+	u.UserPassword = encoded
 	return nil
 }
 
-func (u *User) EncryptPassword() {
-	u.UserPassword = cryptor.Md5String(constants.PwdSalt + u.UserPassword)
+// ComparePassword 校验明文密码 plain 是否与已存储的密码哈希匹配.
+// 已存储的哈希无法识别或格式错误时视为不匹配, 由调用方按密码错误处理
+func (u *User) ComparePassword(h hasher.PasswordHasher, plain string) (bool, error) {
+	ok, err := h.Verify(plain, u.UserPassword)
+	if errors.Is(err, hasher.ErrUnknownScheme) || errors.Is(err, hasher.ErrInvalidHash) {
+		return false, nil
+	}
+	return ok, err
 }
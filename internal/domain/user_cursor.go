@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"strings"
+	"time"
+)
+
+// CountMode 游标分页时统计总数的方式
+type CountMode string
+
+const (
+	CountNone   CountMode = "none"   // 不统计总数
+	CountExact  CountMode = "exact"  // 使用 COUNT(*) 精确统计
+	CountApprox CountMode = "approx" // 使用执行计划中估算的行数, 结果可能偏差较大
+)
+
+// UserCursorQuery 游标分页查询用户的条件, 翻页时不会因为新注册的用户出现重复或遗漏
+type UserCursorQuery struct {
+	Filter UserFilter
+	Sorts  []UserSort // 为空时按 id 升序
+	Cursor string     // 上一页返回的游标, 为空时查询第一页
+	Size   int        // 每页数量
+	Count  CountMode  // 为空时不统计总数
+}
+
+// Validate 校验每页数量、统计方式、筛选条件和排序字段
+func (q UserCursorQuery) Validate() error {
+	if q.Size < 1 || q.Size > MaxPageSize {
+		return errno.ErrParameterInvalid.SetDescription("每页数量必须在 1-%d 之间", MaxPageSize)
+	}
+	switch q.Count {
+	case "", CountNone, CountExact, CountApprox:
+	default:
+		return errno.ErrParameterInvalid.SetDescription("统计方式只能是 none、exact 或 approx")
+	}
+	for _, sort := range q.Sorts {
+		if _, ok := userSortFields[sort.Field]; !ok {
+			return errno.ErrParameterInvalid.SetDescription("不支持按 %s 排序", sort.Field)
+		}
+	}
+	return q.Filter.Validate()
+}
+
+// KeysetSorts 游标分页实际使用的排序, 以 id 结尾保证顺序唯一.
+// id 之后的排序字段不影响顺序, 会被丢弃; 没有 id 时追加 id 升序
+func (q UserCursorQuery) KeysetSorts() []UserSort {
+	sorts := make([]UserSort, 0, len(q.Sorts)+1)
+	for _, sort := range q.Sorts {
+		sorts = append(sorts, sort)
+		if sort.Field == "id" {
+			return sorts
+		}
+	}
+	return append(sorts, UserSort{Field: "id"})
+}
+
+// UserCursorPage 游标分页的结果
+type UserCursorPage struct {
+	Users      []User
+	NextCursor string // 下一页的游标, 为空表示没有下一页
+	Total      int64  // 总数, 未统计时为 -1
+	// Approximate 总数是否为估算值
+	Approximate bool
+}
+
+// userCursor 游标的内容, Sorts 用于识别排序条件变化后继续使用的旧游标
+type userCursor struct {
+	Sorts  string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+// EncodeUserCursor 将 user 在 sorts 各字段上的取值编码为不透明的游标, sorts 需要以 id 结尾
+func EncodeUserCursor(sorts []UserSort, user User) string {
+	cursor := userCursor{Sorts: formatUserSorts(sorts), Values: make([]any, 0, len(sorts))}
+	for _, sort := range sorts {
+		value := userSortFields[sort.Field](user)
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(time.RFC3339Nano)
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeUserCursor 解码游标, 按 sorts 的顺序返回各字段的取值
+func DecodeUserCursor(sorts []UserSort, s string) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errno.ErrParameterInvalid.SetDescription("游标无效")
+	}
+	var cursor userCursor
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err = decoder.Decode(&cursor); err != nil {
+		return nil, errno.ErrParameterInvalid.SetDescription("游标无效")
+	}
+	if cursor.Sorts != formatUserSorts(sorts) || len(cursor.Values) != len(sorts) {
+		return nil, errno.ErrParameterInvalid.SetDescription("游标与排序条件不匹配")
+	}
+	values := make([]any, 0, len(sorts))
+	for i, sort := range sorts {
+		value, ok := decodeCursorValue(userSortFields[sort.Field](User{}), cursor.Values[i])
+		if !ok {
+			return nil, errno.ErrParameterInvalid.SetDescription("游标无效")
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// decodeCursorValue 按字段零值的类型还原游标中的取值
+func decodeCursorValue(zero any, raw any) (any, bool) {
+	switch zero.(type) {
+	case int64:
+		n, ok := raw.(json.Number)
+		if !ok {
+			return nil, false
+		}
+		v, err := n.Int64()
+		return v, err == nil
+	case time.Time:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, false
+		}
+		v, err := time.Parse(time.RFC3339Nano, s)
+		return v, err == nil
+	default:
+		v, ok := raw.(string)
+		return v, ok
+	}
+}
+
+// formatUserSorts 将排序条件格式化为 ParseUserSorts 接受的形式
+func formatUserSorts(sorts []UserSort) string {
+	items := make([]string, 0, len(sorts))
+	for _, sort := range sorts {
+		if sort.Desc {
+			items = append(items, "-"+sort.Field)
+		} else {
+			items = append(items, sort.Field)
+		}
+	}
+	return strings.Join(items, ",")
+}
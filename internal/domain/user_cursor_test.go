@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"encoding/base64"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestUserCursor_RoundTrip(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.FixedZone("CST", 8*3600))
+	user := User{ID: 42, Username: "coderlu", UserRole: 1, CreateTime: created}
+
+	sorts := []UserSort{{Field: "create_time", Desc: true}, {Field: "username"}, {Field: "user_role"}, {Field: "id"}}
+	values, err := DecodeUserCursor(sorts, EncodeUserCursor(sorts, user))
+	require.NoError(t, err)
+	require.Len(t, values, 4)
+	// 时间保留纳秒精度, 时区不影响比较
+	got, ok := values[0].(time.Time)
+	require.True(t, ok)
+	assert.True(t, created.Equal(got), got)
+	assert.Equal(t, []any{"coderlu", int64(1), int64(42)}, values[1:])
+}
+
+func TestDecodeUserCursor(t *testing.T) {
+	sorts := []UserSort{{Field: "create_time"}, {Field: "id"}}
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+	testCases := []struct {
+		name   string
+		cursor string
+
+		wantDesc string
+	}{
+		{
+			name:     "不是 base64",
+			cursor:   "not a cursor!",
+			wantDesc: "游标无效",
+		},
+		{
+			name:     "不是 JSON",
+			cursor:   encode("{"),
+			wantDesc: "游标无效",
+		},
+		{
+			name:     "排序条件不同",
+			cursor:   EncodeUserCursor([]UserSort{{Field: "username"}, {Field: "id"}}, User{ID: 1}),
+			wantDesc: "游标与排序条件不匹配",
+		},
+		{
+			name:     "排序方向不同",
+			cursor:   EncodeUserCursor([]UserSort{{Field: "create_time", Desc: true}, {Field: "id"}}, User{ID: 1}),
+			wantDesc: "游标与排序条件不匹配",
+		},
+		{
+			name:     "取值个数被篡改",
+			cursor:   encode(`{"s":"create_time,id","v":["2024-01-02T03:04:05Z"]}`),
+			wantDesc: "游标与排序条件不匹配",
+		},
+		{
+			name:     "时间字段的取值类型不匹配",
+			cursor:   encode(`{"s":"create_time,id","v":[1704164645,1]}`),
+			wantDesc: "游标无效",
+		},
+		{
+			name:     "时间格式错误",
+			cursor:   encode(`{"s":"create_time,id","v":["2024-01-02",1]}`),
+			wantDesc: "游标无效",
+		},
+		{
+			name:     "id 的取值类型不匹配",
+			cursor:   encode(`{"s":"create_time,id","v":["2024-01-02T03:04:05Z","1"]}`),
+			wantDesc: "游标无效",
+		},
+		{
+			name:     "id 不是整数",
+			cursor:   encode(`{"s":"create_time,id","v":["2024-01-02T03:04:05Z",1.5]}`),
+			wantDesc: "游标无效",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := DecodeUserCursor(sorts, tc.cursor)
+			assert.Nil(t, values)
+			assert.Equal(t, errno.ErrParameterInvalid, err)
+			assert.Equal(t, tc.wantDesc, errno.ErrParameterInvalid.Desc)
+		})
+	}
+}
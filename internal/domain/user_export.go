@@ -0,0 +1,10 @@
+package domain
+
+// UserExport 导出用户的参数
+type UserExport struct {
+	Filter  UserFilter
+	Format  string   // csv、xlsx 或 jsonl
+	Columns []string // 导出的列, 为空时使用默认列
+	// Unmask 为 true 时导出完整的手机号和邮箱, 否则部分隐藏
+	Unmask bool
+}
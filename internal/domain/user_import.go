@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// 批量导入任务的状态
+const (
+	ImportStatusRunning  = "running"
+	ImportStatusFinished = "finished"
+	ImportStatusFailed   = "failed"
+)
+
+// ImportJob 批量导入用户的后台任务
+type ImportJob struct {
+	ID string
+	// DryRun 只校验不导入
+	DryRun     bool
+	Status     string
+	OperatorID int64
+	Total      int // 数据行数, 不含表头
+	Processed  int // 已处理的行数
+	Succeeded  int // 校验通过或导入成功的行数
+	Failed     int
+	Errors     []ImportRowError
+	CreateTime time.Time
+	FinishTime time.Time // 未结束时为零值
+}
+
+// ImportRowError 单行的错误, Row 为表格中的行号, 表头为第 1 行
+type ImportRowError struct {
+	Row     int
+	Account string
+	Message string
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// UserMFA 用户的两步验证配置
+type UserMFA struct {
+	UserID        int64
+	TOTPSecret    string   // TOTP 密钥
+	Enabled       bool     // 是否已确认启用
+	RecoveryCodes []string // 恢复码的哈希
+}
+
+// UseRecoveryCode 校验恢复码, 成功时将其从可用列表中移除
+func (m *UserMFA) UseRecoveryCode(code string) bool {
+	hashed := HashRecoveryCode(code)
+	for i, c := range m.RecoveryCodes {
+		if c == hashed {
+			m.RecoveryCodes = append(m.RecoveryCodes[:i:i], m.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// HashRecoveryCode 恢复码为高熵随机串, 使用 SHA-256 保存即可, 忽略大小写和分隔符
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
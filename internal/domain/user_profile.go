@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/duke-git/lancet/v2/validator"
+	"strings"
+	"unicode/utf8"
+)
+
+// 昵称长度限制, 按字符计算
+const (
+	UsernameMinLength = 1
+	UsernameMaxLength = 32
+)
+
+// UserProfile 用户可以自行修改的资料, 为 nil 的字段不修改
+type UserProfile struct {
+	Username  *string
+	AvatarURL *string
+	Gender    *int32
+	// Phone 为空字符串时解除绑定
+	Phone *string
+	// PhoneCode 用户修改手机号时, 发送到新手机号的验证码
+	PhoneCode string
+	// Email 为空字符串时解除绑定
+	Email *string
+}
+
+func (p *UserProfile) IsEmpty() bool {
+	return p.Username == nil && p.AvatarURL == nil && p.Gender == nil && p.Phone == nil && p.Email == nil
+}
+
+// Validate 校验资料格式, 手机号的格式由调用方规范化时校验
+func (p *UserProfile) Validate() error {
+	if p.IsEmpty() {
+		return errno.ErrParameterInvalid.SetDescription("没有需要修改的资料")
+	}
+
+	if p.Username != nil {
+		name := strings.TrimSpace(*p.Username)
+		if n := utf8.RuneCountInString(name); n < UsernameMinLength || n > UsernameMaxLength {
+			return errno.ErrParameterInvalid.SetDescription("昵称长度必须在 1-32 个字符之间")
+		}
+		p.Username = &name
+	}
+
+	if p.AvatarURL != nil {
+		url := strings.TrimSpace(*p.AvatarURL)
+		if url != "" && (len(url) > 1024 || !validator.IsUrl(url) ||
+			!(strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://"))) {
+			return errno.ErrParameterInvalid.SetDescription("头像地址格式错误")
+		}
+		p.AvatarURL = &url
+	}
+
+	if p.Gender != nil {
+		switch *p.Gender {
+		case constants.GenderUnknown, constants.GenderMale, constants.GenderFemale:
+		default:
+			return errno.ErrParameterInvalid.SetDescription("性别只能是 0-未知 1-男 2-女")
+		}
+	}
+
+	if p.Email != nil {
+		email := strings.TrimSpace(*p.Email)
+		if email != "" && (len(email) > 512 || !validator.IsEmail(email)) {
+			return errno.ErrParameterInvalid.SetDescription("邮箱格式错误")
+		}
+		p.Email = &email
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"strings"
+	"time"
+)
+
+// MaxPageSize 分页查询每页的最大数量
+const MaxPageSize = 100
+
+// userSortFields 允许排序的字段及其取值, 字段名与列名相同, 取值用于生成游标
+var userSortFields = map[string]func(u User) any{
+	"id":           func(u User) any { return u.ID },
+	"username":     func(u User) any { return u.Username },
+	"user_account": func(u User) any { return u.UserAccount },
+	"planet_code":  func(u User) any { return u.PlanetCode },
+	"user_status":  func(u User) any { return int64(u.UserStatus) },
+	"user_role":    func(u User) any { return int64(u.UserRole) },
+	"create_time":  func(u User) any { return u.CreateTime },
+	"update_time":  func(u User) any { return u.UpdateTime },
+}
+
+// UserFilter 用户列表的筛选条件, 零值字段不参与筛选
+type UserFilter struct {
+	Username    string    // 用户名包含
+	Account     string    // 账号前缀
+	PlanetCode  string    // 星球编号
+	Email       string    // 邮箱
+	Phone       string    // 手机号包含
+	Role        *int32    // 用户角色
+	Status      *int32    // 用户状态
+	Gender      *int32    // 性别
+	CreatedFrom time.Time // 创建时间不早于
+	CreatedTo   time.Time // 创建时间早于
+}
+
+// Validate 校验时间范围
+func (f UserFilter) Validate() error {
+	if !f.CreatedFrom.IsZero() && !f.CreatedTo.IsZero() && !f.CreatedFrom.Before(f.CreatedTo) {
+		return errno.ErrParameterInvalid.SetDescription("创建时间的开始时间必须早于结束时间")
+	}
+	return nil
+}
+
+// UserSort 排序条件, Field 为允许排序的字段
+type UserSort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseUserSorts 解析 create_time,-id 形式的排序条件, 字段前加 - 表示降序
+func ParseUserSorts(s string) ([]UserSort, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var sorts []UserSort
+	seen := make(map[string]struct{})
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		sort := UserSort{Field: strings.TrimPrefix(item, "-"), Desc: strings.HasPrefix(item, "-")}
+		if _, ok := userSortFields[sort.Field]; !ok {
+			return nil, errno.ErrParameterInvalid.SetDescription("不支持按 %s 排序", sort.Field)
+		}
+		if _, ok := seen[sort.Field]; ok {
+			return nil, errno.ErrParameterInvalid.SetDescription("排序字段 %s 重复", sort.Field)
+		}
+		seen[sort.Field] = struct{}{}
+		sorts = append(sorts, sort)
+	}
+	return sorts, nil
+}
+
+// UserQuery 分页查询用户的条件
+type UserQuery struct {
+	Filter  UserFilter
+	Sorts   []UserSort // 为空时按 id 升序
+	Current int        // 页码, 从 1 开始
+	Size    int        // 每页数量
+}
+
+// Validate 校验分页参数、筛选条件和排序字段
+func (q UserQuery) Validate() error {
+	if q.Current < 1 {
+		return errno.ErrParameterInvalid.SetDescription("页码不能小于 1")
+	}
+	if q.Size < 1 || q.Size > MaxPageSize {
+		return errno.ErrParameterInvalid.SetDescription("每页数量必须在 1-%d 之间", MaxPageSize)
+	}
+	for _, sort := range q.Sorts {
+		if _, ok := userSortFields[sort.Field]; !ok {
+			return errno.ErrParameterInvalid.SetDescription("不支持按 %s 排序", sort.Field)
+		}
+	}
+	return q.Filter.Validate()
+}
+
+// Offset 当前页之前的记录数
+func (q UserQuery) Offset() int {
+	return (q.Current - 1) * q.Size
+}
@@ -14,20 +14,24 @@ const TableNameUser = "user"
 
 // User mapped from table <user>
 type User struct {
-	ID           int64                 `gorm:"column:id;primaryKey;autoIncrement:true;comment:主键ID" json:"id"`                // 主键ID
-	Username     string                `gorm:"column:username;comment:用户昵称" json:"username"`                                  // 用户昵称
-	UserAccount  string                `gorm:"column:user_account;comment:账号" json:"user_account"`                            // 账号
-	AvatarURL    string                `gorm:"column:avatar_url;comment:用户头像" json:"avatar_url"`                              // 用户头像
-	Gender       int32                 `gorm:"column:gender;comment:性别" json:"gender"`                                        // 性别
-	UserPassword string                `gorm:"column:user_password;not null;comment:密码" json:"user_password"`                 // 密码
-	Phone        string                `gorm:"column:phone;comment:电话" json:"phone"`                                          // 电话
-	Email        string                `gorm:"column:email;comment:邮箱" json:"email"`                                          // 邮箱
-	UserStatus   int32                 `gorm:"column:user_status;not null;comment:用户状态 0-正常" json:"user_status"`              // 用户状态 0-正常
-	CreateTime   time.Time             `gorm:"column:create_time;default:CURRENT_TIMESTAMP;comment:创建时间" json:"create_time"`  // 创建时间
-	UpdateTime   time.Time             `gorm:"column:update_time;default:CURRENT_TIMESTAMP;comment:更新时间" json:"update_time"`  // 更新时间
-	IsDelete     soft_delete.DeletedAt `gorm:"column:is_delete;not null;comment:是否删除（逻辑删除）;softDelete:flag" json:"is_delete"` // 是否删除（逻辑删除）
-	UserRole     int32                 `gorm:"column:user_role;not null;comment:用户角色 0-普通用户 1-管理员" json:"user_role"`          // 用户角色 0-普通用户 1-管理员
-	PlanetCode   string                `gorm:"column:planet_code;comment:星球编号" json:"planet_code"`                            // 星球编号
+	ID              int64                 `gorm:"column:id;primaryKey;autoIncrement:true;comment:主键ID" json:"id"`                              // 主键ID
+	Username        string                `gorm:"column:username;not null;comment:用户昵称" json:"username"`                                                // 用户昵称
+	UserAccount     string                `gorm:"column:user_account;not null;comment:账号" json:"user_account"`                                          // 账号
+	AvatarURL       string                `gorm:"column:avatar_url;comment:用户头像" json:"avatar_url"`                                            // 用户头像
+	Gender          int32                 `gorm:"column:gender;comment:性别" json:"gender"`                                                      // 性别
+	UserPassword    string                `gorm:"column:user_password;not null;comment:密码" json:"user_password"`                               // 密码
+	Phone           string                `gorm:"column:phone;comment:电话" json:"phone"`                                                        // 电话
+	Email           string                `gorm:"column:email;comment:邮箱" json:"email"`                                                        // 邮箱
+	UserStatus      int32                 `gorm:"column:user_status;not null;comment:用户状态 0-正常" json:"user_status"`                            // 用户状态 0-正常
+	CreateTime      time.Time             `gorm:"column:create_time;not null;default:CURRENT_TIMESTAMP;comment:创建时间" json:"create_time"`                // 创建时间
+	UpdateTime      time.Time             `gorm:"column:update_time;not null;default:CURRENT_TIMESTAMP;comment:更新时间" json:"update_time"`                // 更新时间
+	IsDelete        soft_delete.DeletedAt `gorm:"column:is_delete;not null;comment:是否删除（逻辑删除）;softDelete:flag" json:"is_delete"`               // 是否删除（逻辑删除）
+	UserRole        int32                 `gorm:"column:user_role;not null;comment:用户角色 0-普通用户 1-管理员" json:"user_role"`                        // 用户角色 0-普通用户 1-管理员
+	PlanetCode      string                `gorm:"column:planet_code;not null;comment:星球编号" json:"planet_code"`                                          // 星球编号
+	SecurityVersion int32                 `gorm:"column:security_version;not null;comment:安全版本, 修改密码后递增, 已签发的登录态随之失效" json:"security_version"` // 安全版本, 修改密码后递增, 已签发的登录态随之失效
+	FreezeReason    string                `gorm:"column:freeze_reason;comment:冻结原因" json:"freeze_reason"`                                      // 冻结原因
+	FreezeUntil     *time.Time            `gorm:"column:freeze_until;comment:冻结截止时间, 为空表示永久冻结" json:"freeze_until"`                            // 冻结截止时间, 为空表示永久冻结
+	PreFreezeStatus int32                 `gorm:"column:pre_freeze_status;not null;comment:冻结前的用户状态, 解冻时恢复" json:"pre_freeze_status"`          // 冻结前的用户状态, 解冻时恢复
 }
 
 // TableName User's table name
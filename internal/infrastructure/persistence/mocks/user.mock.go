@@ -7,8 +7,10 @@ package daomocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/coderlewin/ucenter/internal/infrastructure/entity"
+	persistence "github.com/coderlewin/ucenter/internal/infrastructure/persistence"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -50,6 +52,21 @@ func (mr *MockUserDAOMockRecorder) Count(ctx, col, val interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockUserDAO)(nil).Count), ctx, col, val)
 }
 
+// CountByQuery mocks base method.
+func (m *MockUserDAO) CountByQuery(ctx context.Context, query persistence.UserQuery) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByQuery", ctx, query)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByQuery indicates an expected call of CountByQuery.
+func (mr *MockUserDAOMockRecorder) CountByQuery(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByQuery", reflect.TypeOf((*MockUserDAO)(nil).CountByQuery), ctx, query)
+}
+
 // Delete mocks base method.
 func (m *MockUserDAO) Delete(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
@@ -64,6 +81,21 @@ func (mr *MockUserDAOMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserDAO)(nil).Delete), ctx, id)
 }
 
+// EstimateCountByQuery mocks base method.
+func (m *MockUserDAO) EstimateCountByQuery(ctx context.Context, query persistence.UserQuery) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EstimateCountByQuery", ctx, query)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EstimateCountByQuery indicates an expected call of EstimateCountByQuery.
+func (mr *MockUserDAOMockRecorder) EstimateCountByQuery(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateCountByQuery", reflect.TypeOf((*MockUserDAO)(nil).EstimateCountByQuery), ctx, query)
+}
+
 // FindByAccount mocks base method.
 func (m *MockUserDAO) FindByAccount(ctx context.Context, account string) (entity.User, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +111,51 @@ func (mr *MockUserDAOMockRecorder) FindByAccount(ctx, account interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByAccount", reflect.TypeOf((*MockUserDAO)(nil).FindByAccount), ctx, account)
 }
 
+// FindByEmail mocks base method.
+func (m *MockUserDAO) FindByEmail(ctx context.Context, email string) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByEmail", ctx, email)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByEmail indicates an expected call of FindByEmail.
+func (mr *MockUserDAOMockRecorder) FindByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmail", reflect.TypeOf((*MockUserDAO)(nil).FindByEmail), ctx, email)
+}
+
+// FindByPhone mocks base method.
+func (m *MockUserDAO) FindByPhone(ctx context.Context, phone string) (entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPhone", ctx, phone)
+	ret0, _ := ret[0].(entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPhone indicates an expected call of FindByPhone.
+func (mr *MockUserDAOMockRecorder) FindByPhone(ctx, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPhone", reflect.TypeOf((*MockUserDAO)(nil).FindByPhone), ctx, phone)
+}
+
+// Freeze mocks base method.
+func (m *MockUserDAO) Freeze(ctx context.Context, id int64, reason string, until *time.Time, keepRole int32) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Freeze", ctx, id, reason, until, keepRole)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Freeze indicates an expected call of Freeze.
+func (mr *MockUserDAOMockRecorder) Freeze(ctx, id, reason, until, keepRole interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Freeze", reflect.TypeOf((*MockUserDAO)(nil).Freeze), ctx, id, reason, until, keepRole)
+}
+
 // GetByID mocks base method.
 func (m *MockUserDAO) GetByID(ctx context.Context, id int64) (entity.User, error) {
 	m.ctrl.T.Helper()
@@ -109,18 +186,416 @@ func (mr *MockUserDAOMockRecorder) Insert(ctx, data interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockUserDAO)(nil).Insert), ctx, data)
 }
 
-// SelectPageByUsername mocks base method.
-func (m *MockUserDAO) SelectPageByUsername(ctx context.Context, username string, offset, limit int) ([]entity.User, int64, error) {
+// RehashPassword mocks base method.
+func (m *MockUserDAO) RehashPassword(ctx context.Context, id int64, oldHash, newHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RehashPassword", ctx, id, oldHash, newHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RehashPassword indicates an expected call of RehashPassword.
+func (mr *MockUserDAOMockRecorder) RehashPassword(ctx, id, oldHash, newHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashPassword", reflect.TypeOf((*MockUserDAO)(nil).RehashPassword), ctx, id, oldHash, newHash)
+}
+
+// SelectAfter mocks base method.
+func (m *MockUserDAO) SelectAfter(ctx context.Context, query persistence.UserQuery, after []any, limit int) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectAfter", ctx, query, after, limit)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectAfter indicates an expected call of SelectAfter.
+func (mr *MockUserDAOMockRecorder) SelectAfter(ctx, query, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectAfter", reflect.TypeOf((*MockUserDAO)(nil).SelectAfter), ctx, query, after, limit)
+}
+
+// SelectBatch mocks base method.
+func (m *MockUserDAO) SelectBatch(ctx context.Context, query persistence.UserQuery, afterID int64, limit int) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectBatch", ctx, query, afterID, limit)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectBatch indicates an expected call of SelectBatch.
+func (mr *MockUserDAOMockRecorder) SelectBatch(ctx, query, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectBatch", reflect.TypeOf((*MockUserDAO)(nil).SelectBatch), ctx, query, afterID, limit)
+}
+
+// SelectPage mocks base method.
+func (m *MockUserDAO) SelectPage(ctx context.Context, query persistence.UserQuery, offset, limit int) ([]entity.User, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SelectPageByUsername", ctx, username, offset, limit)
+	ret := m.ctrl.Call(m, "SelectPage", ctx, query, offset, limit)
 	ret0, _ := ret[0].([]entity.User)
 	ret1, _ := ret[1].(int64)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
-// SelectPageByUsername indicates an expected call of SelectPageByUsername.
-func (mr *MockUserDAOMockRecorder) SelectPageByUsername(ctx, username, offset, limit interface{}) *gomock.Call {
+// SelectPage indicates an expected call of SelectPage.
+func (mr *MockUserDAOMockRecorder) SelectPage(ctx, query, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectPage", reflect.TypeOf((*MockUserDAO)(nil).SelectPage), ctx, query, offset, limit)
+}
+
+// Unfreeze mocks base method.
+func (m *MockUserDAO) Unfreeze(ctx context.Context, id int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unfreeze", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unfreeze indicates an expected call of Unfreeze.
+func (mr *MockUserDAOMockRecorder) Unfreeze(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unfreeze", reflect.TypeOf((*MockUserDAO)(nil).Unfreeze), ctx, id)
+}
+
+// Update mocks base method.
+func (m *MockUserDAO) Update(ctx context.Context, id int64, fields map[string]any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, fields)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockUserDAOMockRecorder) Update(ctx, id, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserDAO)(nil).Update), ctx, id, fields)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockUserDAO) UpdatePassword(ctx context.Context, id int64, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, id, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserDAOMockRecorder) UpdatePassword(ctx, id, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserDAO)(nil).UpdatePassword), ctx, id, password)
+}
+
+// UpdateRole mocks base method.
+func (m *MockUserDAO) UpdateRole(ctx context.Context, id int64, role, keepRole int32) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, id, role, keepRole)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockUserDAOMockRecorder) UpdateRole(ctx, id, role, keepRole interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockUserDAO)(nil).UpdateRole), ctx, id, role, keepRole)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockUserDAO) VerifyEmail(ctx context.Context, id int64, email string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, id, email)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserDAOMockRecorder) VerifyEmail(ctx, id, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserDAO)(nil).VerifyEmail), ctx, id, email)
+}
+
+// MockOAuthClientDAO is a mock of OAuthClientDAO interface.
+type MockOAuthClientDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthClientDAOMockRecorder
+}
+
+// MockOAuthClientDAOMockRecorder is the mock recorder for MockOAuthClientDAO.
+type MockOAuthClientDAOMockRecorder struct {
+	mock *MockOAuthClientDAO
+}
+
+// NewMockOAuthClientDAO creates a new mock instance.
+func NewMockOAuthClientDAO(ctrl *gomock.Controller) *MockOAuthClientDAO {
+	mock := &MockOAuthClientDAO{ctrl: ctrl}
+	mock.recorder = &MockOAuthClientDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthClientDAO) EXPECT() *MockOAuthClientDAOMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockOAuthClientDAO) Delete(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockOAuthClientDAOMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOAuthClientDAO)(nil).Delete), ctx, id)
+}
+
+// FindByClientID mocks base method.
+func (m *MockOAuthClientDAO) FindByClientID(ctx context.Context, clientID string) (entity.OauthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByClientID", ctx, clientID)
+	ret0, _ := ret[0].(entity.OauthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByClientID indicates an expected call of FindByClientID.
+func (mr *MockOAuthClientDAOMockRecorder) FindByClientID(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByClientID", reflect.TypeOf((*MockOAuthClientDAO)(nil).FindByClientID), ctx, clientID)
+}
+
+// Insert mocks base method.
+func (m *MockOAuthClientDAO) Insert(ctx context.Context, data entity.OauthClient) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, data)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockOAuthClientDAOMockRecorder) Insert(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockOAuthClientDAO)(nil).Insert), ctx, data)
+}
+
+// SelectAll mocks base method.
+func (m *MockOAuthClientDAO) SelectAll(ctx context.Context) ([]entity.OauthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SelectAll", ctx)
+	ret0, _ := ret[0].([]entity.OauthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SelectAll indicates an expected call of SelectAll.
+func (mr *MockOAuthClientDAOMockRecorder) SelectAll(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectAll", reflect.TypeOf((*MockOAuthClientDAO)(nil).SelectAll), ctx)
+}
+
+// MockUserMFADAO is a mock of UserMFADAO interface.
+type MockUserMFADAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserMFADAOMockRecorder
+}
+
+// MockUserMFADAOMockRecorder is the mock recorder for MockUserMFADAO.
+type MockUserMFADAOMockRecorder struct {
+	mock *MockUserMFADAO
+}
+
+// NewMockUserMFADAO creates a new mock instance.
+func NewMockUserMFADAO(ctrl *gomock.Controller) *MockUserMFADAO {
+	mock := &MockUserMFADAO{ctrl: ctrl}
+	mock.recorder = &MockUserMFADAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserMFADAO) EXPECT() *MockUserMFADAOMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockUserMFADAO) Delete(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUserMFADAOMockRecorder) Delete(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserMFADAO)(nil).Delete), ctx, uid)
+}
+
+// Enable mocks base method.
+func (m *MockUserMFADAO) Enable(ctx context.Context, uid int64, recoveryCodes string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, uid, recoveryCodes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockUserMFADAOMockRecorder) Enable(ctx, uid, recoveryCodes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockUserMFADAO)(nil).Enable), ctx, uid, recoveryCodes)
+}
+
+// FindByUserID mocks base method.
+func (m *MockUserMFADAO) FindByUserID(ctx context.Context, uid int64) (entity.UserMfa, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserID", ctx, uid)
+	ret0, _ := ret[0].(entity.UserMfa)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserID indicates an expected call of FindByUserID.
+func (mr *MockUserMFADAOMockRecorder) FindByUserID(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserID", reflect.TypeOf((*MockUserMFADAO)(nil).FindByUserID), ctx, uid)
+}
+
+// UpdateRecoveryCodes mocks base method.
+func (m *MockUserMFADAO) UpdateRecoveryCodes(ctx context.Context, uid int64, old, new string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRecoveryCodes", ctx, uid, old, new)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRecoveryCodes indicates an expected call of UpdateRecoveryCodes.
+func (mr *MockUserMFADAOMockRecorder) UpdateRecoveryCodes(ctx, uid, old, new interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRecoveryCodes", reflect.TypeOf((*MockUserMFADAO)(nil).UpdateRecoveryCodes), ctx, uid, old, new)
+}
+
+// Upsert mocks base method.
+func (m *MockUserMFADAO) Upsert(ctx context.Context, data entity.UserMfa) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upsert", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockUserMFADAOMockRecorder) Upsert(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockUserMFADAO)(nil).Upsert), ctx, data)
+}
+
+// MockPasswordHistoryDAO is a mock of PasswordHistoryDAO interface.
+type MockPasswordHistoryDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordHistoryDAOMockRecorder
+}
+
+// MockPasswordHistoryDAOMockRecorder is the mock recorder for MockPasswordHistoryDAO.
+type MockPasswordHistoryDAOMockRecorder struct {
+	mock *MockPasswordHistoryDAO
+}
+
+// NewMockPasswordHistoryDAO creates a new mock instance.
+func NewMockPasswordHistoryDAO(ctrl *gomock.Controller) *MockPasswordHistoryDAO {
+	mock := &MockPasswordHistoryDAO{ctrl: ctrl}
+	mock.recorder = &MockPasswordHistoryDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordHistoryDAO) EXPECT() *MockPasswordHistoryDAOMockRecorder {
+	return m.recorder
+}
+
+// DeleteBefore mocks base method.
+func (m *MockPasswordHistoryDAO) DeleteBefore(ctx context.Context, uid, beforeID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBefore", ctx, uid, beforeID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBefore indicates an expected call of DeleteBefore.
+func (mr *MockPasswordHistoryDAOMockRecorder) DeleteBefore(ctx, uid, beforeID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBefore", reflect.TypeOf((*MockPasswordHistoryDAO)(nil).DeleteBefore), ctx, uid, beforeID)
+}
+
+// FindRecent mocks base method.
+func (m *MockPasswordHistoryDAO) FindRecent(ctx context.Context, uid int64, limit int) ([]entity.UserPasswordHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRecent", ctx, uid, limit)
+	ret0, _ := ret[0].([]entity.UserPasswordHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRecent indicates an expected call of FindRecent.
+func (mr *MockPasswordHistoryDAOMockRecorder) FindRecent(ctx, uid, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRecent", reflect.TypeOf((*MockPasswordHistoryDAO)(nil).FindRecent), ctx, uid, limit)
+}
+
+// Insert mocks base method.
+func (m *MockPasswordHistoryDAO) Insert(ctx context.Context, data entity.UserPasswordHistory) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockPasswordHistoryDAOMockRecorder) Insert(ctx, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockPasswordHistoryDAO)(nil).Insert), ctx, data)
+}
+
+// MockAuditLogDAO is a mock of AuditLogDAO interface.
+type MockAuditLogDAO struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditLogDAOMockRecorder
+}
+
+// MockAuditLogDAOMockRecorder is the mock recorder for MockAuditLogDAO.
+type MockAuditLogDAOMockRecorder struct {
+	mock *MockAuditLogDAO
+}
+
+// NewMockAuditLogDAO creates a new mock instance.
+func NewMockAuditLogDAO(ctrl *gomock.Controller) *MockAuditLogDAO {
+	mock := &MockAuditLogDAO{ctrl: ctrl}
+	mock.recorder = &MockAuditLogDAOMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditLogDAO) EXPECT() *MockAuditLogDAOMockRecorder {
+	return m.recorder
+}
+
+// Insert mocks base method.
+func (m *MockAuditLogDAO) Insert(ctx context.Context, data entity.UserAuditLog) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, data)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockAuditLogDAOMockRecorder) Insert(ctx, data interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectPageByUsername", reflect.TypeOf((*MockUserDAO)(nil).SelectPageByUsername), ctx, username, offset, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockAuditLogDAO)(nil).Insert), ctx, data)
 }
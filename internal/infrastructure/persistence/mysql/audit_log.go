@@ -0,0 +1,20 @@
+package mysql
+
+import (
+	"context"
+	"github.com/coderlewin/ucenter/internal/infrastructure/entity"
+	"github.com/coderlewin/ucenter/internal/infrastructure/persistence"
+	"gorm.io/gorm"
+)
+
+func NewAuditLogDao(db *gorm.DB) persistence.AuditLogDAO {
+	return &auditLogDao{db: db}
+}
+
+type auditLogDao struct {
+	db *gorm.DB
+}
+
+func (a *auditLogDao) Insert(ctx context.Context, data entity.UserAuditLog) error {
+	return a.db.WithContext(ctx).Create(&data).Error
+}
@@ -0,0 +1,37 @@
+package mysql
+
+import (
+	"context"
+	"github.com/coderlewin/ucenter/internal/infrastructure/entity"
+	"github.com/coderlewin/ucenter/internal/infrastructure/persistence"
+	"gorm.io/gorm"
+)
+
+func NewOAuthClientDao(db *gorm.DB) persistence.OAuthClientDAO {
+	return &oauthClientDao{db: db}
+}
+
+type oauthClientDao struct {
+	db *gorm.DB
+}
+
+func (o *oauthClientDao) Insert(ctx context.Context, data entity.OauthClient) (int64, error) {
+	err := o.db.WithContext(ctx).Create(&data).Error
+	return data.ID, err
+}
+
+func (o *oauthClientDao) Delete(ctx context.Context, id int64) error {
+	return o.db.WithContext(ctx).Delete(&entity.OauthClient{}, id).Error
+}
+
+func (o *oauthClientDao) FindByClientID(ctx context.Context, clientID string) (entity.OauthClient, error) {
+	var client entity.OauthClient
+	err := o.db.WithContext(ctx).Where("client_id = ?", clientID).First(&client).Error
+	return client, err
+}
+
+func (o *oauthClientDao) SelectAll(ctx context.Context) ([]entity.OauthClient, error) {
+	var list []entity.OauthClient
+	err := o.db.WithContext(ctx).Order("id").Find(&list).Error
+	return list, err
+}
@@ -0,0 +1,31 @@
+package mysql
+
+import (
+	"context"
+	"github.com/coderlewin/ucenter/internal/infrastructure/entity"
+	"github.com/coderlewin/ucenter/internal/infrastructure/persistence"
+	"gorm.io/gorm"
+)
+
+func NewPasswordHistoryDao(db *gorm.DB) persistence.PasswordHistoryDAO {
+	return &passwordHistoryDao{db: db}
+}
+
+type passwordHistoryDao struct {
+	db *gorm.DB
+}
+
+func (p *passwordHistoryDao) Insert(ctx context.Context, data entity.UserPasswordHistory) error {
+	return p.db.WithContext(ctx).Create(&data).Error
+}
+
+func (p *passwordHistoryDao) FindRecent(ctx context.Context, uid int64, limit int) ([]entity.UserPasswordHistory, error) {
+	var list []entity.UserPasswordHistory
+	err := p.db.WithContext(ctx).Where("user_id = ?", uid).Order("id DESC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (p *passwordHistoryDao) DeleteBefore(ctx context.Context, uid int64, beforeID int64) error {
+	return p.db.WithContext(ctx).Where("user_id = ? AND id < ?", uid, beforeID).
+		Delete(&entity.UserPasswordHistory{}).Error
+}
@@ -2,10 +2,17 @@ package mysql
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/coderlewin/ucenter/internal/constants"
 	"github.com/coderlewin/ucenter/internal/infrastructure/entity"
 	"github.com/coderlewin/ucenter/internal/infrastructure/persistence"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func NewUserDao(db *gorm.DB) persistence.UserDAO {
@@ -22,17 +29,286 @@ func (u *userDao) GetByID(ctx context.Context, id int64) (entity.User, error) {
 	return user, err
 }
 
-func (u *userDao) SelectPageByUsername(ctx context.Context, username string, offset, limit int) ([]entity.User, int64, error) {
-	tx := u.db.WithContext(ctx).Model(&entity.User{})
-	if len(username) != 0 {
-		tx = tx.Where("username LIKE ?", "%"+username+"%")
+func (u *userDao) UpdatePassword(ctx context.Context, id int64, password string) error {
+	// 修改密码的同时递增安全版本, 使已签发的登录态失效
+	return u.db.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).Updates(map[string]any{
+		"user_password":    password,
+		"security_version": gorm.Expr("security_version + 1"),
+	}).Error
+}
+
+func (u *userDao) RehashPassword(ctx context.Context, id int64, oldHash, newHash string) (bool, error) {
+	// 只是更换哈希算法, 密码本身没有变化, 已签发的登录态继续有效
+	res := u.db.WithContext(ctx).Model(&entity.User{}).
+		Where("id = ? AND user_password = ?", id, oldHash).
+		Update("user_password", newHash)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (u *userDao) Update(ctx context.Context, id int64, fields map[string]any) error {
+	// 不依赖数据库的 on update, 显式刷新更新时间
+	fields["update_time"] = time.Now()
+	return u.db.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (u *userDao) Freeze(ctx context.Context, id int64, reason string, until *time.Time, keepRole int32) (bool, error) {
+	var ok bool
+	err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		last, err := isLastActive(tx, id, keepRole)
+		if err != nil || last {
+			return err
+		}
+		var user entity.User
+		if err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, id).Error; err != nil {
+			return err
+		}
+		fields := map[string]any{
+			"user_status":   constants.UserStatusDisabled,
+			"freeze_reason": reason,
+			"freeze_until":  until,
+			"update_time":   time.Now(),
+		}
+		// 重复冻结时保留第一次冻结前的状态
+		if user.UserStatus != constants.UserStatusDisabled {
+			fields["pre_freeze_status"] = user.UserStatus
+		}
+		if err = tx.Model(&entity.User{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+			return err
+		}
+		ok = true
+		return nil
+	})
+	return ok, err
+}
+
+func (u *userDao) Unfreeze(ctx context.Context, id int64) (bool, error) {
+	res := u.db.WithContext(ctx).Model(&entity.User{}).
+		Where("id = ? AND user_status = ?", id, constants.UserStatusDisabled).
+		Updates(map[string]any{
+			"user_status":   gorm.Expr("pre_freeze_status"),
+			"freeze_reason": "",
+			"freeze_until":  nil,
+			"update_time":   time.Now(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (u *userDao) UpdateRole(ctx context.Context, id int64, role, keepRole int32) (bool, error) {
+	var ok bool
+	err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		last, err := isLastActive(tx, id, keepRole)
+		if err != nil || (last && role != keepRole) {
+			return err
+		}
+		err = tx.Model(&entity.User{}).Where("id = ?", id).Updates(map[string]any{
+			"user_role":   role,
+			"update_time": time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+		ok = true
+		return nil
+	})
+	return ok, err
+}
+
+// isLastActive 锁定 role 中未被冻结的用户, 返回 id 是否是其中唯一的一个.
+// 先锁定再修改, 并发冻结或撤销同一角色的操作会依次执行, 不会同时通过检查
+func isLastActive(tx *gorm.DB, id int64, role int32) (bool, error) {
+	var ids []int64
+	// 冻结已到期的视为未冻结
+	err := tx.Model(&entity.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_role = ?", role).
+		Where("NOT (user_status = ? AND (freeze_until IS NULL OR freeze_until > ?))",
+			constants.UserStatusDisabled, time.Now()).
+		Order("id").
+		Pluck("id", &ids).Error
+	return len(ids) == 1 && ids[0] == id, err
+}
+
+func (u *userDao) VerifyEmail(ctx context.Context, id int64, email string) (bool, error) {
+	res := u.db.WithContext(ctx).Model(&entity.User{}).
+		Where("id = ? AND email = ? AND user_status = ?", id, email, constants.UserStatusPending).
+		Update("user_status", 0)
+	return res.RowsAffected > 0, res.Error
+}
+
+// userSortColumns 允许排序的列, 排序的列名会直接拼接到 SQL 中, 必须经过白名单校验.
+// 这些列都不允许为 NULL 并且有 (列, id) 联合索引, 游标分页时直接与游标中的取值比较
+var userSortColumns = map[string]struct{}{
+	"id":           {},
+	"username":     {},
+	"user_account": {},
+	"planet_code":  {},
+	"user_status":  {},
+	"user_role":    {},
+	"create_time":  {},
+	"update_time":  {},
+}
+
+func (u *userDao) SelectPage(ctx context.Context, query persistence.UserQuery, offset, limit int) ([]entity.User, int64, error) {
+	tx := applyUserQuery(u.db.WithContext(ctx).Model(&entity.User{}), query)
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	byID := false
+	for _, order := range query.Orders {
+		if _, ok := userSortColumns[order.Column]; !ok {
+			return nil, 0, fmt.Errorf("unsupported sort column %q", order.Column)
+		}
+		byID = byID || order.Column == "id"
+		tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Name: order.Column}, Desc: order.Desc})
+	}
+	// 最后按 id 排序, 排序字段相同的记录在各页之间不会重复或遗漏
+	if !byID {
+		tx = tx.Order("id")
 	}
 	var list []entity.User
-	var total int64
-	err := tx.Count(&total).Offset(offset).Limit(limit).Find(&list).Error
+	err := tx.Offset(offset).Limit(limit).Find(&list).Error
 	return list, total, err
 }
 
+func (u *userDao) SelectAfter(ctx context.Context, query persistence.UserQuery, after []any, limit int) ([]entity.User, error) {
+	tx := applyUserQuery(u.db.WithContext(ctx).Model(&entity.User{}), query)
+	for _, order := range query.Orders {
+		if _, ok := userSortColumns[order.Column]; !ok {
+			return nil, fmt.Errorf("unsupported sort column %q", order.Column)
+		}
+		tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Name: order.Column}, Desc: order.Desc})
+	}
+	if len(query.Orders) == 0 || query.Orders[len(query.Orders)-1].Column != "id" {
+		return nil, errors.New("keyset orders must end with id")
+	}
+	if len(after) != 0 {
+		if len(after) != len(query.Orders) {
+			return nil, fmt.Errorf("cursor has %d values, want %d", len(after), len(query.Orders))
+		}
+		sql, vars := keysetAfter(query.Orders, after)
+		tx = tx.Where(sql, vars...)
+	}
+	var list []entity.User
+	err := tx.Limit(limit).Find(&list).Error
+	return list, err
+}
+
+// keysetAfter 生成排在游标之后的条件, 如按 (a, id) 升序时为 (a > ?) OR (a = ? AND id > ?)
+func keysetAfter(orders []persistence.Order, after []any) (string, []any) {
+	var vars []any
+	branches := make([]string, 0, len(orders))
+	for i, order := range orders {
+		conds := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			conds = append(conds, orders[j].Column+" = ?")
+			vars = append(vars, after[j])
+		}
+		if order.Desc {
+			conds = append(conds, order.Column+" < ?")
+		} else {
+			conds = append(conds, order.Column+" > ?")
+		}
+		vars = append(vars, after[i])
+		branches = append(branches, "("+strings.Join(conds, " AND ")+")")
+	}
+	return strings.Join(branches, " OR "), vars
+}
+
+func (u *userDao) CountByQuery(ctx context.Context, query persistence.UserQuery) (int64, error) {
+	var total int64
+	err := applyUserQuery(u.db.WithContext(ctx).Model(&entity.User{}), query).Count(&total).Error
+	return total, err
+}
+
+func (u *userDao) EstimateCountByQuery(ctx context.Context, query persistence.UserQuery) (int64, error) {
+	// 只生成 SQL, 不执行
+	stmt := applyUserQuery(u.db.WithContext(ctx).Session(&gorm.Session{DryRun: true}).Model(&entity.User{}), query).
+		Select("id").Find(&[]entity.User{}).Statement
+	var plans []map[string]any
+	err := u.db.WithContext(ctx).Raw("EXPLAIN "+stmt.SQL.String(), stmt.Vars...).Scan(&plans).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(plans) == 0 {
+		return 0, errors.New("empty explain result")
+	}
+	// rows 为需要扫描的行数, filtered 为其中满足条件的百分比
+	rows, ok := explainNumber(plans[0]["rows"])
+	if !ok {
+		return 0, fmt.Errorf("unexpected explain rows %v", plans[0]["rows"])
+	}
+	if filtered, ok := explainNumber(plans[0]["filtered"]); ok {
+		rows = rows * filtered / 100
+	}
+	return int64(math.Round(rows)), nil
+}
+
+// explainNumber 解析 EXPLAIN 结果中的数值, 驱动可能返回数值或字节切片
+func explainNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case []byte:
+		f, err := strconv.ParseFloat(string(n), 64)
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func (u *userDao) SelectBatch(ctx context.Context, query persistence.UserQuery, afterID int64, limit int) ([]entity.User, error) {
+	tx := applyUserQuery(u.db.WithContext(ctx).Model(&entity.User{}), query).Where("id > ?", afterID)
+	var list []entity.User
+	err := tx.Order("id").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+// applyUserQuery 添加筛选条件
+func applyUserQuery(tx *gorm.DB, query persistence.UserQuery) *gorm.DB {
+	if query.Username != "" {
+		tx = tx.Where("username LIKE ?", "%"+escapeLike(query.Username)+"%")
+	}
+	if query.Account != "" {
+		tx = tx.Where("user_account LIKE ?", escapeLike(query.Account)+"%")
+	}
+	if query.PlanetCode != "" {
+		tx = tx.Where("planet_code = ?", query.PlanetCode)
+	}
+	if query.Email != "" {
+		tx = tx.Where("email = ?", query.Email)
+	}
+	if query.Phone != "" {
+		tx = tx.Where("phone LIKE ?", "%"+escapeLike(query.Phone)+"%")
+	}
+	if query.Role != nil {
+		tx = tx.Where("user_role = ?", *query.Role)
+	}
+	if query.Status != nil {
+		tx = tx.Where("user_status = ?", *query.Status)
+	}
+	if query.Gender != nil {
+		tx = tx.Where("gender = ?", *query.Gender)
+	}
+	if !query.CreatedFrom.IsZero() {
+		tx = tx.Where("create_time >= ?", query.CreatedFrom)
+	}
+	if !query.CreatedTo.IsZero() {
+		tx = tx.Where("create_time < ?", query.CreatedTo)
+	}
+	return tx
+}
+
+// escapeLike 转义 LIKE 中的通配符, 使其按字面匹配
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 func (u *userDao) Count(ctx context.Context, col string, val any) (int64, error) {
 	var count int64
 	err := u.db.WithContext(ctx).Model(&entity.User{}).Where(fmt.Sprintf("%s = ?", col), val).Count(&count).Error
@@ -48,6 +324,40 @@ func (u *userDao) FindByAccount(ctx context.Context, account string) (entity.Use
 	return user, err
 }
 
+func (u *userDao) FindByEmail(ctx context.Context, email string) (entity.User, error) {
+	// 多取一条用于判断邮箱是否被多个用户使用, 不能随意返回其中一个
+	var users []entity.User
+	err := u.db.WithContext(ctx).Where("email = ?", email).Order("id").Limit(2).Find(&users).Error
+	if err != nil {
+		return entity.User{}, err
+	}
+	switch len(users) {
+	case 0:
+		return entity.User{}, gorm.ErrRecordNotFound
+	case 1:
+		return users[0], nil
+	default:
+		return entity.User{}, persistence.ErrAmbiguousRecord
+	}
+}
+
+func (u *userDao) FindByPhone(ctx context.Context, phone string) (entity.User, error) {
+	// 与 FindByEmail 相同, 手机号被多个用户使用时不能随意返回其中一个
+	var users []entity.User
+	err := u.db.WithContext(ctx).Where("phone = ?", phone).Order("id").Limit(2).Find(&users).Error
+	if err != nil {
+		return entity.User{}, err
+	}
+	switch len(users) {
+	case 0:
+		return entity.User{}, gorm.ErrRecordNotFound
+	case 1:
+		return users[0], nil
+	default:
+		return entity.User{}, persistence.ErrAmbiguousRecord
+	}
+}
+
 func (u *userDao) Delete(ctx context.Context, id int64) error {
 	return u.db.WithContext(ctx).Delete(&entity.User{}, id).Error
 }
@@ -0,0 +1,45 @@
+package mysql
+
+import (
+	"context"
+	"github.com/coderlewin/ucenter/internal/infrastructure/entity"
+	"github.com/coderlewin/ucenter/internal/infrastructure/persistence"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func NewUserMFADao(db *gorm.DB) persistence.UserMFADAO {
+	return &userMFADao{db: db}
+}
+
+type userMFADao struct {
+	db *gorm.DB
+}
+
+func (u *userMFADao) Upsert(ctx context.Context, data entity.UserMfa) error {
+	return u.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"totp_secret", "enabled", "recovery_codes"}),
+	}).Create(&data).Error
+}
+
+func (u *userMFADao) FindByUserID(ctx context.Context, uid int64) (entity.UserMfa, error) {
+	var mfa entity.UserMfa
+	err := u.db.WithContext(ctx).Where("user_id = ?", uid).First(&mfa).Error
+	return mfa, err
+}
+
+func (u *userMFADao) Enable(ctx context.Context, uid int64, recoveryCodes string) error {
+	return u.db.WithContext(ctx).Model(&entity.UserMfa{}).Where("user_id = ?", uid).
+		Updates(map[string]any{"enabled": 1, "recovery_codes": recoveryCodes}).Error
+}
+
+func (u *userMFADao) UpdateRecoveryCodes(ctx context.Context, uid int64, old, new string) (bool, error) {
+	res := u.db.WithContext(ctx).Model(&entity.UserMfa{}).
+		Where("user_id = ? AND recovery_codes = ?", uid, old).
+		Update("recovery_codes", new)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (u *userMFADao) Delete(ctx context.Context, uid int64) error {
+	return u.db.WithContext(ctx).Where("user_id = ?", uid).Delete(&entity.UserMfa{}).Error
+}
@@ -2,15 +2,97 @@ package persistence
 
 import (
 	"context"
+	"errors"
 	"github.com/coderlewin/ucenter/internal/infrastructure/entity"
+	"time"
 )
 
+// ErrAmbiguousRecord 按非唯一列查询单条记录时匹配到多条记录
+var ErrAmbiguousRecord = errors.New("persistence: more than one record matched")
+
 //go:generate mockgen -source=./persistence.go -package=daomocks -destination=mocks/user.mock.go UserDAO
 type UserDAO interface {
 	Insert(ctx context.Context, data entity.User) (int64, error)
 	Delete(ctx context.Context, id int64) error
 	GetByID(ctx context.Context, id int64) (entity.User, error)
 	FindByAccount(ctx context.Context, account string) (entity.User, error)
+	// FindByEmail 邮箱没有唯一约束, 匹配到多个用户时返回 ErrAmbiguousRecord
+	FindByEmail(ctx context.Context, email string) (entity.User, error)
+	// FindByPhone 手机号没有唯一约束, 匹配到多个用户时返回 ErrAmbiguousRecord
+	FindByPhone(ctx context.Context, phone string) (entity.User, error)
 	Count(ctx context.Context, col string, val any) (int64, error)
-	SelectPageByUsername(ctx context.Context, username string, offset, limit int) ([]entity.User, int64, error)
+	// UpdatePassword 更新密码并递增安全版本
+	UpdatePassword(ctx context.Context, id int64, password string) error
+	// RehashPassword 密码未被修改时替换为新算法的哈希, 不递增安全版本, 返回是否更新成功
+	RehashPassword(ctx context.Context, id int64, oldHash, newHash string) (bool, error)
+	// Update 更新 fields 中的字段, 同时刷新 update_time
+	Update(ctx context.Context, id int64, fields map[string]any) error
+	// Freeze 冻结用户并保存冻结前的状态. 目标是 keepRole 中最后一个未被冻结的用户时不更新, 返回 false
+	Freeze(ctx context.Context, id int64, reason string, until *time.Time, keepRole int32) (bool, error)
+	// Unfreeze 仅在用户处于冻结状态时解冻并恢复冻结前的状态, 返回是否更新成功
+	Unfreeze(ctx context.Context, id int64) (bool, error)
+	// UpdateRole 修改用户角色. 目标是 keepRole 中最后一个未被冻结的用户时不更新, 返回 false
+	UpdateRole(ctx context.Context, id int64, role, keepRole int32) (bool, error)
+	// VerifyEmail 邮箱未变更且处于待验证状态时激活用户, 返回是否更新成功
+	VerifyEmail(ctx context.Context, id int64, email string) (bool, error)
+	// SelectPage 按 query 筛选和排序, 返回当前页和总数
+	SelectPage(ctx context.Context, query UserQuery, offset, limit int) ([]entity.User, int64, error)
+	// SelectAfter 按 query 筛选和排序, 返回排在 after 之后的最多 limit 个用户, 不统计总数.
+	// query.Orders 需要以 id 结尾, after 为上一页最后一个用户在各排序列上的取值, 为空时从头开始
+	SelectAfter(ctx context.Context, query UserQuery, after []any, limit int) ([]entity.User, error)
+	// CountByQuery 统计满足 query 的用户数量
+	CountByQuery(ctx context.Context, query UserQuery) (int64, error)
+	// EstimateCountByQuery 根据执行计划估算满足 query 的用户数量, 不扫描数据
+	EstimateCountByQuery(ctx context.Context, query UserQuery) (int64, error)
+	// SelectBatch 按 id 升序返回 id 大于 afterID 的最多 limit 个用户, 用于分批遍历, 忽略 query 中的排序
+	SelectBatch(ctx context.Context, query UserQuery, afterID int64, limit int) ([]entity.User, error)
+}
+
+// UserQuery 用户列表的查询条件, 零值字段不参与查询
+type UserQuery struct {
+	Username    string // 用户名包含
+	Account     string // 账号前缀
+	PlanetCode  string
+	Email       string
+	Phone       string // 手机号包含
+	Role        *int32
+	Status      *int32
+	Gender      *int32
+	CreatedFrom time.Time // 创建时间不早于
+	CreatedTo   time.Time // 创建时间早于
+	Orders      []Order   // 排序, 最后总是按 id 排序保证分页稳定
+}
+
+// Order 排序条件, Column 必须是允许排序的列
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+type OAuthClientDAO interface {
+	Insert(ctx context.Context, data entity.OauthClient) (int64, error)
+	Delete(ctx context.Context, id int64) error
+	FindByClientID(ctx context.Context, clientID string) (entity.OauthClient, error)
+	SelectAll(ctx context.Context) ([]entity.OauthClient, error)
+}
+
+type UserMFADAO interface {
+	Upsert(ctx context.Context, data entity.UserMfa) error
+	FindByUserID(ctx context.Context, uid int64) (entity.UserMfa, error)
+	Enable(ctx context.Context, uid int64, recoveryCodes string) error
+	// UpdateRecoveryCodes 仅在恢复码仍为 old 时更新, 返回是否更新成功
+	UpdateRecoveryCodes(ctx context.Context, uid int64, old, new string) (bool, error)
+	Delete(ctx context.Context, uid int64) error
+}
+
+type PasswordHistoryDAO interface {
+	Insert(ctx context.Context, data entity.UserPasswordHistory) error
+	// FindRecent 按时间倒序返回用户最近的 limit 条历史密码
+	FindRecent(ctx context.Context, uid int64, limit int) ([]entity.UserPasswordHistory, error)
+	// DeleteBefore 删除用户 id 小于 beforeID 的历史密码
+	DeleteBefore(ctx context.Context, uid int64, beforeID int64) error
+}
+
+type AuditLogDAO interface {
+	Insert(ctx context.Context, data entity.UserAuditLog) error
 }
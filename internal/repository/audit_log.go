@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/infrastructure/entity"
+	"github.com/coderlewin/ucenter/internal/infrastructure/persistence"
+)
+
+//go:generate mockgen -source=./audit_log.go -package=repomocks -destination=mocks/audit_log.mock.go AuditLogRepository
+type AuditLogRepository interface {
+	Create(ctx context.Context, log domain.AuditLog) error
+}
+
+func NewAuditLogRepository(auditDao persistence.AuditLogDAO) AuditLogRepository {
+	return &auditLogRepository{auditDao: auditDao}
+}
+
+type auditLogRepository struct {
+	auditDao persistence.AuditLogDAO
+}
+
+func (a *auditLogRepository) Create(ctx context.Context, log domain.AuditLog) error {
+	return a.auditDao.Insert(ctx, entity.UserAuditLog{
+		UserID:     log.UserID,
+		OperatorID: log.OperatorID,
+		Action:     log.Action,
+		Detail:     log.Detail,
+		IP:         log.IP,
+		UserAgent:  log.UserAgent,
+	})
+}
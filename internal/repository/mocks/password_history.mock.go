@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./password_history.go
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPasswordHistoryRepository is a mock of PasswordHistoryRepository interface.
+type MockPasswordHistoryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordHistoryRepositoryMockRecorder
+}
+
+// MockPasswordHistoryRepositoryMockRecorder is the mock recorder for MockPasswordHistoryRepository.
+type MockPasswordHistoryRepositoryMockRecorder struct {
+	mock *MockPasswordHistoryRepository
+}
+
+// NewMockPasswordHistoryRepository creates a new mock instance.
+func NewMockPasswordHistoryRepository(ctrl *gomock.Controller) *MockPasswordHistoryRepository {
+	mock := &MockPasswordHistoryRepository{ctrl: ctrl}
+	mock.recorder = &MockPasswordHistoryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordHistoryRepository) EXPECT() *MockPasswordHistoryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockPasswordHistoryRepository) Add(ctx context.Context, uid int64, password string, keep int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, uid, password, keep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockPasswordHistoryRepositoryMockRecorder) Add(ctx, uid, password, keep interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockPasswordHistoryRepository)(nil).Add), ctx, uid, password, keep)
+}
+
+// ListRecent mocks base method.
+func (m *MockPasswordHistoryRepository) ListRecent(ctx context.Context, uid int64, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRecent", ctx, uid, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRecent indicates an expected call of ListRecent.
+func (mr *MockPasswordHistoryRepositoryMockRecorder) ListRecent(ctx, uid, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRecent", reflect.TypeOf((*MockPasswordHistoryRepository)(nil).ListRecent), ctx, uid, limit)
+}
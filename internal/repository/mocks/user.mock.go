@@ -7,6 +7,7 @@ package repomocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	domain "github.com/coderlewin/ucenter/internal/domain"
 	gomock "github.com/golang/mock/gomock"
@@ -50,6 +51,51 @@ func (mr *MockUserRepositoryMockRecorder) CountByAccount(ctx, account interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByAccount", reflect.TypeOf((*MockUserRepository)(nil).CountByAccount), ctx, account)
 }
 
+// CountByEmail mocks base method.
+func (m *MockUserRepository) CountByEmail(ctx context.Context, email string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByEmail", ctx, email)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByEmail indicates an expected call of CountByEmail.
+func (mr *MockUserRepositoryMockRecorder) CountByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByEmail", reflect.TypeOf((*MockUserRepository)(nil).CountByEmail), ctx, email)
+}
+
+// CountByFilter mocks base method.
+func (m *MockUserRepository) CountByFilter(ctx context.Context, filter domain.UserFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByFilter", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByFilter indicates an expected call of CountByFilter.
+func (mr *MockUserRepositoryMockRecorder) CountByFilter(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByFilter", reflect.TypeOf((*MockUserRepository)(nil).CountByFilter), ctx, filter)
+}
+
+// CountByPhone mocks base method.
+func (m *MockUserRepository) CountByPhone(ctx context.Context, phone string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByPhone", ctx, phone)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByPhone indicates an expected call of CountByPhone.
+func (mr *MockUserRepositoryMockRecorder) CountByPhone(ctx, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByPhone", reflect.TypeOf((*MockUserRepository)(nil).CountByPhone), ctx, phone)
+}
+
 // CountByPlanetCode mocks base method.
 func (m *MockUserRepository) CountByPlanetCode(ctx context.Context, planetCode string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +140,21 @@ func (mr *MockUserRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserRepository)(nil).Delete), ctx, id)
 }
 
+// EstimateCountByFilter mocks base method.
+func (m *MockUserRepository) EstimateCountByFilter(ctx context.Context, filter domain.UserFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EstimateCountByFilter", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EstimateCountByFilter indicates an expected call of EstimateCountByFilter.
+func (mr *MockUserRepositoryMockRecorder) EstimateCountByFilter(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateCountByFilter", reflect.TypeOf((*MockUserRepository)(nil).EstimateCountByFilter), ctx, filter)
+}
+
 // FindByAccount mocks base method.
 func (m *MockUserRepository) FindByAccount(ctx context.Context, account string) (domain.User, error) {
 	m.ctrl.T.Helper()
@@ -109,6 +170,51 @@ func (mr *MockUserRepositoryMockRecorder) FindByAccount(ctx, account interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByAccount", reflect.TypeOf((*MockUserRepository)(nil).FindByAccount), ctx, account)
 }
 
+// FindByEmail mocks base method.
+func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByEmail", ctx, email)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByEmail indicates an expected call of FindByEmail.
+func (mr *MockUserRepositoryMockRecorder) FindByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmail", reflect.TypeOf((*MockUserRepository)(nil).FindByEmail), ctx, email)
+}
+
+// FindByPhone mocks base method.
+func (m *MockUserRepository) FindByPhone(ctx context.Context, phone string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByPhone", ctx, phone)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByPhone indicates an expected call of FindByPhone.
+func (mr *MockUserRepositoryMockRecorder) FindByPhone(ctx, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPhone", reflect.TypeOf((*MockUserRepository)(nil).FindByPhone), ctx, phone)
+}
+
+// Freeze mocks base method.
+func (m *MockUserRepository) Freeze(ctx context.Context, id int64, reason string, until time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Freeze", ctx, id, reason, until)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Freeze indicates an expected call of Freeze.
+func (mr *MockUserRepositoryMockRecorder) Freeze(ctx, id, reason, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Freeze", reflect.TypeOf((*MockUserRepository)(nil).Freeze), ctx, id, reason, until)
+}
+
 // GetOneById mocks base method.
 func (m *MockUserRepository) GetOneById(ctx context.Context, id int64) (domain.User, error) {
 	m.ctrl.T.Helper()
@@ -124,18 +230,136 @@ func (mr *MockUserRepositoryMockRecorder) GetOneById(ctx, id interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneById", reflect.TypeOf((*MockUserRepository)(nil).GetOneById), ctx, id)
 }
 
-// ListByUsername mocks base method.
-func (m *MockUserRepository) ListByUsername(ctx context.Context, username string, offset, limit int) ([]domain.User, int64, error) {
+// ListBatch mocks base method.
+func (m *MockUserRepository) ListBatch(ctx context.Context, filter domain.UserFilter, afterID int64, limit int) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBatch", ctx, filter, afterID, limit)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBatch indicates an expected call of ListBatch.
+func (mr *MockUserRepositoryMockRecorder) ListBatch(ctx, filter, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBatch", reflect.TypeOf((*MockUserRepository)(nil).ListBatch), ctx, filter, afterID, limit)
+}
+
+// RehashPassword mocks base method.
+func (m *MockUserRepository) RehashPassword(ctx context.Context, id int64, oldHash, newHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RehashPassword", ctx, id, oldHash, newHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RehashPassword indicates an expected call of RehashPassword.
+func (mr *MockUserRepositoryMockRecorder) RehashPassword(ctx, id, oldHash, newHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RehashPassword", reflect.TypeOf((*MockUserRepository)(nil).RehashPassword), ctx, id, oldHash, newHash)
+}
+
+// Search mocks base method.
+func (m *MockUserRepository) Search(ctx context.Context, query domain.UserQuery) ([]domain.User, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListByUsername", ctx, username, offset, limit)
+	ret := m.ctrl.Call(m, "Search", ctx, query)
 	ret0, _ := ret[0].([]domain.User)
 	ret1, _ := ret[1].(int64)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
-// ListByUsername indicates an expected call of ListByUsername.
-func (mr *MockUserRepositoryMockRecorder) ListByUsername(ctx, username, offset, limit interface{}) *gomock.Call {
+// Search indicates an expected call of Search.
+func (mr *MockUserRepositoryMockRecorder) Search(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUserRepository)(nil).Search), ctx, query)
+}
+
+// SearchAfter mocks base method.
+func (m *MockUserRepository) SearchAfter(ctx context.Context, filter domain.UserFilter, sorts []domain.UserSort, after []any, limit int) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAfter", ctx, filter, sorts, after, limit)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAfter indicates an expected call of SearchAfter.
+func (mr *MockUserRepositoryMockRecorder) SearchAfter(ctx, filter, sorts, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAfter", reflect.TypeOf((*MockUserRepository)(nil).SearchAfter), ctx, filter, sorts, after, limit)
+}
+
+// Unfreeze mocks base method.
+func (m *MockUserRepository) Unfreeze(ctx context.Context, id int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unfreeze", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unfreeze indicates an expected call of Unfreeze.
+func (mr *MockUserRepositoryMockRecorder) Unfreeze(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unfreeze", reflect.TypeOf((*MockUserRepository)(nil).Unfreeze), ctx, id)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, id int64, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, id, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserRepositoryMockRecorder) UpdatePassword(ctx, id, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepository)(nil).UpdatePassword), ctx, id, password)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUserRepository) UpdateProfile(ctx context.Context, id int64, profile domain.UserProfile) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, id, profile)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUserRepositoryMockRecorder) UpdateProfile(ctx, id, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserRepository)(nil).UpdateProfile), ctx, id, profile)
+}
+
+// UpdateRole mocks base method.
+func (m *MockUserRepository) UpdateRole(ctx context.Context, id int64, role int32) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, id, role)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockUserRepositoryMockRecorder) UpdateRole(ctx, id, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockUserRepository)(nil).UpdateRole), ctx, id, role)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockUserRepository) VerifyEmail(ctx context.Context, id int64, email string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, id, email)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserRepositoryMockRecorder) VerifyEmail(ctx, id, email interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUsername", reflect.TypeOf((*MockUserRepository)(nil).ListByUsername), ctx, username, offset, limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserRepository)(nil).VerifyEmail), ctx, id, email)
 }
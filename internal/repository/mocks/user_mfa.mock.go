@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./user_mfa.go
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/coderlewin/ucenter/internal/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockUserMFARepository is a mock of UserMFARepository interface.
+type MockUserMFARepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserMFARepositoryMockRecorder
+}
+
+// MockUserMFARepositoryMockRecorder is the mock recorder for MockUserMFARepository.
+type MockUserMFARepositoryMockRecorder struct {
+	mock *MockUserMFARepository
+}
+
+// NewMockUserMFARepository creates a new mock instance.
+func NewMockUserMFARepository(ctrl *gomock.Controller) *MockUserMFARepository {
+	mock := &MockUserMFARepository{ctrl: ctrl}
+	mock.recorder = &MockUserMFARepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserMFARepository) EXPECT() *MockUserMFARepositoryMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockUserMFARepository) Delete(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUserMFARepositoryMockRecorder) Delete(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserMFARepository)(nil).Delete), ctx, uid)
+}
+
+// Enable mocks base method.
+func (m *MockUserMFARepository) Enable(ctx context.Context, uid int64, recoveryCodes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, uid, recoveryCodes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable.
+func (mr *MockUserMFARepositoryMockRecorder) Enable(ctx, uid, recoveryCodes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockUserMFARepository)(nil).Enable), ctx, uid, recoveryCodes)
+}
+
+// FindByUserID mocks base method.
+func (m *MockUserMFARepository) FindByUserID(ctx context.Context, uid int64) (domain.UserMFA, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserID", ctx, uid)
+	ret0, _ := ret[0].(domain.UserMFA)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserID indicates an expected call of FindByUserID.
+func (mr *MockUserMFARepositoryMockRecorder) FindByUserID(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserID", reflect.TypeOf((*MockUserMFARepository)(nil).FindByUserID), ctx, uid)
+}
+
+// Save mocks base method.
+func (m *MockUserMFARepository) Save(ctx context.Context, mfa domain.UserMFA) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, mfa)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockUserMFARepositoryMockRecorder) Save(ctx, mfa interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockUserMFARepository)(nil).Save), ctx, mfa)
+}
+
+// UpdateRecoveryCodes mocks base method.
+func (m *MockUserMFARepository) UpdateRecoveryCodes(ctx context.Context, uid int64, old, new []string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRecoveryCodes", ctx, uid, old, new)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRecoveryCodes indicates an expected call of UpdateRecoveryCodes.
+func (mr *MockUserMFARepositoryMockRecorder) UpdateRecoveryCodes(ctx, uid, old, new interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRecoveryCodes", reflect.TypeOf((*MockUserMFARepository)(nil).UpdateRecoveryCodes), ctx, uid, old, new)
+}
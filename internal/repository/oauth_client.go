@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/infrastructure/entity"
+	"github.com/coderlewin/ucenter/internal/infrastructure/persistence"
+	"github.com/duke-git/lancet/v2/slice"
+	"strings"
+)
+
+//go:generate mockgen -source=./oauth_client.go -package=repomocks -destination=mocks/oauth_client.mock.go OAuthClientRepository
+type OAuthClientRepository interface {
+	Create(ctx context.Context, client domain.OAuthClient) (int64, error)
+	Delete(ctx context.Context, id int64) error
+	FindByClientID(ctx context.Context, clientID string) (domain.OAuthClient, error)
+	List(ctx context.Context) ([]domain.OAuthClient, error)
+}
+
+func NewOAuthClientRepository(clientDao persistence.OAuthClientDAO) OAuthClientRepository {
+	return &oauthClientRepository{clientDao: clientDao}
+}
+
+type oauthClientRepository struct {
+	clientDao persistence.OAuthClientDAO
+}
+
+func (o *oauthClientRepository) Create(ctx context.Context, client domain.OAuthClient) (int64, error) {
+	return o.clientDao.Insert(ctx, o.domainToEntity(client))
+}
+
+func (o *oauthClientRepository) Delete(ctx context.Context, id int64) error {
+	return o.clientDao.Delete(ctx, id)
+}
+
+func (o *oauthClientRepository) FindByClientID(ctx context.Context, clientID string) (domain.OAuthClient, error) {
+	client, err := o.clientDao.FindByClientID(ctx, clientID)
+	if err != nil {
+		return domain.OAuthClient{}, err
+	}
+	return o.entityToDomain(client), nil
+}
+
+func (o *oauthClientRepository) List(ctx context.Context) ([]domain.OAuthClient, error) {
+	clients, err := o.clientDao.SelectAll(ctx)
+	list := slice.Map(clients, func(index int, item entity.OauthClient) domain.OAuthClient {
+		return o.entityToDomain(item)
+	})
+	return list, err
+}
+
+func (o *oauthClientRepository) domainToEntity(client domain.OAuthClient) entity.OauthClient {
+	return entity.OauthClient{
+		ID:           client.ID,
+		ClientID:     client.ClientID,
+		ClientSecret: client.ClientSecret,
+		Name:         client.Name,
+		RedirectUris: strings.Join(client.RedirectURIs, "\n"),
+	}
+}
+
+func (o *oauthClientRepository) entityToDomain(client entity.OauthClient) domain.OAuthClient {
+	var uris []string
+	if client.RedirectUris != "" {
+		uris = strings.Split(client.RedirectUris, "\n")
+	}
+	return domain.OAuthClient{
+		ID:           client.ID,
+		ClientID:     client.ClientID,
+		ClientSecret: client.ClientSecret,
+		Name:         client.Name,
+		RedirectURIs: uris,
+		CreateTime:   client.CreateTime,
+		UpdateTime:   client.UpdateTime,
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"github.com/coderlewin/ucenter/internal/infrastructure/entity"
+	"github.com/coderlewin/ucenter/internal/infrastructure/persistence"
+	"github.com/duke-git/lancet/v2/slice"
+)
+
+//go:generate mockgen -source=./password_history.go -package=repomocks -destination=mocks/password_history.mock.go PasswordHistoryRepository
+type PasswordHistoryRepository interface {
+	// Add 记录用户设置的密码哈希, 只保留最近的 keep 条
+	Add(ctx context.Context, uid int64, password string, keep int) error
+	// ListRecent 返回用户最近使用过的 limit 个密码哈希, 最新的在前
+	ListRecent(ctx context.Context, uid int64, limit int) ([]string, error)
+}
+
+func NewPasswordHistoryRepository(historyDao persistence.PasswordHistoryDAO) PasswordHistoryRepository {
+	return &passwordHistoryRepository{historyDao: historyDao}
+}
+
+type passwordHistoryRepository struct {
+	historyDao persistence.PasswordHistoryDAO
+}
+
+func (p *passwordHistoryRepository) Add(ctx context.Context, uid int64, password string, keep int) error {
+	err := p.historyDao.Insert(ctx, entity.UserPasswordHistory{
+		UserID:       uid,
+		UserPassword: password,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 清理更早的记录
+	list, err := p.historyDao.FindRecent(ctx, uid, keep)
+	if err != nil || len(list) < keep {
+		return err
+	}
+	return p.historyDao.DeleteBefore(ctx, uid, list[len(list)-1].ID)
+}
+
+func (p *passwordHistoryRepository) ListRecent(ctx context.Context, uid int64, limit int) ([]string, error) {
+	list, err := p.historyDao.FindRecent(ctx, uid, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(list, func(index int, item entity.UserPasswordHistory) string {
+		return item.UserPassword
+	}), nil
+}
@@ -2,21 +2,55 @@ package repository
 
 import (
 	"context"
+	"github.com/coderlewin/ucenter/internal/constants"
 	"github.com/coderlewin/ucenter/internal/domain"
 	"github.com/coderlewin/ucenter/internal/infrastructure/entity"
 	"github.com/coderlewin/ucenter/internal/infrastructure/persistence"
 	"github.com/duke-git/lancet/v2/slice"
+	"time"
 )
 
+// ErrAmbiguousEmail 邮箱被多个用户使用, 无法确定对应的用户
+var ErrAmbiguousEmail = persistence.ErrAmbiguousRecord
+
+// ErrAmbiguousPhone 手机号被多个用户使用, 无法确定对应的用户
+var ErrAmbiguousPhone = persistence.ErrAmbiguousRecord
+
 //go:generate mockgen -source=./user.go -package=repomocks -destination=mocks/user.mock.go UserRepository
 type UserRepository interface {
 	Create(ctx context.Context, user domain.User) (int64, error)
 	Delete(ctx context.Context, id int64) error
 	GetOneById(ctx context.Context, id int64) (domain.User, error)
 	FindByAccount(ctx context.Context, account string) (domain.User, error)
+	// FindByEmail 按邮箱查找用户, 邮箱被多个用户使用时返回 ErrAmbiguousEmail
+	FindByEmail(ctx context.Context, email string) (domain.User, error)
+	// FindByPhone 按手机号查找用户, 手机号被多个用户使用时返回 ErrAmbiguousPhone
+	FindByPhone(ctx context.Context, phone string) (domain.User, error)
 	CountByAccount(ctx context.Context, account string) (int64, error)
 	CountByPlanetCode(ctx context.Context, planetCode string) (int64, error)
-	ListByUsername(ctx context.Context, username string, offset int, limit int) ([]domain.User, int64, error)
+	CountByEmail(ctx context.Context, email string) (int64, error)
+	// Search 按筛选条件和排序分页查询用户, 返回当前页和总数
+	Search(ctx context.Context, query domain.UserQuery) ([]domain.User, int64, error)
+	// SearchAfter 按筛选条件和排序返回排在 after 之后的最多 limit 个用户, sorts 需要以 id 结尾
+	SearchAfter(ctx context.Context, filter domain.UserFilter, sorts []domain.UserSort, after []any, limit int) ([]domain.User, error)
+	CountByFilter(ctx context.Context, filter domain.UserFilter) (int64, error)
+	// EstimateCountByFilter 估算满足筛选条件的用户数量, 不扫描数据
+	EstimateCountByFilter(ctx context.Context, filter domain.UserFilter) (int64, error)
+	UpdatePassword(ctx context.Context, id int64, password string) error
+	// RehashPassword 将密码哈希 oldHash 升级为 newHash, 不影响已签发的登录态. 密码已被修改时返回 false
+	RehashPassword(ctx context.Context, id int64, oldHash, newHash string) (bool, error)
+	// UpdateProfile 只更新 profile 中不为 nil 的字段
+	UpdateProfile(ctx context.Context, id int64, profile domain.UserProfile) error
+	CountByPhone(ctx context.Context, phone string) (int64, error)
+	// Freeze 冻结用户, until 为零值时永久冻结. 目标是最后一个未被冻结的管理员时不冻结, 返回 false
+	Freeze(ctx context.Context, id int64, reason string, until time.Time) (bool, error)
+	// Unfreeze 解冻处于冻结状态的用户并恢复冻结前的状态, 返回是否更新成功
+	Unfreeze(ctx context.Context, id int64) (bool, error)
+	// UpdateRole 修改用户角色. 目标是最后一个未被冻结的管理员时不修改, 返回 false
+	UpdateRole(ctx context.Context, id int64, role int32) (bool, error)
+	VerifyEmail(ctx context.Context, id int64, email string) (bool, error)
+	// ListBatch 按 id 升序返回 id 大于 afterID 的最多 limit 个用户, 用于分批遍历
+	ListBatch(ctx context.Context, filter domain.UserFilter, afterID int64, limit int) ([]domain.User, error)
 }
 
 func NewUserRepository(userDao persistence.UserDAO) UserRepository {
@@ -27,14 +61,102 @@ type userRepository struct {
 	userDao persistence.UserDAO
 }
 
-func (u *userRepository) ListByUsername(ctx context.Context, username string, offset int, limit int) ([]domain.User, int64, error) {
-	users, total, err := u.userDao.SelectPageByUsername(ctx, username, offset, limit)
+func (u *userRepository) Search(ctx context.Context, query domain.UserQuery) ([]domain.User, int64, error) {
+	q := filterToQuery(query.Filter)
+	q.Orders = slice.Map(query.Sorts, func(index int, item domain.UserSort) persistence.Order {
+		return persistence.Order{Column: item.Field, Desc: item.Desc}
+	})
+	users, total, err := u.userDao.SelectPage(ctx, q, query.Offset(), query.Size)
 	list := slice.Map(users, func(index int, item entity.User) domain.User {
 		return u.entityToDomain(item)
 	})
 	return list, total, err
 }
 
+func (u *userRepository) SearchAfter(ctx context.Context, filter domain.UserFilter, sorts []domain.UserSort, after []any, limit int) ([]domain.User, error) {
+	q := filterToQuery(filter)
+	q.Orders = slice.Map(sorts, func(index int, item domain.UserSort) persistence.Order {
+		return persistence.Order{Column: item.Field, Desc: item.Desc}
+	})
+	users, err := u.userDao.SelectAfter(ctx, q, after, limit)
+	return slice.Map(users, func(index int, item entity.User) domain.User {
+		return u.entityToDomain(item)
+	}), err
+}
+
+func (u *userRepository) CountByFilter(ctx context.Context, filter domain.UserFilter) (int64, error) {
+	return u.userDao.CountByQuery(ctx, filterToQuery(filter))
+}
+
+func (u *userRepository) EstimateCountByFilter(ctx context.Context, filter domain.UserFilter) (int64, error) {
+	return u.userDao.EstimateCountByQuery(ctx, filterToQuery(filter))
+}
+
+func (u *userRepository) ListBatch(ctx context.Context, filter domain.UserFilter, afterID int64, limit int) ([]domain.User, error) {
+	users, err := u.userDao.SelectBatch(ctx, filterToQuery(filter), afterID, limit)
+	return slice.Map(users, func(index int, item entity.User) domain.User {
+		return u.entityToDomain(item)
+	}), err
+}
+
+func filterToQuery(filter domain.UserFilter) persistence.UserQuery {
+	return persistence.UserQuery{
+		Username:    filter.Username,
+		Account:     filter.Account,
+		PlanetCode:  filter.PlanetCode,
+		Email:       filter.Email,
+		Phone:       filter.Phone,
+		Role:        filter.Role,
+		Status:      filter.Status,
+		Gender:      filter.Gender,
+		CreatedFrom: filter.CreatedFrom,
+		CreatedTo:   filter.CreatedTo,
+	}
+}
+
+func (u *userRepository) UpdatePassword(ctx context.Context, id int64, password string) error {
+	return u.userDao.UpdatePassword(ctx, id, password)
+}
+
+func (u *userRepository) RehashPassword(ctx context.Context, id int64, oldHash, newHash string) (bool, error) {
+	return u.userDao.RehashPassword(ctx, id, oldHash, newHash)
+}
+
+func (u *userRepository) UpdateProfile(ctx context.Context, id int64, profile domain.UserProfile) error {
+	fields := make(map[string]any, 5)
+	if profile.Username != nil {
+		fields["username"] = *profile.Username
+	}
+	if profile.AvatarURL != nil {
+		fields["avatar_url"] = *profile.AvatarURL
+	}
+	if profile.Gender != nil {
+		fields["gender"] = *profile.Gender
+	}
+	if profile.Phone != nil {
+		fields["phone"] = *profile.Phone
+	}
+	if profile.Email != nil {
+		fields["email"] = *profile.Email
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return u.userDao.Update(ctx, id, fields)
+}
+
+func (u *userRepository) Freeze(ctx context.Context, id int64, reason string, until time.Time) (bool, error) {
+	return u.userDao.Freeze(ctx, id, reason, toNullableTime(until), constants.AdminRole)
+}
+
+func (u *userRepository) Unfreeze(ctx context.Context, id int64) (bool, error) {
+	return u.userDao.Unfreeze(ctx, id)
+}
+
+func (u *userRepository) UpdateRole(ctx context.Context, id int64, role int32) (bool, error) {
+	return u.userDao.UpdateRole(ctx, id, role, constants.AdminRole)
+}
+
 func (u *userRepository) GetOneById(ctx context.Context, id int64) (domain.User, error) {
 	user, err := u.userDao.GetByID(ctx, id)
 	return u.entityToDomain(user), err
@@ -48,6 +170,18 @@ func (u *userRepository) CountByPlanetCode(ctx context.Context, planetCode strin
 	return u.userDao.Count(ctx, "planet_code", planetCode)
 }
 
+func (u *userRepository) CountByPhone(ctx context.Context, phone string) (int64, error) {
+	return u.userDao.Count(ctx, "phone", phone)
+}
+
+func (u *userRepository) CountByEmail(ctx context.Context, email string) (int64, error) {
+	return u.userDao.Count(ctx, "email", email)
+}
+
+func (u *userRepository) VerifyEmail(ctx context.Context, id int64, email string) (bool, error) {
+	return u.userDao.VerifyEmail(ctx, id, email)
+}
+
 func (u *userRepository) FindByAccount(ctx context.Context, account string) (domain.User, error) {
 	user, err := u.userDao.FindByAccount(ctx, account)
 	if err != nil {
@@ -57,6 +191,22 @@ func (u *userRepository) FindByAccount(ctx context.Context, account string) (dom
 	return ud, nil
 }
 
+func (u *userRepository) FindByEmail(ctx context.Context, email string) (domain.User, error) {
+	user, err := u.userDao.FindByEmail(ctx, email)
+	if err != nil {
+		return domain.User{}, err
+	}
+	return u.entityToDomain(user), nil
+}
+
+func (u *userRepository) FindByPhone(ctx context.Context, phone string) (domain.User, error) {
+	user, err := u.userDao.FindByPhone(ctx, phone)
+	if err != nil {
+		return domain.User{}, err
+	}
+	return u.entityToDomain(user), nil
+}
+
 func (u *userRepository) Delete(ctx context.Context, id int64) error {
 	return u.userDao.Delete(ctx, id)
 }
@@ -67,34 +217,54 @@ func (u *userRepository) Create(ctx context.Context, user domain.User) (int64, e
 
 func (u *userRepository) domainToEntity(user domain.User) entity.User {
 	return entity.User{
-		ID:           user.ID,
-		Username:     user.Username,
-		UserAccount:  user.UserAccount,
-		AvatarURL:    user.AvatarURL,
-		Gender:       user.Gender,
-		UserPassword: user.UserPassword,
-		Phone:        user.Phone,
-		Email:        user.Email,
-		UserStatus:   user.UserStatus,
-		UserRole:     user.UserRole,
-		PlanetCode:   user.PlanetCode,
+		ID:              user.ID,
+		Username:        user.Username,
+		UserAccount:     user.UserAccount,
+		AvatarURL:       user.AvatarURL,
+		Gender:          user.Gender,
+		UserPassword:    user.UserPassword,
+		Phone:           user.Phone,
+		Email:           user.Email,
+		UserStatus:      user.UserStatus,
+		UserRole:        user.UserRole,
+		PlanetCode:      user.PlanetCode,
+		SecurityVersion: user.SecurityVersion,
+		FreezeReason:    user.FreezeReason,
+		FreezeUntil:     toNullableTime(user.FreezeUntil),
 	}
 }
 
 func (u *userRepository) entityToDomain(user entity.User) domain.User {
 	return domain.User{
-		ID:           user.ID,
-		Username:     user.Username,
-		UserAccount:  user.UserAccount,
-		AvatarURL:    user.AvatarURL,
-		Gender:       user.Gender,
-		UserPassword: user.UserPassword,
-		Phone:        user.Phone,
-		Email:        user.Email,
-		UserStatus:   user.UserStatus,
-		UserRole:     user.UserRole,
-		PlanetCode:   user.PlanetCode,
-		CreateTime:   user.CreateTime,
-		UpdateTime:   user.UpdateTime,
+		ID:              user.ID,
+		Username:        user.Username,
+		UserAccount:     user.UserAccount,
+		AvatarURL:       user.AvatarURL,
+		Gender:          user.Gender,
+		UserPassword:    user.UserPassword,
+		Phone:           user.Phone,
+		Email:           user.Email,
+		UserStatus:      user.UserStatus,
+		UserRole:        user.UserRole,
+		PlanetCode:      user.PlanetCode,
+		SecurityVersion: user.SecurityVersion,
+		FreezeReason:    user.FreezeReason,
+		FreezeUntil:     fromNullableTime(user.FreezeUntil),
+		CreateTime:      user.CreateTime,
+		UpdateTime:      user.UpdateTime,
+	}
+}
+
+func toNullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func fromNullableTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
 	}
+	return *t
 }
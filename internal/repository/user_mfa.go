@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/infrastructure/entity"
+	"github.com/coderlewin/ucenter/internal/infrastructure/persistence"
+	"strings"
+)
+
+//go:generate mockgen -source=./user_mfa.go -package=repomocks -destination=mocks/user_mfa.mock.go UserMFARepository
+type UserMFARepository interface {
+	Save(ctx context.Context, mfa domain.UserMFA) error
+	FindByUserID(ctx context.Context, uid int64) (domain.UserMFA, error)
+	Enable(ctx context.Context, uid int64, recoveryCodes []string) error
+	// UpdateRecoveryCodes 乐观锁更新恢复码, 防止同一个恢复码被并发使用
+	UpdateRecoveryCodes(ctx context.Context, uid int64, old, new []string) (bool, error)
+	Delete(ctx context.Context, uid int64) error
+}
+
+func NewUserMFARepository(mfaDao persistence.UserMFADAO) UserMFARepository {
+	return &userMFARepository{mfaDao: mfaDao}
+}
+
+type userMFARepository struct {
+	mfaDao persistence.UserMFADAO
+}
+
+func (u *userMFARepository) Save(ctx context.Context, mfa domain.UserMFA) error {
+	return u.mfaDao.Upsert(ctx, u.domainToEntity(mfa))
+}
+
+func (u *userMFARepository) FindByUserID(ctx context.Context, uid int64) (domain.UserMFA, error) {
+	mfa, err := u.mfaDao.FindByUserID(ctx, uid)
+	if err != nil {
+		return domain.UserMFA{}, err
+	}
+	return u.entityToDomain(mfa), nil
+}
+
+func (u *userMFARepository) Enable(ctx context.Context, uid int64, recoveryCodes []string) error {
+	return u.mfaDao.Enable(ctx, uid, strings.Join(recoveryCodes, "\n"))
+}
+
+func (u *userMFARepository) UpdateRecoveryCodes(ctx context.Context, uid int64, old, new []string) (bool, error) {
+	return u.mfaDao.UpdateRecoveryCodes(ctx, uid, strings.Join(old, "\n"), strings.Join(new, "\n"))
+}
+
+func (u *userMFARepository) Delete(ctx context.Context, uid int64) error {
+	return u.mfaDao.Delete(ctx, uid)
+}
+
+func (u *userMFARepository) domainToEntity(mfa domain.UserMFA) entity.UserMfa {
+	var enabled int32
+	if mfa.Enabled {
+		enabled = 1
+	}
+	return entity.UserMfa{
+		UserID:        mfa.UserID,
+		TotpSecret:    mfa.TOTPSecret,
+		Enabled:       enabled,
+		RecoveryCodes: strings.Join(mfa.RecoveryCodes, "\n"),
+	}
+}
+
+func (u *userMFARepository) entityToDomain(mfa entity.UserMfa) domain.UserMFA {
+	var codes []string
+	if mfa.RecoveryCodes != "" {
+		codes = strings.Split(mfa.RecoveryCodes, "\n")
+	}
+	return domain.UserMFA{
+		UserID:        mfa.UserID,
+		TOTPSecret:    mfa.TotpSecret,
+		Enabled:       mfa.Enabled == 1,
+		RecoveryCodes: codes,
+	}
+}
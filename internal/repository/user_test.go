@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"github.com/coderlewin/ucenter/internal/constants"
 	"github.com/coderlewin/ucenter/internal/domain"
 	"github.com/coderlewin/ucenter/internal/infrastructure/entity"
 	"github.com/coderlewin/ucenter/internal/infrastructure/persistence"
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
 func Test_userRepository_GetOneById(t *testing.T) {
@@ -78,3 +80,21 @@ func Test_userRepository_GetOneById(t *testing.T) {
 		})
 	}
 }
+
+func Test_userRepository_KeepLastAdmin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	d := daomocks.NewMockUserDAO(ctrl)
+	repo := NewUserRepository(d)
+
+	// 冻结和修改角色都要保留至少一个未被冻结的管理员, 永久冻结时截止时间为空
+	d.EXPECT().Freeze(gomock.Any(), int64(2), "违规", (*time.Time)(nil), int32(constants.AdminRole)).Return(false, nil)
+	ok, err := repo.Freeze(context.Background(), 2, "违规", time.Time{})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	d.EXPECT().UpdateRole(gomock.Any(), int64(2), int32(0), int32(constants.AdminRole)).Return(true, nil)
+	ok, err = repo.UpdateRole(context.Background(), 2, 0)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
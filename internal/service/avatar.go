@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/repository"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/imaging"
+	"github.com/coderlewin/ucenter/pkg/storage"
+	"github.com/google/uuid"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// AvatarOptions 定义头像上传的选项
+type AvatarOptions struct {
+	MaxSize   int64 // 上传文件的最大字节数
+	MaxPixels int   // 允许的最大像素数, 超过时不解码
+	Sizes     []int // 生成的正方形边长, 从大到小排列, 第一个作为用户头像
+}
+
+//go:generate mockgen -source=./avatar.go -package=svcmocks -destination=./mocks/avatar.mock.go AvatarService
+type AvatarService interface {
+	// Upload 从中心裁剪为正方形并缩放到各个尺寸后保存, 用户头像更新为最大尺寸的地址
+	Upload(ctx context.Context, uid int64, data []byte) (domain.Avatar, error)
+}
+
+func NewAvatarService(opts AvatarOptions, blobStorage storage.BlobStorage, userRepo repository.UserRepository,
+	userSvc UserService) AvatarService {
+	return &avatarService{opts: opts, storage: blobStorage, userRepo: userRepo, userSvc: userSvc}
+}
+
+type avatarService struct {
+	opts     AvatarOptions
+	storage  storage.BlobStorage
+	userRepo repository.UserRepository
+	userSvc  UserService
+}
+
+func (a *avatarService) Upload(ctx context.Context, uid int64, data []byte) (domain.Avatar, error) {
+	if len(data) == 0 {
+		return domain.Avatar{}, errno.ErrParameterInvalid.SetDescription("请选择头像图片")
+	}
+	if int64(len(data)) > a.opts.MaxSize {
+		return domain.Avatar{}, errno.ErrParameterInvalid.SetDescription("头像不能超过 %d KB", a.opts.MaxSize>>10)
+	}
+	// 按文件内容而不是文件名或请求头判断类型
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return domain.Avatar{}, errno.ErrParameterInvalid.SetDescription("只支持 JPEG、PNG、GIF 格式的图片")
+	}
+	img, format, err := imaging.Decode(data, a.opts.MaxPixels)
+	if errors.Is(err, imaging.ErrTooLarge) {
+		return domain.Avatar{}, errno.ErrParameterInvalid.SetDescription("图片尺寸过大")
+	}
+	if err != nil {
+		return domain.Avatar{}, errno.ErrParameterInvalid.SetDescription("图片无法解析")
+	}
+
+	user, err := a.userSvc.GetCurrentUser(ctx, uid)
+	if err != nil {
+		return domain.Avatar{}, err
+	}
+
+	// 每次上传使用新的目录, 地址变化后客户端和 CDN 的缓存自然失效
+	square := imaging.CropSquare(img)
+	dir := fmt.Sprintf("avatars/%d/%s", uid, uuid.NewString())
+	avatar := domain.Avatar{Sizes: make(map[int]string, len(a.opts.Sizes))}
+	keys := make([]string, 0, len(a.opts.Sizes))
+	for _, size := range a.opts.Sizes {
+		// 重新编码后不保留 EXIF 等元数据
+		out, err := imaging.Encode(imaging.Resize(square, size, size), format)
+		if err != nil {
+			a.deleteKeys(ctx, keys)
+			return domain.Avatar{}, err
+		}
+		key := fmt.Sprintf("%s/%d%s", dir, size, imaging.Ext(format))
+		if err = a.storage.Put(ctx, key, out, imaging.ContentType(format)); err != nil {
+			a.deleteKeys(ctx, keys)
+			return domain.Avatar{}, err
+		}
+		keys = append(keys, key)
+		avatar.Sizes[size] = a.storage.URL(key)
+	}
+	avatar.URL = avatar.Sizes[a.opts.Sizes[0]]
+
+	if err = a.userRepo.UpdateProfile(ctx, uid, domain.UserProfile{AvatarURL: &avatar.URL}); err != nil {
+		a.deleteKeys(ctx, keys)
+		return domain.Avatar{}, errno.ErrDBFailed
+	}
+	a.deleteOld(ctx, uid, user.AvatarURL)
+	return avatar, nil
+}
+
+// deleteOld 删除之前上传的头像, 手动设置的外部地址不处理
+func (a *avatarService) deleteOld(ctx context.Context, uid int64, oldURL string) {
+	prefix := a.storage.URL("")
+	if !strings.HasPrefix(oldURL, a.storage.URL(fmt.Sprintf("avatars/%d/", uid))) {
+		return
+	}
+	oldKey := strings.TrimPrefix(oldURL, prefix)
+	dir, ext := path.Dir(oldKey), path.Ext(oldKey)
+	keys := make([]string, 0, len(a.opts.Sizes))
+	for _, size := range a.opts.Sizes {
+		keys = append(keys, fmt.Sprintf("%s/%d%s", dir, size, ext))
+	}
+	a.deleteKeys(ctx, keys)
+}
+
+// deleteKeys 删除失败只记录日志, 不影响上传结果
+func (a *avatarService) deleteKeys(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := a.storage.Delete(ctx, key); err != nil {
+			hlog.CtxWarnf(ctx, "delete avatar failed, key=%s, err=%v", key, err)
+		}
+	}
+}
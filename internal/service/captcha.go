@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/pkg/captcha"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"strings"
+	"time"
+)
+
+// 验证码的校验策略
+const (
+	CaptchaPolicyAlways        = "always"
+	CaptchaPolicyNever         = "never"
+	CaptchaPolicyAfterFailures = "after-failures"
+)
+
+// CaptchaOptions 定义图形验证码的选项
+type CaptchaOptions struct {
+	Generator      captcha.Generator
+	Width          int
+	Height         int
+	Expiration     time.Duration
+	RegisterPolicy string // 注册时的校验策略, always 或 never
+	LoginPolicy    string // 登录时的校验策略, always、never 或 after-failures
+	LoginFailures  int64  // after-failures 策略下, 登录失败多少次后需要验证码
+}
+
+//go:generate mockgen -source=./captcha.go -package=svcmocks -destination=./mocks/captcha.mock.go CaptchaService
+type CaptchaService interface {
+	Generate(ctx context.Context) (domain.Captcha, error)
+	// Verify 校验验证码, 无论是否正确验证码都会失效
+	Verify(ctx context.Context, id string, answer string) error
+	// CheckRegister 按注册策略校验验证码
+	CheckRegister(ctx context.Context, id string, answer string) error
+	// CheckLogin 按登录策略校验验证码
+	CheckLogin(ctx context.Context, account string, ip string, id string, answer string) error
+}
+
+func NewCaptchaService(opts CaptchaOptions, cmd redis.Cmdable, loginLimitSvc LoginLimitService) CaptchaService {
+	return &captchaService{opts: opts, cmd: cmd, loginLimitSvc: loginLimitSvc}
+}
+
+type captchaService struct {
+	opts          CaptchaOptions
+	cmd           redis.Cmdable
+	loginLimitSvc LoginLimitService
+}
+
+func (s *captchaService) Generate(ctx context.Context) (domain.Captcha, error) {
+	ch, err := s.opts.Generator.Generate()
+	if err != nil {
+		return domain.Captcha{}, err
+	}
+	img, err := captcha.Render(ch.Text, s.opts.Width, s.opts.Height)
+	if err != nil {
+		return domain.Captcha{}, err
+	}
+	id := uuid.NewString()
+	if err = s.cmd.Set(ctx, s.key(id), ch.Answer, s.opts.Expiration).Err(); err != nil {
+		return domain.Captcha{}, err
+	}
+	return domain.Captcha{ID: id, Image: img}, nil
+}
+
+func (s *captchaService) Verify(ctx context.Context, id string, answer string) error {
+	if id == "" || answer == "" {
+		return errno.ErrCaptchaInvalid.SetDescription("请输入图形验证码")
+	}
+	// 取出即删除, 每个验证码只能尝试一次
+	want, err := s.cmd.GetDel(ctx, s.key(id)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return errno.ErrCaptchaInvalid.SetDescription("图形验证码已过期, 请刷新")
+		}
+		return err
+	}
+	if !strings.EqualFold(strings.TrimSpace(answer), want) {
+		return errno.ErrCaptchaInvalid.SetDescription("图形验证码错误, 请刷新后重试")
+	}
+	return nil
+}
+
+func (s *captchaService) CheckRegister(ctx context.Context, id string, answer string) error {
+	if s.opts.RegisterPolicy != CaptchaPolicyAlways {
+		return nil
+	}
+	return s.Verify(ctx, id, answer)
+}
+
+func (s *captchaService) CheckLogin(ctx context.Context, account string, ip string, id string, answer string) error {
+	switch s.opts.LoginPolicy {
+	case CaptchaPolicyAlways:
+		return s.Verify(ctx, id, answer)
+	case CaptchaPolicyAfterFailures:
+		locks, err := s.loginLimitSvc.GetLocks(ctx, normalizeLoginAccount(account), ip)
+		if err != nil {
+			return err
+		}
+		for _, lock := range locks {
+			if lock.Failures >= s.opts.LoginFailures {
+				return s.Verify(ctx, id, answer)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *captchaService) key(id string) string {
+	return fmt.Sprintf("ucenter:captcha:%s", id)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/identicon"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// 默认头像的样式
+const (
+	DefaultAvatarStyleIdenticon = "identicon" // 对称的格子图案
+	DefaultAvatarStyleInitials  = "initials"  // 用户名首字母
+	DefaultAvatarStyleURL       = "url"       // 所有用户使用同一个地址
+)
+
+// DefaultAvatarOptions 定义新用户默认头像的选项
+type DefaultAvatarOptions struct {
+	Style   string // identicon、initials 或 url
+	BaseURL string // ucenter 提供默认头像的访问地址前缀, 如 http://localhost:8080/api/avatar
+	URL     string // style 为 url 时使用的头像地址
+	Size    int    // 生成的头像边长
+}
+
+// seedPattern 默认头像地址中的种子, 即账号哈希的前 16 个字节
+var seedPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+//go:generate mockgen -source=./default_avatar.go -package=svcmocks -destination=./mocks/default_avatar.mock.go DefaultAvatarService
+type DefaultAvatarService interface {
+	// URL 返回新用户的默认头像地址, 同一个账号总是得到相同的地址.
+	// 地址中只包含账号的哈希和用户名的首字母, 不包含账号本身
+	URL(account, username string) string
+	// Render 渲染默认头像地址对应的图片, 返回图片内容和 MIME 类型
+	Render(style, seed, text string) ([]byte, string, error)
+}
+
+func NewDefaultAvatarService(opts DefaultAvatarOptions) DefaultAvatarService {
+	return &defaultAvatarService{opts: opts}
+}
+
+type defaultAvatarService struct {
+	opts DefaultAvatarOptions
+}
+
+func (d *defaultAvatarService) URL(account, username string) string {
+	base := strings.TrimRight(d.opts.BaseURL, "/")
+	// 使用账号的哈希, 避免在头像地址中暴露账号
+	sum := sha256.Sum256([]byte(account))
+	seed := hex.EncodeToString(sum[:16])
+	switch d.opts.Style {
+	case DefaultAvatarStyleIdenticon:
+		return base + "/identicon/" + seed + ".png"
+	case DefaultAvatarStyleInitials:
+		return base + "/initials/" + seed + ".svg?text=" + url.QueryEscape(identicon.InitialsOf(username))
+	default:
+		return d.opts.URL
+	}
+}
+
+func (d *defaultAvatarService) Render(style, seed, text string) ([]byte, string, error) {
+	if !seedPattern.MatchString(seed) {
+		return nil, "", errno.ErrPageNotFound
+	}
+	switch style {
+	case DefaultAvatarStyleIdenticon:
+		data, err := identicon.Render(seed, d.opts.Size)
+		if err != nil {
+			return nil, "", err
+		}
+		return data, "image/png", nil
+	case DefaultAvatarStyleInitials:
+		return identicon.Initials(text, seed, d.opts.Size), "image/svg+xml", nil
+	default:
+		return nil, "", errno.ErrPageNotFound
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func Test_defaultAvatarService_URL(t *testing.T) {
+	svc := NewDefaultAvatarService(DefaultAvatarOptions{
+		Style:   DefaultAvatarStyleInitials,
+		BaseURL: "http://localhost:8080/api/avatar/",
+		Size:    128,
+	})
+	testCases := []struct {
+		name string
+
+		account  string
+		username string
+
+		wantText string
+	}{
+		{
+			name:     "短信注册, 账号由手机号生成",
+			account:  "m8613800138000",
+			username: "用户8000",
+			wantText: "用",
+		},
+		{
+			name:     "用户名取前两个字母",
+			account:  "coderlewin",
+			username: "CODERLEWIN",
+			wantText: "CO",
+		},
+		{
+			name:     "用户名为空时不使用账号",
+			account:  "m8613800138000",
+			wantText: "?",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			avatarURL := svc.URL(tc.account, tc.username)
+			assert.True(t, strings.HasPrefix(avatarURL, "http://localhost:8080/api/avatar/initials/"), avatarURL)
+			assert.NotContains(t, avatarURL, tc.account)
+			u, err := url.Parse(avatarURL)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantText, u.Query().Get("text"))
+			// 同一个账号总是得到相同的地址
+			assert.Equal(t, avatarURL, svc.URL(tc.account, tc.username))
+		})
+	}
+}
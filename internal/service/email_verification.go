@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coderlewin/ucenter/internal/repository"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/keyring"
+	"github.com/coderlewin/ucenter/pkg/mail"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// emailVerificationAudience 验证链接中 token 的 aud, 防止与其他用途的 token 混用
+	emailVerificationAudience = "ucenter:email-verification"
+	// emailChangeAudience 修改邮箱确认链接中 token 的 aud
+	emailChangeAudience = "ucenter:email-change"
+	// emailVerificationTokenType 验证链接中 token 的 typ, 与登录态 token 区分
+	emailVerificationTokenType = "ev+jwt"
+)
+
+// EmailVerificationOptions 定义注册邮箱验证的选项
+type EmailVerificationOptions struct {
+	// Enabled 为 true 时注册必须填写邮箱, 验证邮箱后才能登录
+	Enabled bool
+	// URL 验证页面的地址, %s 会被替换为验证 token
+	URL string
+	// ChangeURL 修改邮箱确认页面的地址, %s 会被替换为确认 token
+	ChangeURL      string
+	Expiration     time.Duration // 验证链接的有效期
+	ResendInterval time.Duration // 同一用户两次发送验证邮件的最小间隔
+}
+
+type emailVerificationClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+//go:generate mockgen -source=./email_verification.go -package=svcmocks -destination=./mocks/email_verification.mock.go EmailVerificationService
+type EmailVerificationService interface {
+	// Enabled 注册时是否需要验证邮箱
+	Enabled() bool
+	// SendVerifyEmail 向待验证的用户发送验证邮件
+	SendVerifyEmail(ctx context.Context, uid int64) error
+	// Resend 重新发送验证邮件, 邮箱未注册或已验证时同样返回成功
+	Resend(ctx context.Context, email string) error
+	// Verify 校验验证链接中的 token 并激活用户
+	Verify(ctx context.Context, token string) error
+	// SendChangeEmail 向新邮箱发送修改邮箱的确认链接, 只有最近一次发送的链接有效
+	SendChangeEmail(ctx context.Context, uid int64, email string) error
+	// VerifyChange 校验修改邮箱的确认链接, 返回用户 ID 和新邮箱, 每个链接只能使用一次
+	VerifyChange(ctx context.Context, token string) (int64, string, error)
+}
+
+// NewEmailVerificationService 验证链接使用 JWT 密钥环签名, 不需要单独配置密钥
+func NewEmailVerificationService(opts EmailVerificationOptions, cmd redis.Cmdable, keys *keyring.KeyRing,
+	userRepo repository.UserRepository, mailer mail.Mailer) EmailVerificationService {
+	return &emailVerificationService{opts: opts, cmd: cmd, keys: keys, userRepo: userRepo, mailer: mailer}
+}
+
+type emailVerificationService struct {
+	opts     EmailVerificationOptions
+	cmd      redis.Cmdable
+	keys     *keyring.KeyRing
+	userRepo repository.UserRepository
+	mailer   mail.Mailer
+}
+
+func (e *emailVerificationService) Enabled() bool {
+	return e.opts.Enabled
+}
+
+func (e *emailVerificationService) SendVerifyEmail(ctx context.Context, uid int64) error {
+	user, err := e.userRepo.GetOneById(ctx, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errno.ErrEntityNull
+		}
+		return errno.ErrDBFailed
+	}
+	if !user.IsPending() || user.Email == "" {
+		return errno.ErrParameterInvalid.SetDescription("用户无需验证邮箱")
+	}
+
+	// 限制发送频率
+	ok, err := e.cmd.SetNX(ctx, e.throttleKey(uid), 1, e.opts.ResendInterval).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errno.ErrTooManyRequests.SetDescription("发送过于频繁, 请稍后重试")
+	}
+
+	now := time.Now()
+	token, err := e.keys.Sign(emailVerificationTokenType, emailVerificationClaims{
+		Email: user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(uid, 10),
+			Audience:  jwt.ClaimStrings{emailVerificationAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(e.opts.Expiration)),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = e.mailer.Send(ctx, mail.Message{
+		To:      []string{user.Email},
+		Subject: "验证邮箱",
+		Body: fmt.Sprintf("您好 %s:\n\n感谢注册, 请在 %d 小时内访问以下链接完成邮箱验证:\n%s\n\n如果这不是您本人的操作, 请忽略本邮件.\n",
+			user.Username, int(e.opts.Expiration.Hours()), fmt.Sprintf(e.opts.URL, token)),
+	})
+	if err != nil {
+		// 发送失败时允许立即重试
+		e.cmd.Del(ctx, e.throttleKey(uid))
+		return err
+	}
+	return nil
+}
+
+func (e *emailVerificationService) Resend(ctx context.Context, email string) error {
+	user, err := e.userRepo.FindByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, repository.ErrAmbiguousEmail) {
+			return nil
+		}
+		return errno.ErrDBFailed
+	}
+	if !user.IsPending() {
+		return nil
+	}
+	return e.SendVerifyEmail(ctx, user.ID)
+}
+
+func (e *emailVerificationService) Verify(ctx context.Context, token string) error {
+	var claims emailVerificationClaims
+	err := e.keys.Parse(token, emailVerificationTokenType, &claims, jwt.WithAudience(emailVerificationAudience))
+	if err != nil {
+		return errno.ErrParameterInvalid.SetDescription("验证链接无效或已过期")
+	}
+	uid, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return errno.ErrParameterInvalid.SetDescription("验证链接无效或已过期")
+	}
+
+	ok, err := e.userRepo.VerifyEmail(ctx, uid, claims.Email)
+	if err != nil {
+		return errno.ErrDBFailed
+	}
+	if ok {
+		return nil
+	}
+
+	// 重复访问验证链接时视为成功
+	user, err := e.userRepo.GetOneById(ctx, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errno.ErrParameterInvalid.SetDescription("验证链接无效或已过期")
+		}
+		return errno.ErrDBFailed
+	}
+	if !user.IsPending() && user.Email == claims.Email {
+		return nil
+	}
+	return errno.ErrParameterInvalid.SetDescription("验证链接无效或已过期")
+}
+
+func (e *emailVerificationService) SendChangeEmail(ctx context.Context, uid int64, email string) error {
+	ok, err := e.cmd.SetNX(ctx, e.changeThrottleKey(uid), 1, e.opts.ResendInterval).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errno.ErrTooManyRequests.SetDescription("发送过于频繁, 请稍后重试")
+	}
+
+	// 记录最近一次发送的链接, 之前发送的链接随之失效
+	jti, err := randomToken()
+	if err != nil {
+		return err
+	}
+	if err = e.cmd.Set(ctx, e.changeKey(uid), jti, e.opts.Expiration).Err(); err != nil {
+		return err
+	}
+	now := time.Now()
+	token, err := e.keys.Sign(emailVerificationTokenType, emailVerificationClaims{
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   strconv.FormatInt(uid, 10),
+			Audience:  jwt.ClaimStrings{emailChangeAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(e.opts.Expiration)),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = e.mailer.Send(ctx, mail.Message{
+		To:      []string{email},
+		Subject: "确认修改邮箱",
+		Body: fmt.Sprintf("您好:\n\n您正在将账号的邮箱修改为 %s, 请在 %d 小时内访问以下链接确认:\n%s\n\n如果这不是您本人的操作, 请忽略本邮件.\n",
+			email, int(e.opts.Expiration.Hours()), fmt.Sprintf(e.opts.ChangeURL, token)),
+	})
+	if err != nil {
+		// 发送失败时允许立即重试
+		e.cmd.Del(ctx, e.changeThrottleKey(uid))
+		return err
+	}
+	return nil
+}
+
+func (e *emailVerificationService) VerifyChange(ctx context.Context, token string) (int64, string, error) {
+	var claims emailVerificationClaims
+	err := e.keys.Parse(token, emailVerificationTokenType, &claims, jwt.WithAudience(emailChangeAudience))
+	if err != nil {
+		return 0, "", errno.ErrParameterInvalid.SetDescription("确认链接无效或已过期")
+	}
+	uid, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil || claims.ID == "" {
+		return 0, "", errno.ErrParameterInvalid.SetDescription("确认链接无效或已过期")
+	}
+
+	// 只有最近一次发送的链接有效, 使用后删除
+	jti, err := e.cmd.Get(ctx, e.changeKey(uid)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, "", err
+	}
+	if jti != claims.ID {
+		return 0, "", errno.ErrParameterInvalid.SetDescription("确认链接无效或已过期")
+	}
+	if err = e.cmd.Del(ctx, e.changeKey(uid)).Err(); err != nil {
+		return 0, "", err
+	}
+	return uid, claims.Email, nil
+}
+
+func (e *emailVerificationService) throttleKey(uid int64) string {
+	return fmt.Sprintf("ucenter:email_verification:throttle:%d", uid)
+}
+
+func (e *emailVerificationService) changeKey(uid int64) string {
+	return fmt.Sprintf("ucenter:email_change:%d", uid)
+}
+
+func (e *emailVerificationService) changeThrottleKey(uid int64) string {
+	return fmt.Sprintf("ucenter:email_change:throttle:%d", uid)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/domain"
+	repomocks "github.com/coderlewin/ucenter/internal/repository/mocks"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/mail"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func Test_emailVerificationService_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userRepo := repomocks.NewMockUserRepository(ctrl)
+	mailer := mail.NewMemoryMailer()
+	keys := newTestKeyRing(t)
+	svc := NewEmailVerificationService(EmailVerificationOptions{
+		Enabled:        true,
+		URL:            "https://uc.example.com/verify?token=%s",
+		Expiration:     time.Hour,
+		ResendInterval: time.Minute,
+	}, redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}), keys, userRepo, mailer)
+	ctx := context.Background()
+
+	userRepo.EXPECT().GetOneById(gomock.Any(), int64(1)).Return(domain.User{
+		ID:         1,
+		Email:      "lewin@example.com",
+		UserStatus: constants.UserStatusPending,
+	}, nil)
+	require.NoError(t, svc.SendVerifyEmail(ctx, 1))
+	token := lastMailToken(t, mailer)
+
+	// 其他用途的 token 即使由同一密钥签名也不能用于验证邮箱
+	other, err := keys.Sign(accessTokenType, emailVerificationClaims{
+		Email:            "lewin@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "1", Audience: jwt.ClaimStrings{emailVerificationAudience}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, errno.ErrParameterInvalid, svc.Verify(ctx, other))
+
+	userRepo.EXPECT().VerifyEmail(gomock.Any(), int64(1), "lewin@example.com").Return(true, nil)
+	assert.NoError(t, svc.Verify(ctx, token))
+}
+
+func Test_emailVerificationService_Change(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mailer := mail.NewMemoryMailer()
+	mr := miniredis.RunT(t)
+	svc := NewEmailVerificationService(EmailVerificationOptions{
+		ChangeURL:      "https://uc.example.com/confirm?token=%s",
+		Expiration:     time.Hour,
+		ResendInterval: time.Minute,
+	}, redis.NewClient(&redis.Options{Addr: mr.Addr()}), newTestKeyRing(t), repomocks.NewMockUserRepository(ctrl), mailer)
+	ctx := context.Background()
+
+	require.NoError(t, svc.SendChangeEmail(ctx, 1, "new@example.com"))
+	require.Len(t, mailer.Messages(), 1)
+	assert.Equal(t, []string{"new@example.com"}, mailer.Messages()[0].To)
+	first := lastMailToken(t, mailer)
+
+	// 发送间隔内不能重复发送
+	assert.Equal(t, errno.ErrTooManyRequests, svc.SendChangeEmail(ctx, 1, "other@example.com"))
+
+	// 重新发送后之前的链接失效
+	mr.FastForward(time.Minute)
+	require.NoError(t, svc.SendChangeEmail(ctx, 1, "other@example.com"))
+	second := lastMailToken(t, mailer)
+	_, _, err := svc.VerifyChange(ctx, first)
+	assert.Equal(t, errno.ErrParameterInvalid, err)
+
+	uid, email, err := svc.VerifyChange(ctx, second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), uid)
+	assert.Equal(t, "other@example.com", email)
+
+	// 链接只能使用一次
+	_, _, err = svc.VerifyChange(ctx, second)
+	assert.Equal(t, errno.ErrParameterInvalid, err)
+	assert.Equal(t, "确认链接无效或已过期", errno.ErrParameterInvalid.Desc)
+}
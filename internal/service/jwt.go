@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	_ "embed"
 	"fmt"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/domain"
 	"github.com/coderlewin/ucenter/internal/web/dto"
 	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/keyring"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -14,17 +17,36 @@ import (
 	"time"
 )
 
+//go:embed lua/rotate_refresh_token.lua
+var luaRotateRefreshToken string
+
+// token 类型, 写入 JWT 头部的 typ, 防止 refresh token 被当作 access token 使用
+const (
+	accessTokenType  = "at+jwt"
+	refreshTokenType = "rt+jwt"
+	idTokenType      = "JWT"
+)
+
+//go:generate mockgen -source=./jwt.go -package=svcmocks -destination=./mocks/jwt.mock.go JWTService
 type JWTService interface {
-	SetJWTToken(ctx *app.RequestContext, ssid string, uid int64) error
+	SetJWTToken(ctx *app.RequestContext, ssid string, uid int64, role int32, version int32) error
 	CheckSession(ctx context.Context, ssid string) error
-	SetLoginToken(ctx *app.RequestContext, uid int64) error
+	SetLoginToken(c context.Context, ctx *app.RequestContext, uid int64, role int32, version int32) error
 	ExtractTokenString(ctx *app.RequestContext) string
+	ParseAccessToken(tokenString string) (dto.UserClaims, error)
+	RefreshToken(c context.Context, ctx *app.RequestContext, refreshToken string) error
 	ClearToken(c context.Context, ctx *app.RequestContext) error
+	// SignIDToken 签发 OIDC 的 ID Token
+	SignIDToken(claims dto.IDTokenClaims) (string, error)
+	// JWKS 返回用于校验 token 签名的公钥集合
+	JWKS() keyring.JWKSet
 }
 
-func NewRedisJWTService(cmd redis.Cmdable) JWTService {
+func NewRedisJWTService(cmd redis.Cmdable, keys *keyring.KeyRing, sessionSvc SessionService) JWTService {
 	return &redisJWTService{
 		cmd:                    cmd,
+		keys:                   keys,
+		sessionSvc:             sessionSvc,
 		refreshTokenExpiration: time.Hour * 24 * 7,
 		accessTokenExpiration:  time.Minute * 30,
 	}
@@ -32,25 +54,31 @@ func NewRedisJWTService(cmd redis.Cmdable) JWTService {
 
 type redisJWTService struct {
 	cmd redis.Cmdable
+	// 签名密钥
+	keys *keyring.KeyRing
+	// 登录会话的记录和吊销
+	sessionSvc SessionService
 	// refresh token 的过期时间
 	refreshTokenExpiration time.Duration
 	// token 过期时间
 	accessTokenExpiration time.Duration
 }
 
-func (r *redisJWTService) SetJWTToken(ctx *app.RequestContext, ssid string, uid int64) error {
+func (r *redisJWTService) SetJWTToken(ctx *app.RequestContext, ssid string, uid int64, role int32, version int32) error {
 	// 在 token 中设置一些参数字段如 用户id等
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, dto.UserClaims{
-		Id:        uid,
-		UserAgent: string(ctx.GetHeader("User-Agent")),
-		Ssid:      ssid,
+	claims := dto.UserClaims{
+		Id:              uid,
+		Role:            role,
+		SecurityVersion: version,
+		UserAgent:       string(ctx.GetHeader("User-Agent")),
+		Ssid:            ssid,
 		RegisteredClaims: jwt.RegisteredClaims{
 			// 设置 token 过期时间
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(r.accessTokenExpiration)),
 		},
-	})
+	}
 	// 根据密钥生成 token 字符串
-	tokenString, err := token.SignedString(constants.AccessTokenKey)
+	tokenString, err := r.sign(accessTokenType, claims)
 	if err != nil {
 		return err
 	}
@@ -60,38 +88,86 @@ func (r *redisJWTService) SetJWTToken(ctx *app.RequestContext, ssid string, uid
 }
 
 func (r *redisJWTService) CheckSession(ctx context.Context, ssid string) error {
-	logout, err := r.cmd.Exists(ctx,
-		r.key(ssid)).Result()
+	return r.sessionSvc.Check(ctx, ssid)
+}
+
+func (r *redisJWTService) SetLoginToken(c context.Context, ctx *app.RequestContext, uid int64, role int32, version int32) error {
+	ssid, err := r.sessionSvc.Create(c, domain.LoginSession{
+		Type:      domain.LoginSessionJWT,
+		UserID:    uid,
+		UserAgent: string(ctx.GetHeader("User-Agent")),
+		IP:        ctx.ClientIP(),
+	}, r.refreshTokenExpiration)
 	if err != nil {
 		return err
 	}
-	if logout > 0 {
-		return errno.ErrUnauthorization.SetDescription("用户已经退出登录")
+	err = r.SetJWTToken(ctx, ssid, uid, role, version)
+	if err != nil {
+		return err
 	}
-	return nil
+	// 记录该登录会话当前有效的 refresh token
+	jti := uuid.New().String()
+	err = r.cmd.Set(c, r.refreshKey(ssid), jti, r.refreshTokenExpiration).Err()
+	if err != nil {
+		return err
+	}
+	err = r.setRefreshToken(ctx, ssid, jti, uid, role, version)
+	return err
 }
 
-func (r *redisJWTService) SetLoginToken(ctx *app.RequestContext, uid int64) error {
-	ssid := uuid.New().String()
-	err := r.SetJWTToken(ctx, ssid, uid)
+func (r *redisJWTService) RefreshToken(c context.Context, ctx *app.RequestContext, refreshToken string) error {
+	var rc dto.RefreshClaims
+	if err := r.parse(refreshToken, refreshTokenType, &rc); err != nil {
+		return errno.ErrUnauthorization.SetDescription("refresh token 无效")
+	}
+
+	// 已经退出登录的会话不允许刷新
+	if err := r.CheckSession(c, rc.Ssid); err != nil {
+		return err
+	}
+
+	// 轮换 refresh token, 旧的 refresh token 随即失效
+	jti := uuid.New().String()
+	res, err := r.cmd.Eval(c, luaRotateRefreshToken, []string{r.refreshKey(rc.Ssid)},
+		rc.ID, jti, r.refreshTokenExpiration.Milliseconds()).Int()
 	if err != nil {
 		return err
 	}
-	err = r.setRefreshToken(ctx, ssid, uid)
-	return err
+	switch res {
+	case -1:
+		return errno.ErrUnauthorization.SetDescription("登录已过期")
+	case 0:
+		// 旧的 refresh token 被重放, 说明可能已经泄露, 吊销整个登录会话
+		if err = r.revoke(c, rc.Ssid); err != nil {
+			return err
+		}
+		return errno.ErrUnauthorization.SetDescription("refresh token 已被使用, 请重新登录")
+	}
+
+	// 刷新后登录会话随 refresh token 一起续期
+	if err = r.sessionSvc.Extend(c, rc.Ssid, r.refreshTokenExpiration); err != nil {
+		return err
+	}
+	err = r.SetJWTToken(ctx, rc.Ssid, rc.Id, rc.Role, rc.SecurityVersion)
+	if err != nil {
+		return err
+	}
+	return r.setRefreshToken(ctx, rc.Ssid, jti, rc.Id, rc.Role, rc.SecurityVersion)
 }
 
-func (r *redisJWTService) setRefreshToken(ctx *app.RequestContext, ssid string, uid int64) error {
+func (r *redisJWTService) setRefreshToken(ctx *app.RequestContext, ssid string, jti string, uid int64, role int32, version int32) error {
 	rc := dto.RefreshClaims{
-		Id:   uid,
-		Ssid: ssid,
+		Id:              uid,
+		Role:            role,
+		SecurityVersion: version,
+		Ssid:            ssid,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID: jti,
 			// 设置为七天过期
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(r.refreshTokenExpiration)),
 		},
 	}
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, rc)
-	refreshTokenStr, err := refreshToken.SignedString(constants.RefreshTokenKey)
+	refreshTokenStr, err := r.sign(refreshTokenType, rc)
 	if err != nil {
 		return err
 	}
@@ -113,14 +189,48 @@ func (r *redisJWTService) ExtractTokenString(ctx *app.RequestContext) string {
 	return authSegments[1]
 }
 
+func (r *redisJWTService) ParseAccessToken(tokenString string) (dto.UserClaims, error) {
+	var uc dto.UserClaims
+	if err := r.parse(tokenString, accessTokenType, &uc); err != nil {
+		return dto.UserClaims{}, errno.ErrUnauthorization.SetDescription("token 无效")
+	}
+	return uc, nil
+}
+
+func (r *redisJWTService) SignIDToken(claims dto.IDTokenClaims) (string, error) {
+	return r.sign(idTokenType, claims)
+}
+
+func (r *redisJWTService) JWKS() keyring.JWKSet {
+	return r.keys.JWKS()
+}
+
+// sign 使用当前的签名密钥签发 token
+func (r *redisJWTService) sign(typ string, claims jwt.Claims) (string, error) {
+	return r.keys.Sign(typ, claims)
+}
+
+// parse 校验 token 的签名和类型
+func (r *redisJWTService) parse(tokenString string, typ string, claims jwt.Claims) error {
+	return r.keys.Parse(tokenString, typ, claims)
+}
+
 func (r *redisJWTService) ClearToken(c context.Context, ctx *app.RequestContext) error {
 	// 设置给请求头字段为空字符串，前端会拿到并保存, 下次请求token就是个空字符串了
 	ctx.Header("x-jwt-token", "")
 	ctx.Header("x-refresh-token", "")
 	uc := ctx.MustGet(constants.UserLoginState).(dto.UserClaims)
-	return r.cmd.Set(c, r.key(uc.Ssid), "", r.refreshTokenExpiration).Err()
+	return r.revoke(c, uc.Ssid)
+}
+
+// revoke 吊销登录会话, 该会话下的 access token 和 refresh token 全部失效
+func (r *redisJWTService) revoke(c context.Context, ssid string) error {
+	if err := r.sessionSvc.Revoke(c, ssid); err != nil {
+		return err
+	}
+	return r.cmd.Del(c, r.refreshKey(ssid)).Err()
 }
 
-func (r *redisJWTService) key(ssid string) string {
-	return fmt.Sprintf("ucenter:users:ssid:%s", ssid)
+func (r *redisJWTService) refreshKey(ssid string) string {
+	return fmt.Sprintf("ucenter:users:refresh:%s", ssid)
 }
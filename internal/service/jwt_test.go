@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/cloudwego/hertz/pkg/app"
+	svcmocks "github.com/coderlewin/ucenter/internal/service/mocks"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/keyring"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestKeyRing(t *testing.T) *keyring.KeyRing {
+	key, err := keyring.GenerateKey("k1", keyring.AlgEdDSA)
+	require.NoError(t, err)
+	keys, err := keyring.New(key)
+	require.NoError(t, err)
+	return keys
+}
+
+// newTestJWTService 返回使用 miniredis 的 JWTService, 会话服务使用 mock
+func newTestJWTService(t *testing.T, ctrl *gomock.Controller) (*redisJWTService, *svcmocks.MockSessionService, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	sessionSvc := svcmocks.NewMockSessionService(ctrl)
+	svc := NewRedisJWTService(redis.NewClient(&redis.Options{Addr: mr.Addr()}), newTestKeyRing(t), sessionSvc)
+	return svc.(*redisJWTService), sessionSvc, mr
+}
+
+// login 登录并返回签发的 refresh token
+func login(t *testing.T, svc JWTService, sessionSvc *svcmocks.MockSessionService) string {
+	sessionSvc.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Return("ssid-1", nil)
+	c := app.NewContext(0)
+	require.NoError(t, svc.SetLoginToken(context.Background(), c, 1, 0, 0))
+	refreshToken := c.Response.Header.Get("x-refresh-token")
+	require.NotEmpty(t, refreshToken)
+	return refreshToken
+}
+
+func Test_redisJWTService_RefreshToken_Rotation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, sessionSvc, _ := newTestJWTService(t, ctrl)
+	first := login(t, svc, sessionSvc)
+
+	sessionSvc.EXPECT().Check(gomock.Any(), "ssid-1").Return(nil).Times(2)
+	sessionSvc.EXPECT().Extend(gomock.Any(), "ssid-1", svc.refreshTokenExpiration).Return(nil).Times(2)
+
+	c := app.NewContext(0)
+	require.NoError(t, svc.RefreshToken(context.Background(), c, first))
+	second := c.Response.Header.Get("x-refresh-token")
+	assert.NotEmpty(t, second)
+	assert.NotEqual(t, first, second)
+	uc, err := svc.ParseAccessToken(c.Response.Header.Get("x-jwt-token"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), uc.Id)
+	assert.Equal(t, "ssid-1", uc.Ssid)
+
+	// 轮换后的 refresh token 可以继续刷新
+	c = app.NewContext(0)
+	require.NoError(t, svc.RefreshToken(context.Background(), c, second))
+	assert.NotEmpty(t, c.Response.Header.Get("x-refresh-token"))
+}
+
+func Test_redisJWTService_RefreshToken_Reuse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, sessionSvc, mr := newTestJWTService(t, ctrl)
+	first := login(t, svc, sessionSvc)
+
+	sessionSvc.EXPECT().Check(gomock.Any(), "ssid-1").Return(nil).Times(2)
+	sessionSvc.EXPECT().Extend(gomock.Any(), "ssid-1", gomock.Any()).Return(nil)
+	c := app.NewContext(0)
+	require.NoError(t, svc.RefreshToken(context.Background(), c, first))
+	second := c.Response.Header.Get("x-refresh-token")
+
+	// 重放已经使用过的 refresh token, 整个登录会话被吊销
+	sessionSvc.EXPECT().Revoke(gomock.Any(), "ssid-1").Return(nil)
+	err := svc.RefreshToken(context.Background(), app.NewContext(0), first)
+	assert.Equal(t, errno.ErrUnauthorization, err)
+	assert.False(t, mr.Exists(svc.refreshKey("ssid-1")))
+
+	// 会话被吊销后, 轮换得到的 refresh token 也不能再使用
+	sessionSvc.EXPECT().Check(gomock.Any(), "ssid-1").Return(errno.ErrUnauthorization)
+	err = svc.RefreshToken(context.Background(), app.NewContext(0), second)
+	assert.Equal(t, errno.ErrUnauthorization, err)
+}
+
+func Test_redisJWTService_RefreshToken_Expired(t *testing.T) {
+	testCases := []struct {
+		name string
+		// 登录之后, 刷新之前执行
+		before func(svc *redisJWTService, sessionSvc *svcmocks.MockSessionService, mr *miniredis.Miniredis)
+		// 提交的 token, 为 nil 时使用登录时签发的 refresh token
+		token func(svc *redisJWTService) string
+	}{
+		{
+			name: "refresh token 已过期",
+			before: func(svc *redisJWTService, sessionSvc *svcmocks.MockSessionService, mr *miniredis.Miniredis) {
+			},
+			token: func(svc *redisJWTService) string {
+				c := app.NewContext(0)
+				svc.refreshTokenExpiration = -time.Minute
+				_ = svc.setRefreshToken(c, "ssid-1", "jti", 1, 0, 0)
+				return c.Response.Header.Get("x-refresh-token")
+			},
+		},
+		{
+			name: "登录会话中的 refresh token 记录已过期",
+			before: func(svc *redisJWTService, sessionSvc *svcmocks.MockSessionService, mr *miniredis.Miniredis) {
+				mr.FastForward(svc.refreshTokenExpiration + time.Second)
+				sessionSvc.EXPECT().Check(gomock.Any(), "ssid-1").Return(nil)
+			},
+		},
+		{
+			name: "使用 access token 刷新",
+			before: func(svc *redisJWTService, sessionSvc *svcmocks.MockSessionService, mr *miniredis.Miniredis) {
+			},
+			token: func(svc *redisJWTService) string {
+				c := app.NewContext(0)
+				_ = svc.SetJWTToken(c, "ssid-1", 1, 0, 0)
+				return c.Response.Header.Get("x-jwt-token")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, sessionSvc, mr := newTestJWTService(t, ctrl)
+			token := login(t, svc, sessionSvc)
+			tc.before(svc, sessionSvc, mr)
+			if tc.token != nil {
+				token = tc.token(svc)
+			}
+			err := svc.RefreshToken(context.Background(), app.NewContext(0), token)
+			assert.Equal(t, errno.ErrUnauthorization, err)
+		})
+	}
+}
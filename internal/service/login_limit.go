@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/redis/go-redis/v9"
+	"math"
+	"strings"
+	"time"
+)
+
+//go:embed lua/login_failure.lua
+var luaLoginFailure string
+
+// LoginLimitOptions 定义登录失败限制的选项
+type LoginLimitOptions struct {
+	AccountMaxFailures int64         // 同一账号连续失败多少次后锁定
+	IPMaxFailures      int64         // 同一 IP 连续失败多少次后锁定
+	BaseLockDuration   time.Duration // 首次锁定时长, 之后每次失败翻倍
+	MaxLockDuration    time.Duration // 最长锁定时长
+	FailureWindow      time.Duration // 失败次数的统计窗口
+}
+
+//go:generate mockgen -source=./login_limit.go -package=svcmocks -destination=./mocks/login_limit.mock.go LoginLimitService
+type LoginLimitService interface {
+	// Check 检查账号和 IP 是否处于锁定状态
+	Check(ctx context.Context, account string, ip string) error
+	// Fail 记录一次登录失败, 达到阈值后锁定
+	Fail(ctx context.Context, account string, ip string) error
+	// Succeed 登录成功后清除账号的失败次数
+	Succeed(ctx context.Context, account string) error
+	// GetLocks 查询账号和 IP 的锁定状态, 参数为空时忽略对应维度
+	GetLocks(ctx context.Context, account string, ip string) ([]domain.LoginLock, error)
+	// Unlock 清除账号和 IP 的失败次数和锁定状态, 参数为空时忽略对应维度
+	Unlock(ctx context.Context, account string, ip string) error
+}
+
+func NewLoginLimitService(opts LoginLimitOptions, cmd redis.Cmdable) LoginLimitService {
+	return &loginLimitService{opts: opts, cmd: cmd}
+}
+
+type loginLimitService struct {
+	opts LoginLimitOptions
+	cmd  redis.Cmdable
+}
+
+func (l *loginLimitService) Check(ctx context.Context, account string, ip string) error {
+	var retryAfter time.Duration
+	for _, target := range l.targets(account, ip) {
+		ttl, err := l.cmd.PTTL(ctx, l.lockKey(target.Type, target.Target)).Result()
+		if err != nil {
+			return err
+		}
+		if ttl > retryAfter {
+			retryAfter = ttl
+		}
+	}
+	if retryAfter > 0 {
+		return l.lockedErr(retryAfter)
+	}
+	return nil
+}
+
+func (l *loginLimitService) Fail(ctx context.Context, account string, ip string) error {
+	var retryAfter time.Duration
+	for _, target := range l.targets(account, ip) {
+		threshold := l.opts.AccountMaxFailures
+		if target.Type == domain.LoginLockByIP {
+			threshold = l.opts.IPMaxFailures
+		}
+		ttl, err := l.cmd.Eval(ctx, luaLoginFailure,
+			[]string{l.failuresKey(target.Type, target.Target), l.lockKey(target.Type, target.Target)},
+			threshold, l.opts.BaseLockDuration.Milliseconds(), l.opts.MaxLockDuration.Milliseconds(),
+			l.opts.FailureWindow.Milliseconds()).Int64()
+		if err != nil {
+			return err
+		}
+		if d := time.Duration(ttl) * time.Millisecond; d > retryAfter {
+			retryAfter = d
+		}
+	}
+	if retryAfter > 0 {
+		return l.lockedErr(retryAfter)
+	}
+	return nil
+}
+
+func (l *loginLimitService) Succeed(ctx context.Context, account string) error {
+	// IP 维度不清除, 否则攻击者可以穿插一次正常登录来重置计数
+	return l.cmd.Del(ctx, l.failuresKey(domain.LoginLockByAccount, normalizeLoginAccount(account))).Err()
+}
+
+func (l *loginLimitService) GetLocks(ctx context.Context, account string, ip string) ([]domain.LoginLock, error) {
+	targets := l.targets(account, ip)
+	for i := range targets {
+		failures, err := l.cmd.Get(ctx, l.failuresKey(targets[i].Type, targets[i].Target)).Int64()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+		ttl, err := l.cmd.PTTL(ctx, l.lockKey(targets[i].Type, targets[i].Target)).Result()
+		if err != nil {
+			return nil, err
+		}
+		targets[i].Failures = failures
+		// key 不存在时 PTTL 返回负数
+		if ttl > 0 {
+			targets[i].RetryAfter = ttl
+		}
+	}
+	return targets, nil
+}
+
+func (l *loginLimitService) Unlock(ctx context.Context, account string, ip string) error {
+	targets := l.targets(account, ip)
+	if len(targets) == 0 {
+		return errno.ErrParameterInvalid.SetDescription("账号和 IP 不能同时为空")
+	}
+	keys := make([]string, 0, len(targets)*2)
+	for _, target := range targets {
+		keys = append(keys, l.failuresKey(target.Type, target.Target), l.lockKey(target.Type, target.Target))
+	}
+	return l.cmd.Del(ctx, keys...).Err()
+}
+
+func (l *loginLimitService) targets(account string, ip string) []domain.LoginLock {
+	targets := make([]domain.LoginLock, 0, 2)
+	if account = normalizeLoginAccount(account); account != "" {
+		targets = append(targets, domain.LoginLock{Type: domain.LoginLockByAccount, Target: account})
+	}
+	if ip != "" {
+		targets = append(targets, domain.LoginLock{Type: domain.LoginLockByIP, Target: ip})
+	}
+	return targets
+}
+
+// normalizeLoginAccount 账号比较不区分大小写, 与数据库的排序规则一致, 避免变换大小写绕过账号锁定
+func normalizeLoginAccount(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
+
+func (l *loginLimitService) lockedErr(retryAfter time.Duration) error {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	return errno.ErrTooManyAttempts.SetDescription("登录失败次数过多, 请在 %d 秒后重试", seconds)
+}
+
+func (l *loginLimitService) failuresKey(typ string, target string) string {
+	return fmt.Sprintf("ucenter:login:failures:%s:%s", typ, target)
+}
+
+func (l *loginLimitService) lockKey(typ string, target string) string {
+	return fmt.Sprintf("ucenter:login:lock:%s:%s", typ, target)
+}
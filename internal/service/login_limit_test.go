@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestLoginLimitService(t *testing.T) (LoginLimitService, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	return NewLoginLimitService(LoginLimitOptions{
+		AccountMaxFailures: 3,
+		IPMaxFailures:      5,
+		BaseLockDuration:   time.Minute,
+		MaxLockDuration:    3 * time.Minute,
+		FailureWindow:      15 * time.Minute,
+	}, redis.NewClient(&redis.Options{Addr: mr.Addr()})), mr
+}
+
+func Test_loginLimitService_LockAccount(t *testing.T) {
+	svc, mr := newTestLoginLimitService(t)
+	ctx := context.Background()
+
+	// 未达到阈值前不锁定
+	for i := 0; i < 2; i++ {
+		require.NoError(t, svc.Fail(ctx, "lewin", "10.0.0.1"))
+	}
+	require.NoError(t, svc.Check(ctx, "lewin", "10.0.0.1"))
+
+	// 达到阈值后锁定账号, 换 IP 也不能登录
+	assert.Equal(t, errno.ErrTooManyAttempts, svc.Fail(ctx, "lewin", "10.0.0.1"))
+	assert.Equal(t, errno.ErrTooManyAttempts, svc.Check(ctx, "lewin", "10.0.0.2"))
+	// 其他账号不受影响
+	assert.NoError(t, svc.Check(ctx, "other", "10.0.0.2"))
+
+	// 继续失败锁定时长翻倍, 不超过最长锁定时长
+	_ = svc.Fail(ctx, "lewin", "10.0.0.2")
+	locks, err := svc.GetLocks(ctx, "lewin", "")
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.Equal(t, int64(4), locks[0].Failures)
+	assert.Equal(t, 2*time.Minute, locks[0].RetryAfter)
+	_ = svc.Fail(ctx, "lewin", "10.0.0.2")
+	locks, err = svc.GetLocks(ctx, "lewin", "")
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Minute, locks[0].RetryAfter)
+
+	// 锁定到期后可以重新登录
+	mr.FastForward(3*time.Minute + time.Second)
+	assert.NoError(t, svc.Check(ctx, "lewin", "10.0.0.3"))
+}
+
+func Test_loginLimitService_LockIP(t *testing.T) {
+	svc, _ := newTestLoginLimitService(t)
+	ctx := context.Background()
+
+	// 同一 IP 尝试不同账号, 达到 IP 阈值后锁定
+	for i, account := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, svc.Fail(ctx, account, "10.0.0.1"), i)
+	}
+	assert.Equal(t, errno.ErrTooManyAttempts, svc.Fail(ctx, "e", "10.0.0.1"))
+	assert.Equal(t, errno.ErrTooManyAttempts, svc.Check(ctx, "f", "10.0.0.1"))
+
+	// 登录成功只清除账号的失败次数, IP 仍然锁定
+	require.NoError(t, svc.Succeed(ctx, "a"))
+	assert.Equal(t, errno.ErrTooManyAttempts, svc.Check(ctx, "a", "10.0.0.1"))
+
+	// 管理员解锁后恢复
+	require.NoError(t, svc.Unlock(ctx, "", "10.0.0.1"))
+	assert.NoError(t, svc.Check(ctx, "f", "10.0.0.1"))
+	locks, err := svc.GetLocks(ctx, "", "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.LoginLock{{Type: domain.LoginLockByIP, Target: "10.0.0.1"}}, locks)
+}
+
+func Test_loginLimitService_SucceedResetsAccount(t *testing.T) {
+	svc, _ := newTestLoginLimitService(t)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, svc.Fail(ctx, "lewin", "10.0.0.1"))
+	}
+	require.NoError(t, svc.Succeed(ctx, "lewin"))
+	// 清除后重新计数, 再失败两次不会锁定
+	for i := 0; i < 2; i++ {
+		require.NoError(t, svc.Fail(ctx, "lewin", "10.0.0.1"))
+	}
+	assert.NoError(t, svc.Check(ctx, "lewin", "10.0.0.1"))
+
+	assert.Equal(t, errno.ErrParameterInvalid, svc.Unlock(ctx, "", ""))
+}
+
+func Test_loginLimitService_AccountIgnoresCase(t *testing.T) {
+	svc, _ := newTestLoginLimitService(t)
+	ctx := context.Background()
+
+	// 大小写和首尾空格不同的账号按同一个账号计数
+	require.NoError(t, svc.Fail(ctx, "lewin", "10.0.0.1"))
+	require.NoError(t, svc.Fail(ctx, "Lewin", "10.0.0.2"))
+	assert.Equal(t, errno.ErrTooManyAttempts, svc.Fail(ctx, " LEWIN ", "10.0.0.3"))
+	assert.Equal(t, errno.ErrTooManyAttempts, svc.Check(ctx, "lEwIn", "10.0.0.4"))
+
+	locks, err := svc.GetLocks(ctx, "LEWIN", "")
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.Equal(t, "lewin", locks[0].Target)
+	assert.Equal(t, int64(3), locks[0].Failures)
+
+	require.NoError(t, svc.Unlock(ctx, "Lewin", ""))
+	assert.NoError(t, svc.Check(ctx, "lewin", "10.0.0.4"))
+
+	// 登录成功时同样不区分大小写
+	require.NoError(t, svc.Fail(ctx, "lewin", "10.0.0.1"))
+	require.NoError(t, svc.Succeed(ctx, "LEWIN"))
+	locks, err = svc.GetLocks(ctx, "lewin", "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), locks[0].Failures)
+}
+
+func Test_captchaService_CheckLogin_AccountIgnoresCase(t *testing.T) {
+	loginLimitSvc, mr := newTestLoginLimitService(t)
+	svc := NewCaptchaService(CaptchaOptions{LoginPolicy: CaptchaPolicyAfterFailures, LoginFailures: 2},
+		redis.NewClient(&redis.Options{Addr: mr.Addr()}), loginLimitSvc)
+	ctx := context.Background()
+
+	require.NoError(t, loginLimitSvc.Fail(ctx, "lewin", "10.0.0.1"))
+	require.NoError(t, loginLimitSvc.Fail(ctx, "LEWIN", "10.0.0.2"))
+	// 换一种大小写和 IP 登录仍然需要验证码
+	assert.Equal(t, errno.ErrCaptchaInvalid, svc.CheckLogin(ctx, " Lewin", "10.0.0.3", "", ""))
+	assert.NoError(t, svc.CheckLogin(ctx, "other", "10.0.0.3", "", ""))
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/repository"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+//go:generate mockgen -source=./login_state.go -package=svcmocks -destination=./mocks/login_state.mock.go LoginStateService
+type LoginStateService interface {
+	// Check 重新加载用户当前的角色和状态,
+	// 用户已删除、被冻结或安全版本与登录态中记录的 version 不一致时返回错误
+	Check(ctx context.Context, uid int64, version int32) (domain.User, error)
+	// Invalidate 用户的角色、状态等变更后清除缓存, 使其立即生效
+	Invalidate(ctx context.Context, uid int64) error
+}
+
+func NewLoginStateService(userRepo repository.UserRepository, cmd redis.Cmdable) LoginStateService {
+	return &loginStateService{
+		userRepo:   userRepo,
+		cmd:        cmd,
+		expiration: time.Minute * 5,
+	}
+}
+
+type loginStateService struct {
+	userRepo repository.UserRepository
+	cmd      redis.Cmdable
+	// 缓存的过期时间, 遗漏清除缓存时最多延迟这么久生效
+	expiration time.Duration
+}
+
+func (l *loginStateService) Check(ctx context.Context, uid int64, version int32) (domain.User, error) {
+	user, err := l.load(ctx, uid)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if user.IsFreeze() {
+		return domain.User{}, errno.ErrForbidden.SetDescription("账号已被冻结")
+	}
+	if user.SecurityVersion != version {
+		return domain.User{}, errno.ErrUnauthorization.SetDescription("登录已失效, 请重新登录")
+	}
+	return user, nil
+}
+
+func (l *loginStateService) Invalidate(ctx context.Context, uid int64) error {
+	return l.cmd.Del(ctx, l.key(uid)).Err()
+}
+
+// load 优先从缓存读取, 缓存中只保存校验登录态需要的字段
+func (l *loginStateService) load(ctx context.Context, uid int64) (domain.User, error) {
+	vals, err := l.cmd.HGetAll(ctx, l.key(uid)).Result()
+	if err == nil && len(vals) > 0 {
+		role, _ := strconv.ParseInt(vals["role"], 10, 32)
+		status, _ := strconv.ParseInt(vals["status"], 10, 32)
+		version, _ := strconv.ParseInt(vals["version"], 10, 32)
+		user := domain.User{
+			ID:              uid,
+			UserRole:        int32(role),
+			UserStatus:      int32(status),
+			SecurityVersion: int32(version),
+		}
+		if until, _ := strconv.ParseInt(vals["freeze_until"], 10, 64); until > 0 {
+			user.FreezeUntil = time.UnixMilli(until)
+		}
+		return user, nil
+	}
+
+	user, err := l.userRepo.GetOneById(ctx, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.User{}, errno.ErrUnauthorization.SetDescription("用户不存在")
+		}
+		return domain.User{}, errno.ErrDBFailed
+	}
+
+	// 缓存写入失败不影响本次请求
+	key := l.key(uid)
+	if err = l.cmd.HSet(ctx, key,
+		"role", user.UserRole,
+		"status", user.UserStatus,
+		"version", user.SecurityVersion,
+		"freeze_until", freezeUntilMilli(user),
+	).Err(); err == nil {
+		l.cmd.Expire(ctx, key, l.expiration)
+	}
+	return user, nil
+}
+
+func (l *loginStateService) key(uid int64) string {
+	return fmt.Sprintf("ucenter:users:login_state:%d", uid)
+}
+
+// freezeUntilMilli 冻结截止时间, 永久冻结时为 0
+func freezeUntilMilli(user domain.User) int64 {
+	if user.FreezeUntil.IsZero() {
+		return 0
+	}
+	return user.FreezeUntil.UnixMilli()
+}
@@ -0,0 +1,12 @@
+-- KEYS[1]: 会话信息
+-- ARGV[1]: 当前时间, 单位毫秒
+-- ARGV[2]: 更新最后活跃时间的最小间隔, 单位毫秒
+local last = redis.call('HGET', KEYS[1], 'last_seen')
+if last == false then
+    -- 会话已被吊销或已过期
+    return -1
+end
+if tonumber(ARGV[1]) - tonumber(last) >= tonumber(ARGV[2]) then
+    redis.call('HSET', KEYS[1], 'last_seen', ARGV[1])
+end
+return 0
@@ -0,0 +1,21 @@
+-- KEYS[1]: 失败次数计数器
+-- KEYS[2]: 锁定标记
+-- ARGV[1]: 触发锁定的失败次数
+-- ARGV[2]: 首次锁定时长, 单位毫秒
+-- ARGV[3]: 最长锁定时长, 单位毫秒
+-- ARGV[4]: 失败次数的统计窗口, 单位毫秒
+local count = redis.call('INCR', KEYS[1])
+local threshold = tonumber(ARGV[1])
+local ttl = 0
+if count >= threshold then
+    -- 达到阈值后每多失败一次, 锁定时长翻倍
+    ttl = tonumber(ARGV[2]) * math.pow(2, count - threshold)
+    if ttl > tonumber(ARGV[3]) then
+        ttl = tonumber(ARGV[3])
+    end
+    ttl = math.floor(ttl)
+    redis.call('SET', KEYS[2], count, 'PX', ttl)
+end
+-- 锁定期间计数器不能过期, 否则解锁后退避时长会被重置
+redis.call('PEXPIRE', KEYS[1], tonumber(ARGV[4]) + ttl)
+return ttl
@@ -0,0 +1,12 @@
+-- KEYS[1]: 验证码
+-- KEYS[2]: 24 小时内的发送次数
+-- ARGV[1]: 发送失败的验证码
+if redis.call('HGET', KEYS[1], 'code') == ARGV[1] then
+    redis.call('DEL', KEYS[1])
+end
+-- 发送失败不计入发送次数
+local count = tonumber(redis.call('GET', KEYS[2]) or '0')
+if count > 0 then
+    redis.call('DECR', KEYS[2])
+end
+return 0
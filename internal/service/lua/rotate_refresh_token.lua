@@ -0,0 +1,15 @@
+-- KEYS[1]: 登录会话(ssid)当前有效的 refresh token 标识
+-- ARGV[1]: 本次提交的 refresh token 标识
+-- ARGV[2]: 轮换后新的 refresh token 标识
+-- ARGV[3]: 过期时间, 单位毫秒
+local current = redis.call('GET', KEYS[1])
+if current == false then
+    -- 会话不存在或已过期
+    return -1
+end
+if current ~= ARGV[1] then
+    -- 已经使用过的 refresh token 被重放
+    return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
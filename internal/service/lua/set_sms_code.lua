@@ -0,0 +1,26 @@
+-- KEYS[1]: 验证码
+-- KEYS[2]: 24 小时内的发送次数
+-- ARGV[1]: 验证码
+-- ARGV[2]: 验证码有效期, 单位毫秒
+-- ARGV[3]: 两次发送的最小间隔, 单位毫秒
+-- ARGV[4]: 允许的校验次数
+-- ARGV[5]: 24 小时内允许的发送次数
+-- ARGV[6]: 发送次数计数器的过期时间, 单位毫秒
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl > tonumber(ARGV[2]) - tonumber(ARGV[3]) then
+    -- 发送太频繁
+    return -1
+end
+local count = tonumber(redis.call('GET', KEYS[2]) or '0')
+if count >= tonumber(ARGV[5]) then
+    -- 超过 24 小时内的发送次数
+    return -2
+end
+redis.call('INCR', KEYS[2])
+if count == 0 then
+    redis.call('PEXPIRE', KEYS[2], ARGV[6])
+end
+redis.call('DEL', KEYS[1])
+redis.call('HSET', KEYS[1], 'code', ARGV[1], 'attempts', ARGV[4])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 0
@@ -0,0 +1,15 @@
+-- KEYS[1]: 两步验证的挑战
+-- ARGV[1]: 最大尝试次数
+local uid = redis.call('HGET', KEYS[1], 'uid')
+if uid == false then
+    -- 挑战不存在或已过期
+    return -1
+end
+-- 挑战一定存在, 递增次数不会重新创建 key, 也不影响过期时间
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+if attempts > tonumber(ARGV[1]) then
+    -- 尝试次数用完, 作废挑战
+    redis.call('DEL', KEYS[1])
+    return -1
+end
+return tonumber(uid)
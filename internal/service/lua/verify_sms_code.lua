@@ -0,0 +1,19 @@
+-- KEYS[1]: 验证码
+-- ARGV[1]: 用户输入的验证码
+local code = redis.call('HGET', KEYS[1], 'code')
+if code == false then
+    -- 验证码不存在或已过期
+    return -1
+end
+local attempts = tonumber(redis.call('HGET', KEYS[1], 'attempts'))
+if attempts <= 0 then
+    -- 校验次数用完
+    return -2
+end
+if code == ARGV[1] then
+    -- 验证码只能使用一次
+    redis.call('DEL', KEYS[1])
+    return 0
+end
+redis.call('HINCRBY', KEYS[1], 'attempts', -1)
+return -3
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/repository"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/totp"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+//go:embed lua/use_mfa_challenge.lua
+var luaUseMFAChallenge string
+
+// recoveryCodeCount 每次生成的恢复码数量
+const recoveryCodeCount = 10
+
+// MFAOptions 定义两步验证的选项
+type MFAOptions struct {
+	Issuer               string        // 验证器应用中展示的发行方名称
+	ChallengeExpiration  time.Duration // 登录第二步的有效期
+	MaxChallengeAttempts int64         // 登录第二步允许的最大尝试次数
+}
+
+//go:generate mockgen -source=./mfa.go -package=svcmocks -destination=./mocks/mfa.mock.go MFAService
+type MFAService interface {
+	IsEnabled(ctx context.Context, uid int64) (bool, error)
+	// SetupTOTP 生成新的 TOTP 密钥, 需要调用 ConfirmTOTP 确认后才会生效
+	SetupTOTP(ctx context.Context, uid int64) (secret string, uri string, err error)
+	// ConfirmTOTP 使用验证码确认启用 TOTP, 返回一次性恢复码
+	ConfirmTOTP(ctx context.Context, uid int64, code string) ([]string, error)
+	// RegenerateRecoveryCodes 重新生成恢复码, 旧的恢复码全部失效
+	RegenerateRecoveryCodes(ctx context.Context, uid int64, code string) ([]string, error)
+	// Disable 用户使用验证码或恢复码关闭两步验证
+	Disable(ctx context.Context, uid int64, code string) error
+	// Reset 管理员重置用户的两步验证
+	Reset(ctx context.Context, uid int64) error
+	// CreateChallenge 密码校验通过后创建登录第二步的挑战, 返回挑战 token
+	CreateChallenge(ctx context.Context, uid int64) (string, error)
+	// VerifyChallenge 校验挑战 token 和验证码(或恢复码), 返回用户 ID
+	VerifyChallenge(ctx context.Context, token string, code string) (int64, error)
+}
+
+func NewMFAService(opts MFAOptions, cmd redis.Cmdable, mfaRepo repository.UserMFARepository,
+	userRepo repository.UserRepository) MFAService {
+	return &mfaService{
+		opts:     opts,
+		cmd:      cmd,
+		mfaRepo:  mfaRepo,
+		userRepo: userRepo,
+		now:      time.Now,
+	}
+}
+
+type mfaService struct {
+	opts     MFAOptions
+	cmd      redis.Cmdable
+	mfaRepo  repository.UserMFARepository
+	userRepo repository.UserRepository
+	now      func() time.Time
+}
+
+func (m *mfaService) IsEnabled(ctx context.Context, uid int64) (bool, error) {
+	mfa, err := m.mfaRepo.FindByUserID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, errno.ErrDBFailed
+	}
+	return mfa.Enabled, nil
+}
+
+func (m *mfaService) SetupTOTP(ctx context.Context, uid int64) (string, string, error) {
+	enabled, err := m.IsEnabled(ctx, uid)
+	if err != nil {
+		return "", "", err
+	}
+	if enabled {
+		return "", "", errno.ErrEntityExists.SetDescription("已启用两步验证")
+	}
+
+	user, err := m.userRepo.GetOneById(ctx, uid)
+	if err != nil {
+		return "", "", errno.ErrDBFailed
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	err = m.mfaRepo.Save(ctx, domain.UserMFA{UserID: uid, TOTPSecret: secret})
+	if err != nil {
+		return "", "", errno.ErrDBFailed
+	}
+	return secret, totp.URI(m.opts.Issuer, user.UserAccount, secret), nil
+}
+
+func (m *mfaService) ConfirmTOTP(ctx context.Context, uid int64, code string) ([]string, error) {
+	mfa, err := m.find(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.Enabled {
+		return nil, errno.ErrEntityExists.SetDescription("已启用两步验证")
+	}
+	if err = m.verifyTOTP(ctx, mfa, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashed, err := m.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err = m.mfaRepo.Enable(ctx, uid, hashed); err != nil {
+		return nil, errno.ErrDBFailed
+	}
+	return codes, nil
+}
+
+func (m *mfaService) RegenerateRecoveryCodes(ctx context.Context, uid int64, code string) ([]string, error) {
+	mfa, err := m.findEnabled(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if err = m.verifyTOTP(ctx, mfa, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashed, err := m.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err = m.mfaRepo.Enable(ctx, uid, hashed); err != nil {
+		return nil, errno.ErrDBFailed
+	}
+	return codes, nil
+}
+
+func (m *mfaService) Disable(ctx context.Context, uid int64, code string) error {
+	mfa, err := m.findEnabled(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if err = m.verify(ctx, mfa, code); err != nil {
+		return err
+	}
+	return m.Reset(ctx, uid)
+}
+
+func (m *mfaService) Reset(ctx context.Context, uid int64) error {
+	if uid <= 0 {
+		return errno.ErrParameterInvalid
+	}
+	if err := m.mfaRepo.Delete(ctx, uid); err != nil {
+		return errno.ErrDBFailed
+	}
+	return nil
+}
+
+func (m *mfaService) CreateChallenge(ctx context.Context, uid int64) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	key := m.challengeKey(token)
+	if err = m.cmd.HSet(ctx, key, "uid", uid).Err(); err != nil {
+		return "", err
+	}
+	if err = m.cmd.Expire(ctx, key, m.opts.ChallengeExpiration).Err(); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (m *mfaService) VerifyChallenge(ctx context.Context, token string, code string) (int64, error) {
+	key := m.challengeKey(token)
+	// 校验前先占用一次尝试次数, 并发提交的验证码同样受最大尝试次数限制
+	uid, err := m.cmd.Eval(ctx, luaUseMFAChallenge, []string{key}, m.opts.MaxChallengeAttempts).Int64()
+	if err != nil {
+		return 0, err
+	}
+	if uid == -1 {
+		// 超过最大尝试次数后作废, 需要重新输入密码
+		return 0, errno.ErrUnauthorization.SetDescription("两步验证已过期, 请重新登录")
+	}
+
+	mfa, err := m.findEnabled(ctx, uid)
+	if err != nil {
+		return 0, err
+	}
+	if err = m.verify(ctx, mfa, code); err != nil {
+		return 0, err
+	}
+
+	// 挑战只能使用一次
+	deleted, err := m.cmd.Del(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if deleted == 0 {
+		return 0, errno.ErrUnauthorization.SetDescription("两步验证已过期, 请重新登录")
+	}
+	return uid, nil
+}
+
+// verify 校验 TOTP 验证码或恢复码
+func (m *mfaService) verify(ctx context.Context, mfa domain.UserMFA, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return m.verifyTOTP(ctx, mfa, code)
+	}
+
+	old := mfa.RecoveryCodes
+	if !mfa.UseRecoveryCode(code) {
+		return errno.ErrUnauthorization.SetDescription("验证码错误")
+	}
+	ok, err := m.mfaRepo.UpdateRecoveryCodes(ctx, mfa.UserID, old, mfa.RecoveryCodes)
+	if err != nil {
+		return errno.ErrDBFailed
+	}
+	if !ok {
+		// 恢复码已被并发使用
+		return errno.ErrUnauthorization.SetDescription("验证码错误")
+	}
+	return nil
+}
+
+// verifyTOTP 校验 TOTP 验证码, 同一个时间步的验证码只能使用一次
+func (m *mfaService) verifyTOTP(ctx context.Context, mfa domain.UserMFA, code string) error {
+	step, ok := totp.Validate(mfa.TOTPSecret, code, m.now(), 1)
+	if !ok {
+		return errno.ErrUnauthorization.SetDescription("验证码错误")
+	}
+	key := fmt.Sprintf("ucenter:mfa:totp_used:%d:%d", mfa.UserID, step)
+	ok, err := m.cmd.SetNX(ctx, key, 1, 3*totp.Period*time.Second).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errno.ErrUnauthorization.SetDescription("验证码已使用, 请等待下一个验证码")
+	}
+	return nil
+}
+
+// generateRecoveryCodes 生成恢复码, 返回明文和哈希
+func (m *mfaService) generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashed := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := hex.EncodeToString(b)
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashed = append(hashed, domain.HashRecoveryCode(raw))
+	}
+	return codes, hashed, nil
+}
+
+func (m *mfaService) find(ctx context.Context, uid int64) (domain.UserMFA, error) {
+	mfa, err := m.mfaRepo.FindByUserID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.UserMFA{}, errno.ErrEntityNull.SetDescription("未设置两步验证")
+		}
+		return domain.UserMFA{}, errno.ErrDBFailed
+	}
+	return mfa, nil
+}
+
+func (m *mfaService) findEnabled(ctx context.Context, uid int64) (domain.UserMFA, error) {
+	mfa, err := m.find(ctx, uid)
+	if err != nil {
+		return domain.UserMFA{}, err
+	}
+	if !mfa.Enabled {
+		return domain.UserMFA{}, errno.ErrEntityNull.SetDescription("未启用两步验证")
+	}
+	return mfa, nil
+}
+
+func (m *mfaService) challengeKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("ucenter:mfa:challenge:%s", hex.EncodeToString(sum[:]))
+}
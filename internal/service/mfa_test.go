@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coderlewin/ucenter/internal/domain"
+	repomocks "github.com/coderlewin/ucenter/internal/repository/mocks"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/totp"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestMFAService(t *testing.T, mfaRepo *repomocks.MockUserMFARepository, now time.Time) (*mfaService, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	svc := NewMFAService(MFAOptions{
+		Issuer:               "ucenter",
+		ChallengeExpiration:  5 * time.Minute,
+		MaxChallengeAttempts: 3,
+	}, redis.NewClient(&redis.Options{Addr: mr.Addr()}), mfaRepo, nil).(*mfaService)
+	svc.now = func() time.Time { return now }
+	return svc, mr
+}
+
+func Test_mfaService_ConfirmTOTP(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mfaRepo := repomocks.NewMockUserMFARepository(ctrl)
+	svc, _ := newTestMFAService(t, mfaRepo, now)
+
+	mfaRepo.EXPECT().FindByUserID(gomock.Any(), int64(1)).
+		Return(domain.UserMFA{UserID: 1, TOTPSecret: secret}, nil).Times(3)
+
+	// 验证码错误
+	_, err = svc.ConfirmTOTP(context.Background(), 1, "000000")
+	assert.Equal(t, errno.ErrUnauthorization, err)
+
+	var hashed []string
+	mfaRepo.EXPECT().Enable(gomock.Any(), int64(1), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, uid int64, codes []string) error {
+			hashed = codes
+			return nil
+		})
+	codes, err := svc.ConfirmTOTP(context.Background(), 1, code)
+	require.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+	// 只保存恢复码的哈希
+	for i, c := range codes {
+		assert.Equal(t, domain.HashRecoveryCode(c), hashed[i])
+	}
+
+	// 同一个验证码不能重复使用
+	_, err = svc.ConfirmTOTP(context.Background(), 1, code)
+	assert.Equal(t, errno.ErrUnauthorization, err)
+}
+
+func Test_mfaService_VerifyChallenge(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(secret, now)
+	require.NoError(t, err)
+	recoveryCodes := []string{domain.HashRecoveryCode("abcde-12345"), domain.HashRecoveryCode("fghij-67890")}
+	enabled := domain.UserMFA{UserID: 1, TOTPSecret: secret, Enabled: true, RecoveryCodes: recoveryCodes}
+
+	testCases := []struct {
+		name string
+
+		mock func(mfaRepo *repomocks.MockUserMFARepository)
+		code string
+
+		wantErr error
+		wantUID int64
+	}{
+		{
+			name: "TOTP 验证码正确",
+			mock: func(mfaRepo *repomocks.MockUserMFARepository) {
+				mfaRepo.EXPECT().FindByUserID(gomock.Any(), int64(1)).Return(enabled, nil)
+			},
+			code:    code,
+			wantUID: 1,
+		},
+		{
+			name: "恢复码正确, 使用后移除",
+			mock: func(mfaRepo *repomocks.MockUserMFARepository) {
+				mfaRepo.EXPECT().FindByUserID(gomock.Any(), int64(1)).Return(enabled, nil)
+				mfaRepo.EXPECT().UpdateRecoveryCodes(gomock.Any(), int64(1), recoveryCodes, recoveryCodes[1:]).
+					Return(true, nil)
+			},
+			code:    "ABCDE-12345",
+			wantUID: 1,
+		},
+		{
+			name: "恢复码已被并发使用",
+			mock: func(mfaRepo *repomocks.MockUserMFARepository) {
+				mfaRepo.EXPECT().FindByUserID(gomock.Any(), int64(1)).Return(enabled, nil)
+				mfaRepo.EXPECT().UpdateRecoveryCodes(gomock.Any(), int64(1), recoveryCodes, recoveryCodes[1:]).
+					Return(false, nil)
+			},
+			code:    "abcde-12345",
+			wantErr: errno.ErrUnauthorization,
+		},
+		{
+			name: "验证码错误",
+			mock: func(mfaRepo *repomocks.MockUserMFARepository) {
+				mfaRepo.EXPECT().FindByUserID(gomock.Any(), int64(1)).Return(enabled, nil)
+			},
+			code:    "000000",
+			wantErr: errno.ErrUnauthorization,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mfaRepo := repomocks.NewMockUserMFARepository(ctrl)
+			tc.mock(mfaRepo)
+			svc, mr := newTestMFAService(t, mfaRepo, now)
+
+			token, err := svc.CreateChallenge(context.Background(), 1)
+			require.NoError(t, err)
+			uid, err := svc.VerifyChallenge(context.Background(), token, tc.code)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUID, uid)
+			// 校验通过后挑战作废
+			assert.Equal(t, err != nil, mr.Exists(svc.challengeKey(token)))
+		})
+	}
+}
+
+func Test_mfaService_VerifyChallenge_MaxAttempts(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mfaRepo := repomocks.NewMockUserMFARepository(ctrl)
+	mfaRepo.EXPECT().FindByUserID(gomock.Any(), int64(1)).
+		Return(domain.UserMFA{UserID: 1, TOTPSecret: secret, Enabled: true}, nil).Times(3)
+	svc, mr := newTestMFAService(t, mfaRepo, now)
+
+	token, err := svc.CreateChallenge(context.Background(), 1)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = svc.VerifyChallenge(context.Background(), token, "000000")
+		assert.Equal(t, errno.ErrUnauthorization, err)
+	}
+	// 超过最大尝试次数后, 正确的验证码也需要重新登录
+	_, err = svc.VerifyChallenge(context.Background(), token, code)
+	assert.Equal(t, errno.ErrUnauthorization, err)
+	assert.Equal(t, "两步验证已过期, 请重新登录", errno.ErrUnauthorization.Desc)
+	assert.False(t, mr.Exists(svc.challengeKey(token)))
+}
+
+func Test_mfaService_VerifyChallenge_KeepsTTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mfaRepo := repomocks.NewMockUserMFARepository(ctrl)
+	mfaRepo.EXPECT().FindByUserID(gomock.Any(), int64(1)).
+		Return(domain.UserMFA{UserID: 1, TOTPSecret: secret, Enabled: true}, nil).Times(2)
+	svc, mr := newTestMFAService(t, mfaRepo, now)
+	ctx := context.Background()
+
+	token, err := svc.CreateChallenge(ctx, 1)
+	require.NoError(t, err)
+	key := svc.challengeKey(token)
+	mr.FastForward(time.Minute)
+
+	// 验证失败不影响挑战的过期时间
+	_, err = svc.VerifyChallenge(ctx, token, "000000")
+	assert.Equal(t, errno.ErrUnauthorization, err)
+	assert.Equal(t, 4*time.Minute, mr.TTL(key))
+	assert.Equal(t, "1", mr.HGet(key, "attempts"))
+
+	uid, err := svc.VerifyChallenge(ctx, token, code)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), uid)
+
+	// 挑战使用后再次提交不会重新创建
+	_, err = svc.VerifyChallenge(ctx, token, code)
+	assert.Equal(t, errno.ErrUnauthorization, err)
+	assert.False(t, mr.Exists(key))
+}
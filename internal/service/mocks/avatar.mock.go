@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./avatar.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/coderlewin/ucenter/internal/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAvatarService is a mock of AvatarService interface.
+type MockAvatarService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAvatarServiceMockRecorder
+}
+
+// MockAvatarServiceMockRecorder is the mock recorder for MockAvatarService.
+type MockAvatarServiceMockRecorder struct {
+	mock *MockAvatarService
+}
+
+// NewMockAvatarService creates a new mock instance.
+func NewMockAvatarService(ctrl *gomock.Controller) *MockAvatarService {
+	mock := &MockAvatarService{ctrl: ctrl}
+	mock.recorder = &MockAvatarServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAvatarService) EXPECT() *MockAvatarServiceMockRecorder {
+	return m.recorder
+}
+
+// Upload mocks base method.
+func (m *MockAvatarService) Upload(ctx context.Context, uid int64, data []byte) (domain.Avatar, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upload", ctx, uid, data)
+	ret0, _ := ret[0].(domain.Avatar)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upload indicates an expected call of Upload.
+func (mr *MockAvatarServiceMockRecorder) Upload(ctx, uid, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockAvatarService)(nil).Upload), ctx, uid, data)
+}
@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./captcha.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/coderlewin/ucenter/internal/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCaptchaService is a mock of CaptchaService interface.
+type MockCaptchaService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCaptchaServiceMockRecorder
+}
+
+// MockCaptchaServiceMockRecorder is the mock recorder for MockCaptchaService.
+type MockCaptchaServiceMockRecorder struct {
+	mock *MockCaptchaService
+}
+
+// NewMockCaptchaService creates a new mock instance.
+func NewMockCaptchaService(ctrl *gomock.Controller) *MockCaptchaService {
+	mock := &MockCaptchaService{ctrl: ctrl}
+	mock.recorder = &MockCaptchaServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCaptchaService) EXPECT() *MockCaptchaServiceMockRecorder {
+	return m.recorder
+}
+
+// CheckLogin mocks base method.
+func (m *MockCaptchaService) CheckLogin(ctx context.Context, account, ip, id, answer string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckLogin", ctx, account, ip, id, answer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckLogin indicates an expected call of CheckLogin.
+func (mr *MockCaptchaServiceMockRecorder) CheckLogin(ctx, account, ip, id, answer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckLogin", reflect.TypeOf((*MockCaptchaService)(nil).CheckLogin), ctx, account, ip, id, answer)
+}
+
+// CheckRegister mocks base method.
+func (m *MockCaptchaService) CheckRegister(ctx context.Context, id, answer string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRegister", ctx, id, answer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckRegister indicates an expected call of CheckRegister.
+func (mr *MockCaptchaServiceMockRecorder) CheckRegister(ctx, id, answer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRegister", reflect.TypeOf((*MockCaptchaService)(nil).CheckRegister), ctx, id, answer)
+}
+
+// Generate mocks base method.
+func (m *MockCaptchaService) Generate(ctx context.Context) (domain.Captcha, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Generate", ctx)
+	ret0, _ := ret[0].(domain.Captcha)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Generate indicates an expected call of Generate.
+func (mr *MockCaptchaServiceMockRecorder) Generate(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockCaptchaService)(nil).Generate), ctx)
+}
+
+// Verify mocks base method.
+func (m *MockCaptchaService) Verify(ctx context.Context, id, answer string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, id, answer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockCaptchaServiceMockRecorder) Verify(ctx, id, answer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCaptchaService)(nil).Verify), ctx, id, answer)
+}
@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./default_avatar.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDefaultAvatarService is a mock of DefaultAvatarService interface.
+type MockDefaultAvatarService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDefaultAvatarServiceMockRecorder
+}
+
+// MockDefaultAvatarServiceMockRecorder is the mock recorder for MockDefaultAvatarService.
+type MockDefaultAvatarServiceMockRecorder struct {
+	mock *MockDefaultAvatarService
+}
+
+// NewMockDefaultAvatarService creates a new mock instance.
+func NewMockDefaultAvatarService(ctrl *gomock.Controller) *MockDefaultAvatarService {
+	mock := &MockDefaultAvatarService{ctrl: ctrl}
+	mock.recorder = &MockDefaultAvatarServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDefaultAvatarService) EXPECT() *MockDefaultAvatarServiceMockRecorder {
+	return m.recorder
+}
+
+// Render mocks base method.
+func (m *MockDefaultAvatarService) Render(style, seed, text string) ([]byte, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Render", style, seed, text)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Render indicates an expected call of Render.
+func (mr *MockDefaultAvatarServiceMockRecorder) Render(style, seed, text interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Render", reflect.TypeOf((*MockDefaultAvatarService)(nil).Render), style, seed, text)
+}
+
+// URL mocks base method.
+func (m *MockDefaultAvatarService) URL(account, username string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "URL", account, username)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// URL indicates an expected call of URL.
+func (mr *MockDefaultAvatarServiceMockRecorder) URL(account, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "URL", reflect.TypeOf((*MockDefaultAvatarService)(nil).URL), account, username)
+}
@@ -0,0 +1,121 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./email_verification.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockEmailVerificationService is a mock of EmailVerificationService interface.
+type MockEmailVerificationService struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailVerificationServiceMockRecorder
+}
+
+// MockEmailVerificationServiceMockRecorder is the mock recorder for MockEmailVerificationService.
+type MockEmailVerificationServiceMockRecorder struct {
+	mock *MockEmailVerificationService
+}
+
+// NewMockEmailVerificationService creates a new mock instance.
+func NewMockEmailVerificationService(ctrl *gomock.Controller) *MockEmailVerificationService {
+	mock := &MockEmailVerificationService{ctrl: ctrl}
+	mock.recorder = &MockEmailVerificationServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailVerificationService) EXPECT() *MockEmailVerificationServiceMockRecorder {
+	return m.recorder
+}
+
+// Enabled mocks base method.
+func (m *MockEmailVerificationService) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockEmailVerificationServiceMockRecorder) Enabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockEmailVerificationService)(nil).Enabled))
+}
+
+// Resend mocks base method.
+func (m *MockEmailVerificationService) Resend(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resend", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resend indicates an expected call of Resend.
+func (mr *MockEmailVerificationServiceMockRecorder) Resend(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resend", reflect.TypeOf((*MockEmailVerificationService)(nil).Resend), ctx, email)
+}
+
+// SendChangeEmail mocks base method.
+func (m *MockEmailVerificationService) SendChangeEmail(ctx context.Context, uid int64, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendChangeEmail", ctx, uid, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendChangeEmail indicates an expected call of SendChangeEmail.
+func (mr *MockEmailVerificationServiceMockRecorder) SendChangeEmail(ctx, uid, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendChangeEmail", reflect.TypeOf((*MockEmailVerificationService)(nil).SendChangeEmail), ctx, uid, email)
+}
+
+// SendVerifyEmail mocks base method.
+func (m *MockEmailVerificationService) SendVerifyEmail(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendVerifyEmail", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendVerifyEmail indicates an expected call of SendVerifyEmail.
+func (mr *MockEmailVerificationServiceMockRecorder) SendVerifyEmail(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVerifyEmail", reflect.TypeOf((*MockEmailVerificationService)(nil).SendVerifyEmail), ctx, uid)
+}
+
+// Verify mocks base method.
+func (m *MockEmailVerificationService) Verify(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockEmailVerificationServiceMockRecorder) Verify(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockEmailVerificationService)(nil).Verify), ctx, token)
+}
+
+// VerifyChange mocks base method.
+func (m *MockEmailVerificationService) VerifyChange(ctx context.Context, token string) (int64, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyChange", ctx, token)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// VerifyChange indicates an expected call of VerifyChange.
+func (mr *MockEmailVerificationServiceMockRecorder) VerifyChange(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyChange", reflect.TypeOf((*MockEmailVerificationService)(nil).VerifyChange), ctx, token)
+}
@@ -0,0 +1,166 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./jwt.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	app "github.com/cloudwego/hertz/pkg/app"
+	dto "github.com/coderlewin/ucenter/internal/web/dto"
+	keyring "github.com/coderlewin/ucenter/pkg/keyring"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockJWTService is a mock of JWTService interface.
+type MockJWTService struct {
+	ctrl     *gomock.Controller
+	recorder *MockJWTServiceMockRecorder
+}
+
+// MockJWTServiceMockRecorder is the mock recorder for MockJWTService.
+type MockJWTServiceMockRecorder struct {
+	mock *MockJWTService
+}
+
+// NewMockJWTService creates a new mock instance.
+func NewMockJWTService(ctrl *gomock.Controller) *MockJWTService {
+	mock := &MockJWTService{ctrl: ctrl}
+	mock.recorder = &MockJWTServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJWTService) EXPECT() *MockJWTServiceMockRecorder {
+	return m.recorder
+}
+
+// CheckSession mocks base method.
+func (m *MockJWTService) CheckSession(ctx context.Context, ssid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckSession", ctx, ssid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckSession indicates an expected call of CheckSession.
+func (mr *MockJWTServiceMockRecorder) CheckSession(ctx, ssid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSession", reflect.TypeOf((*MockJWTService)(nil).CheckSession), ctx, ssid)
+}
+
+// ClearToken mocks base method.
+func (m *MockJWTService) ClearToken(c context.Context, ctx *app.RequestContext) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearToken", c, ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearToken indicates an expected call of ClearToken.
+func (mr *MockJWTServiceMockRecorder) ClearToken(c, ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearToken", reflect.TypeOf((*MockJWTService)(nil).ClearToken), c, ctx)
+}
+
+// ExtractTokenString mocks base method.
+func (m *MockJWTService) ExtractTokenString(ctx *app.RequestContext) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtractTokenString", ctx)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ExtractTokenString indicates an expected call of ExtractTokenString.
+func (mr *MockJWTServiceMockRecorder) ExtractTokenString(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractTokenString", reflect.TypeOf((*MockJWTService)(nil).ExtractTokenString), ctx)
+}
+
+// JWKS mocks base method.
+func (m *MockJWTService) JWKS() keyring.JWKSet {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(keyring.JWKSet)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockJWTServiceMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockJWTService)(nil).JWKS))
+}
+
+// ParseAccessToken mocks base method.
+func (m *MockJWTService) ParseAccessToken(tokenString string) (dto.UserClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ParseAccessToken", tokenString)
+	ret0, _ := ret[0].(dto.UserClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ParseAccessToken indicates an expected call of ParseAccessToken.
+func (mr *MockJWTServiceMockRecorder) ParseAccessToken(tokenString interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseAccessToken", reflect.TypeOf((*MockJWTService)(nil).ParseAccessToken), tokenString)
+}
+
+// RefreshToken mocks base method.
+func (m *MockJWTService) RefreshToken(c context.Context, ctx *app.RequestContext, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", c, ctx, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockJWTServiceMockRecorder) RefreshToken(c, ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockJWTService)(nil).RefreshToken), c, ctx, refreshToken)
+}
+
+// SetJWTToken mocks base method.
+func (m *MockJWTService) SetJWTToken(ctx *app.RequestContext, ssid string, uid int64, role, version int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetJWTToken", ctx, ssid, uid, role, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetJWTToken indicates an expected call of SetJWTToken.
+func (mr *MockJWTServiceMockRecorder) SetJWTToken(ctx, ssid, uid, role, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetJWTToken", reflect.TypeOf((*MockJWTService)(nil).SetJWTToken), ctx, ssid, uid, role, version)
+}
+
+// SetLoginToken mocks base method.
+func (m *MockJWTService) SetLoginToken(c context.Context, ctx *app.RequestContext, uid int64, role, version int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLoginToken", c, ctx, uid, role, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLoginToken indicates an expected call of SetLoginToken.
+func (mr *MockJWTServiceMockRecorder) SetLoginToken(c, ctx, uid, role, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLoginToken", reflect.TypeOf((*MockJWTService)(nil).SetLoginToken), c, ctx, uid, role, version)
+}
+
+// SignIDToken mocks base method.
+func (m *MockJWTService) SignIDToken(claims dto.IDTokenClaims) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignIDToken", claims)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignIDToken indicates an expected call of SignIDToken.
+func (mr *MockJWTServiceMockRecorder) SignIDToken(claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIDToken", reflect.TypeOf((*MockJWTService)(nil).SignIDToken), claims)
+}
@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./login_limit.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/coderlewin/ucenter/internal/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLoginLimitService is a mock of LoginLimitService interface.
+type MockLoginLimitService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginLimitServiceMockRecorder
+}
+
+// MockLoginLimitServiceMockRecorder is the mock recorder for MockLoginLimitService.
+type MockLoginLimitServiceMockRecorder struct {
+	mock *MockLoginLimitService
+}
+
+// NewMockLoginLimitService creates a new mock instance.
+func NewMockLoginLimitService(ctrl *gomock.Controller) *MockLoginLimitService {
+	mock := &MockLoginLimitService{ctrl: ctrl}
+	mock.recorder = &MockLoginLimitServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginLimitService) EXPECT() *MockLoginLimitServiceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockLoginLimitService) Check(ctx context.Context, account, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, account, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockLoginLimitServiceMockRecorder) Check(ctx, account, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockLoginLimitService)(nil).Check), ctx, account, ip)
+}
+
+// Fail mocks base method.
+func (m *MockLoginLimitService) Fail(ctx context.Context, account, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", ctx, account, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockLoginLimitServiceMockRecorder) Fail(ctx, account, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockLoginLimitService)(nil).Fail), ctx, account, ip)
+}
+
+// GetLocks mocks base method.
+func (m *MockLoginLimitService) GetLocks(ctx context.Context, account, ip string) ([]domain.LoginLock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLocks", ctx, account, ip)
+	ret0, _ := ret[0].([]domain.LoginLock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLocks indicates an expected call of GetLocks.
+func (mr *MockLoginLimitServiceMockRecorder) GetLocks(ctx, account, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLocks", reflect.TypeOf((*MockLoginLimitService)(nil).GetLocks), ctx, account, ip)
+}
+
+// Succeed mocks base method.
+func (m *MockLoginLimitService) Succeed(ctx context.Context, account string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Succeed", ctx, account)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Succeed indicates an expected call of Succeed.
+func (mr *MockLoginLimitServiceMockRecorder) Succeed(ctx, account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Succeed", reflect.TypeOf((*MockLoginLimitService)(nil).Succeed), ctx, account)
+}
+
+// Unlock mocks base method.
+func (m *MockLoginLimitService) Unlock(ctx context.Context, account, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", ctx, account, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *MockLoginLimitServiceMockRecorder) Unlock(ctx, account, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockLoginLimitService)(nil).Unlock), ctx, account, ip)
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./login_state.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/coderlewin/ucenter/internal/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLoginStateService is a mock of LoginStateService interface.
+type MockLoginStateService struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginStateServiceMockRecorder
+}
+
+// MockLoginStateServiceMockRecorder is the mock recorder for MockLoginStateService.
+type MockLoginStateServiceMockRecorder struct {
+	mock *MockLoginStateService
+}
+
+// NewMockLoginStateService creates a new mock instance.
+func NewMockLoginStateService(ctrl *gomock.Controller) *MockLoginStateService {
+	mock := &MockLoginStateService{ctrl: ctrl}
+	mock.recorder = &MockLoginStateServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginStateService) EXPECT() *MockLoginStateServiceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockLoginStateService) Check(ctx context.Context, uid int64, version int32) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, uid, version)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockLoginStateServiceMockRecorder) Check(ctx, uid, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockLoginStateService)(nil).Check), ctx, uid, version)
+}
+
+// Invalidate mocks base method.
+func (m *MockLoginStateService) Invalidate(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Invalidate", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Invalidate indicates an expected call of Invalidate.
+func (mr *MockLoginStateServiceMockRecorder) Invalidate(ctx, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invalidate", reflect.TypeOf((*MockLoginStateService)(nil).Invalidate), ctx, uid)
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./password_policy.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/coderlewin/ucenter/internal/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPasswordPolicyService is a mock of PasswordPolicyService interface.
+type MockPasswordPolicyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordPolicyServiceMockRecorder
+}
+
+// MockPasswordPolicyServiceMockRecorder is the mock recorder for MockPasswordPolicyService.
+type MockPasswordPolicyServiceMockRecorder struct {
+	mock *MockPasswordPolicyService
+}
+
+// NewMockPasswordPolicyService creates a new mock instance.
+func NewMockPasswordPolicyService(ctrl *gomock.Controller) *MockPasswordPolicyService {
+	mock := &MockPasswordPolicyService{ctrl: ctrl}
+	mock.recorder = &MockPasswordPolicyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordPolicyService) EXPECT() *MockPasswordPolicyServiceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockPasswordPolicyService) Check(ctx context.Context, user domain.User, password string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, user, password)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockPasswordPolicyServiceMockRecorder) Check(ctx, user, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockPasswordPolicyService)(nil).Check), ctx, user, password)
+}
+
+// Record mocks base method.
+func (m *MockPasswordPolicyService) Record(ctx context.Context, uid int64, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, uid, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockPasswordPolicyServiceMockRecorder) Record(ctx, uid, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockPasswordPolicyService)(nil).Record), ctx, uid, hash)
+}
+
+// Validate mocks base method.
+func (m *MockPasswordPolicyService) Validate(ctx context.Context, user domain.User, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", ctx, user, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockPasswordPolicyServiceMockRecorder) Validate(ctx, user, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockPasswordPolicyService)(nil).Validate), ctx, user, password)
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./profile.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/coderlewin/ucenter/internal/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockProfileService is a mock of ProfileService interface.
+type MockProfileService struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfileServiceMockRecorder
+}
+
+// MockProfileServiceMockRecorder is the mock recorder for MockProfileService.
+type MockProfileServiceMockRecorder struct {
+	mock *MockProfileService
+}
+
+// NewMockProfileService creates a new mock instance.
+func NewMockProfileService(ctrl *gomock.Controller) *MockProfileService {
+	mock := &MockProfileService{ctrl: ctrl}
+	mock.recorder = &MockProfileServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfileService) EXPECT() *MockProfileServiceMockRecorder {
+	return m.recorder
+}
+
+// ConfirmEmailChange mocks base method.
+func (m *MockProfileService) ConfirmEmailChange(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange.
+func (mr *MockProfileServiceMockRecorder) ConfirmEmailChange(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockProfileService)(nil).ConfirmEmailChange), ctx, token)
+}
+
+// ForceUpdateProfile mocks base method.
+func (m *MockProfileService) ForceUpdateProfile(ctx context.Context, uid int64, profile domain.UserProfile) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForceUpdateProfile", ctx, uid, profile)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ForceUpdateProfile indicates an expected call of ForceUpdateProfile.
+func (mr *MockProfileServiceMockRecorder) ForceUpdateProfile(ctx, uid, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceUpdateProfile", reflect.TypeOf((*MockProfileService)(nil).ForceUpdateProfile), ctx, uid, profile)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockProfileService) UpdateProfile(ctx context.Context, uid int64, profile domain.UserProfile) (domain.User, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, uid, profile)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockProfileServiceMockRecorder) UpdateProfile(ctx, uid, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockProfileService)(nil).UpdateProfile), ctx, uid, profile)
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./sms.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/coderlewin/ucenter/internal/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSMSService is a mock of SMSService interface.
+type MockSMSService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSMSServiceMockRecorder
+}
+
+// MockSMSServiceMockRecorder is the mock recorder for MockSMSService.
+type MockSMSServiceMockRecorder struct {
+	mock *MockSMSService
+}
+
+// NewMockSMSService creates a new mock instance.
+func NewMockSMSService(ctrl *gomock.Controller) *MockSMSService {
+	mock := &MockSMSService{ctrl: ctrl}
+	mock.recorder = &MockSMSServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSMSService) EXPECT() *MockSMSServiceMockRecorder {
+	return m.recorder
+}
+
+// LoginByCode mocks base method.
+func (m *MockSMSService) LoginByCode(ctx context.Context, phone, code string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginByCode", ctx, phone, code)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginByCode indicates an expected call of LoginByCode.
+func (mr *MockSMSServiceMockRecorder) LoginByCode(ctx, phone, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginByCode", reflect.TypeOf((*MockSMSService)(nil).LoginByCode), ctx, phone, code)
+}
+
+// SendBindCode mocks base method.
+func (m *MockSMSService) SendBindCode(ctx context.Context, phone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBindCode", ctx, phone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendBindCode indicates an expected call of SendBindCode.
+func (mr *MockSMSServiceMockRecorder) SendBindCode(ctx, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBindCode", reflect.TypeOf((*MockSMSService)(nil).SendBindCode), ctx, phone)
+}
+
+// SendLoginCode mocks base method.
+func (m *MockSMSService) SendLoginCode(ctx context.Context, phone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLoginCode", ctx, phone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendLoginCode indicates an expected call of SendLoginCode.
+func (mr *MockSMSServiceMockRecorder) SendLoginCode(ctx, phone interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLoginCode", reflect.TypeOf((*MockSMSService)(nil).SendLoginCode), ctx, phone)
+}
+
+// VerifyBindCode mocks base method.
+func (m *MockSMSService) VerifyBindCode(ctx context.Context, phone, code string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyBindCode", ctx, phone, code)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyBindCode indicates an expected call of VerifyBindCode.
+func (mr *MockSMSServiceMockRecorder) VerifyBindCode(ctx, phone, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyBindCode", reflect.TypeOf((*MockSMSService)(nil).VerifyBindCode), ctx, phone, code)
+}
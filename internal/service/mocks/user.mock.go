@@ -36,6 +36,36 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockUserService) ChangePassword(ctx context.Context, uid int64, oldPassword string, ud domain.User, ip, userAgent string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, uid, oldPassword, ud, ip, userAgent)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUserServiceMockRecorder) ChangePassword(ctx, uid, oldPassword, ud, ip, userAgent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUserService)(nil).ChangePassword), ctx, uid, oldPassword, ud, ip, userAgent)
+}
+
+// CheckRegister mocks base method.
+func (m *MockUserService) CheckRegister(ctx context.Context, ud domain.User) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRegister", ctx, ud)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckRegister indicates an expected call of CheckRegister.
+func (mr *MockUserServiceMockRecorder) CheckRegister(ctx, ud interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRegister", reflect.TypeOf((*MockUserService)(nil).CheckRegister), ctx, ud)
+}
+
 // Delete mocks base method.
 func (m *MockUserService) Delete(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
@@ -66,9 +96,9 @@ func (mr *MockUserServiceMockRecorder) GetCurrentUser(ctx, id interface{}) *gomo
 }
 
 // List mocks base method.
-func (m *MockUserService) List(ctx context.Context, username string, current, size int) ([]domain.User, int64, error) {
+func (m *MockUserService) List(ctx context.Context, query domain.UserQuery) ([]domain.User, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx, username, current, size)
+	ret := m.ctrl.Call(m, "List", ctx, query)
 	ret0, _ := ret[0].([]domain.User)
 	ret1, _ := ret[1].(int64)
 	ret2, _ := ret[2].(error)
@@ -76,24 +106,39 @@ func (m *MockUserService) List(ctx context.Context, username string, current, si
 }
 
 // List indicates an expected call of List.
-func (mr *MockUserServiceMockRecorder) List(ctx, username, current, size interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) List(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserService)(nil).List), ctx, query)
+}
+
+// ListByCursor mocks base method.
+func (m *MockUserService) ListByCursor(ctx context.Context, query domain.UserCursorQuery) (domain.UserCursorPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByCursor", ctx, query)
+	ret0, _ := ret[0].(domain.UserCursorPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByCursor indicates an expected call of ListByCursor.
+func (mr *MockUserServiceMockRecorder) ListByCursor(ctx, query interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserService)(nil).List), ctx, username, current, size)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByCursor", reflect.TypeOf((*MockUserService)(nil).ListByCursor), ctx, query)
 }
 
 // Login mocks base method.
-func (m *MockUserService) Login(ctx context.Context, ud domain.User) (domain.User, error) {
+func (m *MockUserService) Login(ctx context.Context, ud domain.User, ip string) (domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", ctx, ud)
+	ret := m.ctrl.Call(m, "Login", ctx, ud, ip)
 	ret0, _ := ret[0].(domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Login indicates an expected call of Login.
-func (mr *MockUserServiceMockRecorder) Login(ctx, ud interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) Login(ctx, ud, ip interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserService)(nil).Login), ctx, ud)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserService)(nil).Login), ctx, ud, ip)
 }
 
 // Logout mocks base method.
@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./user_admin.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "github.com/coderlewin/ucenter/internal/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockUserAdminService is a mock of UserAdminService interface.
+type MockUserAdminService struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserAdminServiceMockRecorder
+}
+
+// MockUserAdminServiceMockRecorder is the mock recorder for MockUserAdminService.
+type MockUserAdminServiceMockRecorder struct {
+	mock *MockUserAdminService
+}
+
+// NewMockUserAdminService creates a new mock instance.
+func NewMockUserAdminService(ctrl *gomock.Controller) *MockUserAdminService {
+	mock := &MockUserAdminService{ctrl: ctrl}
+	mock.recorder = &MockUserAdminServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserAdminService) EXPECT() *MockUserAdminServiceMockRecorder {
+	return m.recorder
+}
+
+// ChangeRole mocks base method.
+func (m *MockUserAdminService) ChangeRole(ctx context.Context, op domain.Operator, uid int64, role int32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeRole", ctx, op, uid, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeRole indicates an expected call of ChangeRole.
+func (mr *MockUserAdminServiceMockRecorder) ChangeRole(ctx, op, uid, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeRole", reflect.TypeOf((*MockUserAdminService)(nil).ChangeRole), ctx, op, uid, role)
+}
+
+// Create mocks base method.
+func (m *MockUserAdminService) Create(ctx context.Context, op domain.Operator, ud domain.User) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, op, ud)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUserAdminServiceMockRecorder) Create(ctx, op, ud interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserAdminService)(nil).Create), ctx, op, ud)
+}
+
+// Freeze mocks base method.
+func (m *MockUserAdminService) Freeze(ctx context.Context, op domain.Operator, uid int64, reason string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Freeze", ctx, op, uid, reason, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Freeze indicates an expected call of Freeze.
+func (mr *MockUserAdminServiceMockRecorder) Freeze(ctx, op, uid, reason, until interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Freeze", reflect.TypeOf((*MockUserAdminService)(nil).Freeze), ctx, op, uid, reason, until)
+}
+
+// Unfreeze mocks base method.
+func (m *MockUserAdminService) Unfreeze(ctx context.Context, op domain.Operator, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unfreeze", ctx, op, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unfreeze indicates an expected call of Unfreeze.
+func (mr *MockUserAdminServiceMockRecorder) Unfreeze(ctx, op, uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unfreeze", reflect.TypeOf((*MockUserAdminService)(nil).Unfreeze), ctx, op, uid)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUserAdminService) UpdateProfile(ctx context.Context, op domain.Operator, uid int64, profile domain.UserProfile) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, op, uid, profile)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUserAdminServiceMockRecorder) UpdateProfile(ctx, op, uid, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserAdminService)(nil).UpdateProfile), ctx, op, uid, profile)
+}
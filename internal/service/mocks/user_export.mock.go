@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./user_export.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	domain "github.com/coderlewin/ucenter/internal/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockUserExportService is a mock of UserExportService interface.
+type MockUserExportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserExportServiceMockRecorder
+}
+
+// MockUserExportServiceMockRecorder is the mock recorder for MockUserExportService.
+type MockUserExportServiceMockRecorder struct {
+	mock *MockUserExportService
+}
+
+// NewMockUserExportService creates a new mock instance.
+func NewMockUserExportService(ctrl *gomock.Controller) *MockUserExportService {
+	mock := &MockUserExportService{ctrl: ctrl}
+	mock.recorder = &MockUserExportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserExportService) EXPECT() *MockUserExportServiceMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockUserExportService) Export(ctx context.Context, op domain.Operator, req domain.UserExport, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, op, req, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockUserExportServiceMockRecorder) Export(ctx, op, req, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockUserExportService)(nil).Export), ctx, op, req, w)
+}
+
+// Prepare mocks base method.
+func (m *MockUserExportService) Prepare(req domain.UserExport) (domain.UserExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prepare", req)
+	ret0, _ := ret[0].(domain.UserExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prepare indicates an expected call of Prepare.
+func (mr *MockUserExportServiceMockRecorder) Prepare(req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prepare", reflect.TypeOf((*MockUserExportService)(nil).Prepare), req)
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./user_import.go
+
+// Package svcmocks is a generated GoMock package.
+package svcmocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/coderlewin/ucenter/internal/domain"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockUserImportService is a mock of UserImportService interface.
+type MockUserImportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserImportServiceMockRecorder
+}
+
+// MockUserImportServiceMockRecorder is the mock recorder for MockUserImportService.
+type MockUserImportServiceMockRecorder struct {
+	mock *MockUserImportService
+}
+
+// NewMockUserImportService creates a new mock instance.
+func NewMockUserImportService(ctrl *gomock.Controller) *MockUserImportService {
+	mock := &MockUserImportService{ctrl: ctrl}
+	mock.recorder = &MockUserImportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserImportService) EXPECT() *MockUserImportServiceMockRecorder {
+	return m.recorder
+}
+
+// GetJob mocks base method.
+func (m *MockUserImportService) GetJob(ctx context.Context, id string) (domain.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, id)
+	ret0, _ := ret[0].(domain.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockUserImportServiceMockRecorder) GetJob(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockUserImportService)(nil).GetJob), ctx, id)
+}
+
+// Import mocks base method.
+func (m *MockUserImportService) Import(ctx context.Context, op domain.Operator, data []byte, dryRun bool) (domain.ImportJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, op, data, dryRun)
+	ret0, _ := ret[0].(domain.ImportJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockUserImportServiceMockRecorder) Import(ctx, op, data, dryRun interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockUserImportService)(nil).Import), ctx, op, data, dryRun)
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/repository"
+	"github.com/coderlewin/ucenter/internal/web/dto"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/hasher"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+// 支持的授权范围
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+	ScopePhone   = "phone"
+)
+
+// CodeChallengeS256 唯一支持的 PKCE code_challenge_method
+const CodeChallengeS256 = "S256"
+
+// SupportedScopes 返回支持的授权范围
+func SupportedScopes() []string {
+	return []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopePhone}
+}
+
+// OAuthError 表示 RFC 6749 中定义的错误
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code
+}
+
+func newOAuthError(code string, format string, args ...any) *OAuthError {
+	return &OAuthError{Code: code, Description: fmt.Sprintf(format, args...)}
+}
+
+// OIDCOptions 定义 OIDC 服务的选项
+type OIDCOptions struct {
+	Issuer                string        // 签发者, 即 ucenter 对外的访问地址
+	CodeExpiration        time.Duration // 授权码有效期
+	AccessTokenExpiration time.Duration // access token 有效期
+	IDTokenExpiration     time.Duration // id token 有效期
+	RequirePKCE           bool          // 是否强制使用 PKCE
+}
+
+//go:generate mockgen -source=./oidc.go -package=svcmocks -destination=./mocks/oidc.mock.go OIDCService
+type OIDCService interface {
+	Issuer() string
+	// CheckAuthorizeRequest 校验客户端和回调地址, 校验失败时不能重定向回客户端
+	CheckAuthorizeRequest(ctx context.Context, req domain.AuthorizeRequest) (domain.AuthorizeRequest, error)
+	// Authorize 为已登录用户签发授权码
+	Authorize(ctx context.Context, uid int64, req domain.AuthorizeRequest) (string, error)
+	// Exchange 使用授权码换取 access token 和 id token
+	Exchange(ctx context.Context, req domain.TokenRequest) (domain.TokenResult, error)
+	// UserInfo 返回 access token 对应的用户及授权范围
+	UserInfo(ctx context.Context, accessToken string) (domain.User, []string, error)
+	CreateClient(ctx context.Context, client domain.OAuthClient) (domain.OAuthClient, error)
+	ListClients(ctx context.Context) ([]domain.OAuthClient, error)
+	DeleteClient(ctx context.Context, id int64) error
+}
+
+func NewOIDCService(opts OIDCOptions, cmd redis.Cmdable, clientRepo repository.OAuthClientRepository,
+	userSvc UserService, jwtSvc JWTService, pwdHasher hasher.PasswordHasher) OIDCService {
+	return &oidcService{
+		opts:       opts,
+		cmd:        cmd,
+		clientRepo: clientRepo,
+		userSvc:    userSvc,
+		jwtSvc:     jwtSvc,
+		pwdHasher:  pwdHasher,
+	}
+}
+
+type oidcService struct {
+	opts       OIDCOptions
+	cmd        redis.Cmdable
+	clientRepo repository.OAuthClientRepository
+	userSvc    UserService
+	jwtSvc     JWTService
+	// 客户端密钥与用户密码使用相同的哈希算法保存
+	pwdHasher hasher.PasswordHasher
+}
+
+// accessTokenInfo access token 对应的授权信息
+type accessTokenInfo struct {
+	UserID   int64    `json:"user_id"`
+	ClientID string   `json:"client_id"`
+	Scope    []string `json:"scope"`
+	// SecurityVersion 签发时用户的安全版本, 修改密码等操作后 access token 随之失效
+	SecurityVersion int32 `json:"security_version"`
+}
+
+func (o *oidcService) Issuer() string {
+	return o.opts.Issuer
+}
+
+func (o *oidcService) CheckAuthorizeRequest(ctx context.Context, req domain.AuthorizeRequest) (domain.AuthorizeRequest, error) {
+	client, err := o.findClient(ctx, req.ClientID)
+	if err != nil {
+		return req, err
+	}
+
+	// 只登记了一个回调地址时可以省略 redirect_uri
+	if req.RedirectURI == "" && len(client.RedirectURIs) == 1 {
+		req.RedirectURI = client.RedirectURIs[0]
+	}
+	if !client.HasRedirectURI(req.RedirectURI) {
+		return req, newOAuthError("invalid_request", "redirect_uri 未登记")
+	}
+	return req, nil
+}
+
+func (o *oidcService) Authorize(ctx context.Context, uid int64, req domain.AuthorizeRequest) (string, error) {
+	req, err := o.CheckAuthorizeRequest(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	if req.ResponseType != "code" {
+		return "", newOAuthError("unsupported_response_type", "仅支持 response_type=code")
+	}
+	if !domain.HasScope(req.Scope, ScopeOpenID) {
+		return "", newOAuthError("invalid_scope", "scope 必须包含 openid")
+	}
+	for _, scope := range req.Scope {
+		if !domain.HasScope(SupportedScopes(), scope) {
+			return "", newOAuthError("invalid_scope", "不支持的 scope %s", scope)
+		}
+	}
+
+	// PKCE 校验, plain 方式无法防止授权码被截获后使用, 只支持 S256
+	if req.CodeChallenge == "" {
+		if o.opts.RequirePKCE {
+			return "", newOAuthError("invalid_request", "缺少 code_challenge")
+		}
+	} else if req.CodeChallengeMethod != CodeChallengeS256 {
+		return "", newOAuthError("invalid_request", "code_challenge_method 必须为 S256")
+	}
+
+	code, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	val, err := json.Marshal(domain.AuthorizationCode{
+		ClientID:            req.ClientID,
+		RedirectURI:         req.RedirectURI,
+		UserID:              uid,
+		Scope:               req.Scope,
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		AuthTime:            time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+	if err = o.cmd.Set(ctx, o.codeKey(code), val, o.opts.CodeExpiration).Err(); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+func (o *oidcService) Exchange(ctx context.Context, req domain.TokenRequest) (domain.TokenResult, error) {
+	if req.GrantType != "authorization_code" {
+		return domain.TokenResult{}, newOAuthError("unsupported_grant_type", "仅支持 authorization_code")
+	}
+
+	client, err := o.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return domain.TokenResult{}, err
+	}
+
+	// 授权码只能使用一次
+	val, err := o.cmd.GetDel(ctx, o.codeKey(req.Code)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return domain.TokenResult{}, newOAuthError("invalid_grant", "授权码无效或已过期")
+		}
+		return domain.TokenResult{}, err
+	}
+	var code domain.AuthorizationCode
+	if err = json.Unmarshal(val, &code); err != nil {
+		return domain.TokenResult{}, err
+	}
+	if code.ClientID != client.ClientID || code.RedirectURI != req.RedirectURI {
+		return domain.TokenResult{}, newOAuthError("invalid_grant", "授权码与客户端不匹配")
+	}
+	if !verifyCodeChallenge(code.CodeChallenge, code.CodeChallengeMethod, req.CodeVerifier) {
+		return domain.TokenResult{}, newOAuthError("invalid_grant", "code_verifier 校验失败")
+	}
+
+	user, err := o.userSvc.GetCurrentUser(ctx, code.UserID)
+	if err != nil {
+		if errors.Is(err, errno.ErrEntityNull) {
+			return domain.TokenResult{}, newOAuthError("invalid_grant", "用户不存在")
+		}
+		return domain.TokenResult{}, err
+	}
+	if user.IsFreeze() {
+		return domain.TokenResult{}, newOAuthError("invalid_grant", "账号已被冻结")
+	}
+
+	accessToken, err := o.issueAccessToken(ctx, accessTokenInfo{
+		UserID:          user.ID,
+		ClientID:        client.ClientID,
+		Scope:           code.Scope,
+		SecurityVersion: user.SecurityVersion,
+	})
+	if err != nil {
+		return domain.TokenResult{}, err
+	}
+
+	now := time.Now()
+	idToken, err := o.jwtSvc.SignIDToken(dto.IDTokenClaims{
+		Nonce:    code.Nonce,
+		AuthTime: jwt.NewNumericDate(code.AuthTime),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    o.opts.Issuer,
+			Subject:   strconv.FormatInt(user.ID, 10),
+			Audience:  jwt.ClaimStrings{client.ClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(o.opts.IDTokenExpiration)),
+		},
+	})
+	if err != nil {
+		return domain.TokenResult{}, err
+	}
+
+	return domain.TokenResult{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(o.opts.AccessTokenExpiration.Seconds()),
+		IDToken:     idToken,
+		Scope:       code.Scope,
+	}, nil
+}
+
+func (o *oidcService) UserInfo(ctx context.Context, accessToken string) (domain.User, []string, error) {
+	val, err := o.cmd.Get(ctx, o.accessTokenKey(accessToken)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return domain.User{}, nil, newOAuthError("invalid_token", "access token 无效或已过期")
+		}
+		return domain.User{}, nil, err
+	}
+	var info accessTokenInfo
+	if err = json.Unmarshal(val, &info); err != nil {
+		return domain.User{}, nil, err
+	}
+
+	// 客户端被删除后, 已签发的 access token 随之失效
+	if _, err = o.clientRepo.FindByClientID(ctx, info.ClientID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.User{}, nil, newOAuthError("invalid_token", "客户端不存在")
+		}
+		return domain.User{}, nil, err
+	}
+
+	user, err := o.userSvc.GetCurrentUser(ctx, info.UserID)
+	if err != nil {
+		if errors.Is(err, errno.ErrEntityNull) {
+			return domain.User{}, nil, newOAuthError("invalid_token", "用户不存在")
+		}
+		return domain.User{}, nil, err
+	}
+	if user.IsFreeze() {
+		return domain.User{}, nil, newOAuthError("invalid_token", "账号已被冻结")
+	}
+	if user.SecurityVersion != info.SecurityVersion {
+		return domain.User{}, nil, newOAuthError("invalid_token", "登录状态已失效, 请重新授权")
+	}
+	return user, info.Scope, nil
+}
+
+func (o *oidcService) CreateClient(ctx context.Context, client domain.OAuthClient) (domain.OAuthClient, error) {
+	if err := client.ValidateParameters(); err != nil {
+		return domain.OAuthClient{}, err
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return domain.OAuthClient{}, err
+	}
+	client.ClientID = uuid.New().String()
+	client.ClientSecret, err = o.pwdHasher.Hash(secret)
+	if err != nil {
+		return domain.OAuthClient{}, err
+	}
+
+	client.ID, err = o.clientRepo.Create(ctx, client)
+	if err != nil {
+		return domain.OAuthClient{}, errno.ErrDBFailed
+	}
+	// 密钥明文只在创建时返回一次
+	client.ClientSecret = secret
+	return client, nil
+}
+
+func (o *oidcService) ListClients(ctx context.Context) ([]domain.OAuthClient, error) {
+	clients, err := o.clientRepo.List(ctx)
+	if err != nil {
+		return nil, errno.ErrDBFailed
+	}
+	return clients, nil
+}
+
+func (o *oidcService) DeleteClient(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errno.ErrParameterInvalid
+	}
+	return o.clientRepo.Delete(ctx, id)
+}
+
+func (o *oidcService) findClient(ctx context.Context, clientID string) (domain.OAuthClient, error) {
+	if clientID == "" {
+		return domain.OAuthClient{}, newOAuthError("invalid_request", "缺少 client_id")
+	}
+	client, err := o.clientRepo.FindByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.OAuthClient{}, newOAuthError("invalid_client", "客户端不存在")
+		}
+		return domain.OAuthClient{}, err
+	}
+	return client, nil
+}
+
+// authenticateClient 校验客户端的 client_id 和 client_secret
+func (o *oidcService) authenticateClient(ctx context.Context, clientID, clientSecret string) (domain.OAuthClient, error) {
+	client, err := o.findClient(ctx, clientID)
+	if err != nil {
+		return domain.OAuthClient{}, err
+	}
+	ok, err := o.pwdHasher.Verify(clientSecret, client.ClientSecret)
+	if err != nil || !ok {
+		return domain.OAuthClient{}, newOAuthError("invalid_client", "客户端认证失败")
+	}
+	return client, nil
+}
+
+func (o *oidcService) issueAccessToken(ctx context.Context, info accessTokenInfo) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	val, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+	err = o.cmd.Set(ctx, o.accessTokenKey(token), val, o.opts.AccessTokenExpiration).Err()
+	return token, err
+}
+
+func (o *oidcService) codeKey(code string) string {
+	return fmt.Sprintf("ucenter:oidc:code:%s", code)
+}
+
+// accessTokenKey access token 只保存摘要, 防止 Redis 数据泄露后被直接使用
+func (o *oidcService) accessTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("ucenter:oidc:access_token:%s", hex.EncodeToString(sum[:]))
+}
+
+// verifyCodeChallenge 按 RFC 7636 的 S256 方式校验 code_verifier
+func verifyCodeChallenge(challenge, method, verifier string) bool {
+	if challenge == "" {
+		return true
+	}
+	if method != CodeChallengeS256 || verifier == "" {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(base64.RawURLEncoding.EncodeToString(sum[:]))) == 1
+}
+
+// randomToken 生成 256 位的随机字符串
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
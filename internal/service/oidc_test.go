@@ -0,0 +1,420 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/domain"
+	repomocks "github.com/coderlewin/ucenter/internal/repository/mocks"
+	svcmocks "github.com/coderlewin/ucenter/internal/service/mocks"
+	"github.com/coderlewin/ucenter/pkg/hasher"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID     = "client-1"
+	testClientSecret = "client-secret"
+	testRedirectURI  = "https://app.example.com/callback"
+	testCodeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+// testCodeChallenge RFC 7636 附录 B 中 testCodeVerifier 对应的 S256 code_challenge
+const testCodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+type oidcTestDeps struct {
+	clientRepo *repomocks.MockOAuthClientRepository
+	userSvc    *svcmocks.MockUserService
+	jwtSvc     *svcmocks.MockJWTService
+	// clients 已注册的客户端, 按 client_id 查找, 删除后查询返回记录不存在
+	clients map[string]domain.OAuthClient
+}
+
+func newTestOIDCService(t *testing.T, ctrl *gomock.Controller, requirePKCE bool) (OIDCService, oidcTestDeps) {
+	pwdHasher := hasher.NewPasswordHasher(hasher.NewBcryptScheme(4))
+	secret, err := pwdHasher.Hash(testClientSecret)
+	require.NoError(t, err)
+
+	deps := oidcTestDeps{
+		clientRepo: repomocks.NewMockOAuthClientRepository(ctrl),
+		userSvc:    svcmocks.NewMockUserService(ctrl),
+		jwtSvc:     svcmocks.NewMockJWTService(ctrl),
+		clients: map[string]domain.OAuthClient{
+			testClientID: {
+				ID:           1,
+				ClientID:     testClientID,
+				ClientSecret: secret,
+				RedirectURIs: []string{testRedirectURI},
+			},
+		},
+	}
+	deps.clientRepo.EXPECT().FindByClientID(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, clientID string) (domain.OAuthClient, error) {
+			client, ok := deps.clients[clientID]
+			if !ok {
+				return domain.OAuthClient{}, gorm.ErrRecordNotFound
+			}
+			return client, nil
+		}).AnyTimes()
+
+	svc := NewOIDCService(OIDCOptions{
+		Issuer:                "https://uc.example.com",
+		CodeExpiration:        time.Minute,
+		AccessTokenExpiration: time.Hour,
+		IDTokenExpiration:     time.Hour,
+		RequirePKCE:           requirePKCE,
+	}, redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}), deps.clientRepo, deps.userSvc, deps.jwtSvc, pwdHasher)
+	return svc, deps
+}
+
+func validAuthorizeRequest() domain.AuthorizeRequest {
+	return domain.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            testClientID,
+		RedirectURI:         testRedirectURI,
+		Scope:               []string{ScopeOpenID, ScopeProfile},
+		Nonce:               "nonce-1",
+		CodeChallenge:       testCodeChallenge,
+		CodeChallengeMethod: CodeChallengeS256,
+	}
+}
+
+// oauthErrorCode 返回 OAuthError 的错误码, 其他错误返回空字符串
+func oauthErrorCode(err error) string {
+	var oauthErr *OAuthError
+	if errors.As(err, &oauthErr) {
+		return oauthErr.Code
+	}
+	return ""
+}
+
+func Test_verifyCodeChallenge(t *testing.T) {
+	sum := sha256.Sum256([]byte(testCodeVerifier))
+	assert.Equal(t, testCodeChallenge, base64.RawURLEncoding.EncodeToString(sum[:]))
+
+	assert.True(t, verifyCodeChallenge(testCodeChallenge, CodeChallengeS256, testCodeVerifier))
+	assert.False(t, verifyCodeChallenge(testCodeChallenge, CodeChallengeS256, "wrong-verifier"))
+	assert.False(t, verifyCodeChallenge(testCodeChallenge, CodeChallengeS256, ""))
+	// plain 方式不再接受, 即使 verifier 与 challenge 相同
+	assert.False(t, verifyCodeChallenge(testCodeChallenge, "plain", testCodeChallenge))
+	// 授权时没有使用 PKCE
+	assert.True(t, verifyCodeChallenge("", "", ""))
+}
+
+func Test_oidcService_Authorize(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		requirePKCE bool
+		req         func() domain.AuthorizeRequest
+
+		// 预期中的输出, 为空表示成功
+		wantCode string
+	}{
+		{
+			name: "客户端不存在",
+			req: func() domain.AuthorizeRequest {
+				req := validAuthorizeRequest()
+				req.ClientID = "unknown"
+				return req
+			},
+			wantCode: "invalid_client",
+		},
+		{
+			name: "回调地址未登记",
+			req: func() domain.AuthorizeRequest {
+				req := validAuthorizeRequest()
+				req.RedirectURI = "https://app.example.com/callback/other"
+				return req
+			},
+			wantCode: "invalid_request",
+		},
+		{
+			name: "回调地址前缀匹配也不允许",
+			req: func() domain.AuthorizeRequest {
+				req := validAuthorizeRequest()
+				req.RedirectURI = testRedirectURI + "?next=https://evil.example.com"
+				return req
+			},
+			wantCode: "invalid_request",
+		},
+		{
+			name: "不支持的 response_type",
+			req: func() domain.AuthorizeRequest {
+				req := validAuthorizeRequest()
+				req.ResponseType = "token"
+				return req
+			},
+			wantCode: "unsupported_response_type",
+		},
+		{
+			name: "scope 缺少 openid",
+			req: func() domain.AuthorizeRequest {
+				req := validAuthorizeRequest()
+				req.Scope = []string{ScopeProfile}
+				return req
+			},
+			wantCode: "invalid_scope",
+		},
+		{
+			name:        "强制 PKCE 时缺少 code_challenge",
+			requirePKCE: true,
+			req: func() domain.AuthorizeRequest {
+				req := validAuthorizeRequest()
+				req.CodeChallenge, req.CodeChallengeMethod = "", ""
+				return req
+			},
+			wantCode: "invalid_request",
+		},
+		{
+			name:        "plain 方式不被接受",
+			requirePKCE: true,
+			req: func() domain.AuthorizeRequest {
+				req := validAuthorizeRequest()
+				req.CodeChallengeMethod = "plain"
+				return req
+			},
+			wantCode: "invalid_request",
+		},
+		{
+			name: "未指定 code_challenge_method 不会默认为 plain",
+			req: func() domain.AuthorizeRequest {
+				req := validAuthorizeRequest()
+				req.CodeChallengeMethod = ""
+				return req
+			},
+			wantCode: "invalid_request",
+		},
+		{
+			name:        "省略唯一登记的回调地址",
+			requirePKCE: true,
+			req: func() domain.AuthorizeRequest {
+				req := validAuthorizeRequest()
+				req.RedirectURI = ""
+				return req
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, _ := newTestOIDCService(t, ctrl, tc.requirePKCE)
+			code, err := svc.Authorize(context.Background(), 1, tc.req())
+			assert.Equal(t, tc.wantCode, oauthErrorCode(err))
+			if tc.wantCode == "" {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, code)
+			}
+		})
+	}
+}
+
+func Test_oidcService_Exchange(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(deps oidcTestDeps)
+		// 修改默认的令牌请求
+		modify func(req *domain.TokenRequest)
+
+		// 预期中的输出, 为空表示成功
+		wantCode string
+	}{
+		{
+			name: "不支持的 grant_type",
+			mock: func(deps oidcTestDeps) {},
+			modify: func(req *domain.TokenRequest) {
+				req.GrantType = "password"
+			},
+			wantCode: "unsupported_grant_type",
+		},
+		{
+			name: "客户端密钥错误",
+			mock: func(deps oidcTestDeps) {},
+			modify: func(req *domain.TokenRequest) {
+				req.ClientSecret = "wrong-secret"
+			},
+			wantCode: "invalid_client",
+		},
+		{
+			name: "授权码不存在",
+			mock: func(deps oidcTestDeps) {},
+			modify: func(req *domain.TokenRequest) {
+				req.Code = "unknown"
+			},
+			wantCode: "invalid_grant",
+		},
+		{
+			name: "回调地址与授权时不一致",
+			mock: func(deps oidcTestDeps) {},
+			modify: func(req *domain.TokenRequest) {
+				req.RedirectURI = "https://app.example.com/other"
+			},
+			wantCode: "invalid_grant",
+		},
+		{
+			name: "code_verifier 错误",
+			mock: func(deps oidcTestDeps) {},
+			modify: func(req *domain.TokenRequest) {
+				req.CodeVerifier = "wrong-verifier"
+			},
+			wantCode: "invalid_grant",
+		},
+		{
+			name: "缺少 code_verifier",
+			mock: func(deps oidcTestDeps) {},
+			modify: func(req *domain.TokenRequest) {
+				req.CodeVerifier = ""
+			},
+			wantCode: "invalid_grant",
+		},
+		{
+			name: "用户已被冻结",
+			mock: func(deps oidcTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(domain.User{
+					ID:         1,
+					UserStatus: constants.UserStatusDisabled,
+				}, nil)
+			},
+			modify:   func(req *domain.TokenRequest) {},
+			wantCode: "invalid_grant",
+		},
+		{
+			name: "换取成功",
+			mock: func(deps oidcTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(domain.User{ID: 1}, nil).Times(2)
+				deps.jwtSvc.EXPECT().SignIDToken(gomock.Any()).Return("id-token", nil)
+			},
+			modify: func(req *domain.TokenRequest) {},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, deps := newTestOIDCService(t, ctrl, true)
+			tc.mock(deps)
+
+			code, err := svc.Authorize(context.Background(), 1, validAuthorizeRequest())
+			require.NoError(t, err)
+			req := domain.TokenRequest{
+				GrantType:    "authorization_code",
+				Code:         code,
+				RedirectURI:  testRedirectURI,
+				ClientID:     testClientID,
+				ClientSecret: testClientSecret,
+				CodeVerifier: testCodeVerifier,
+			}
+			tc.modify(&req)
+
+			res, err := svc.Exchange(context.Background(), req)
+			assert.Equal(t, tc.wantCode, oauthErrorCode(err))
+			if tc.wantCode != "" {
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Bearer", res.TokenType)
+			assert.Equal(t, "id-token", res.IDToken)
+			assert.Equal(t, []string{ScopeOpenID, ScopeProfile}, res.Scope)
+
+			// access token 可以获取用户信息
+			user, scopes, err := svc.UserInfo(context.Background(), res.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), user.ID)
+			assert.Equal(t, res.Scope, scopes)
+
+			// 授权码只能使用一次
+			_, err = svc.Exchange(context.Background(), req)
+			assert.Equal(t, "invalid_grant", oauthErrorCode(err))
+		})
+	}
+}
+
+func Test_oidcService_UserInfo_InvalidToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, _ := newTestOIDCService(t, ctrl, true)
+	_, _, err := svc.UserInfo(context.Background(), "unknown")
+	assert.Equal(t, "invalid_token", oauthErrorCode(err))
+}
+
+func Test_oidcService_UserInfo(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		// 换取 access token 之后执行
+		mock func(deps oidcTestDeps)
+
+		wantCode string
+	}{
+		{
+			name: "获取成功",
+			mock: func(deps oidcTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).
+					Return(domain.User{ID: 1, SecurityVersion: 1}, nil)
+			},
+		},
+		{
+			name: "修改密码后失效",
+			mock: func(deps oidcTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).
+					Return(domain.User{ID: 1, SecurityVersion: 2}, nil)
+			},
+			wantCode: "invalid_token",
+		},
+		{
+			name: "用户已被冻结",
+			mock: func(deps oidcTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).
+					Return(domain.User{ID: 1, SecurityVersion: 1, UserStatus: constants.UserStatusDisabled}, nil)
+			},
+			wantCode: "invalid_token",
+		},
+		{
+			name: "客户端已被删除",
+			mock: func(deps oidcTestDeps) {
+				delete(deps.clients, testClientID)
+			},
+			wantCode: "invalid_token",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, deps := newTestOIDCService(t, ctrl, true)
+			deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).
+				Return(domain.User{ID: 1, SecurityVersion: 1}, nil)
+			deps.jwtSvc.EXPECT().SignIDToken(gomock.Any()).Return("id-token", nil)
+
+			code, err := svc.Authorize(context.Background(), 1, validAuthorizeRequest())
+			require.NoError(t, err)
+			res, err := svc.Exchange(context.Background(), domain.TokenRequest{
+				GrantType:    "authorization_code",
+				Code:         code,
+				RedirectURI:  testRedirectURI,
+				ClientID:     testClientID,
+				ClientSecret: testClientSecret,
+				CodeVerifier: testCodeVerifier,
+			})
+			require.NoError(t, err)
+			tc.mock(deps)
+
+			user, _, err := svc.UserInfo(context.Background(), res.AccessToken)
+			assert.Equal(t, tc.wantCode, oauthErrorCode(err))
+			if tc.wantCode == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(1), user.ID)
+			}
+		})
+	}
+}
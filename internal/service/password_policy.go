@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/repository"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/hasher"
+	"github.com/coderlewin/ucenter/pkg/pwdpolicy"
+)
+
+type PasswordPolicyOptions struct {
+	Policy *pwdpolicy.Policy
+	// HistorySize 新密码不能与最近使用过的多少个密码相同, 为 0 时不检查
+	HistorySize int
+}
+
+//go:generate mockgen -source=./password_policy.go -package=svcmocks -destination=./mocks/password_policy.mock.go PasswordPolicyService
+type PasswordPolicyService interface {
+	// Validate 校验 user 的新密码 password 是否符合密码策略.
+	// user.ID 大于 0 时, 还会检查是否与当前密码和历史密码重复
+	Validate(ctx context.Context, user domain.User, password string) error
+	// Check 与 Validate 相同, 但不符合时返回原因而不是共享的错误变量, 可以在后台任务中并发调用
+	Check(ctx context.Context, user domain.User, password string) (string, error)
+	// Record 记录用户设置的新密码哈希, 用于防止重复使用
+	Record(ctx context.Context, uid int64, hash string) error
+}
+
+func NewPasswordPolicyService(opts PasswordPolicyOptions, historyRepo repository.PasswordHistoryRepository,
+	pwdHasher hasher.PasswordHasher) PasswordPolicyService {
+	return &passwordPolicyService{opts: opts, historyRepo: historyRepo, pwdHasher: pwdHasher}
+}
+
+type passwordPolicyService struct {
+	opts        PasswordPolicyOptions
+	historyRepo repository.PasswordHistoryRepository
+	pwdHasher   hasher.PasswordHasher
+}
+
+func (p *passwordPolicyService) Validate(ctx context.Context, user domain.User, password string) error {
+	reason, err := p.Check(ctx, user, password)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		return errno.ErrParameterInvalid.SetDescription(reason)
+	}
+	return nil
+}
+
+func (p *passwordPolicyService) Check(ctx context.Context, user domain.User, password string) (string, error) {
+	if reason := p.opts.Policy.Check(password, user.UserAccount); reason != "" {
+		return reason, nil
+	}
+	if user.ID <= 0 || p.opts.HistorySize <= 0 {
+		return "", nil
+	}
+
+	hashes, err := p.historyRepo.ListRecent(ctx, user.ID, p.opts.HistorySize)
+	if err != nil {
+		return "", errno.ErrDBFailed
+	}
+	// 启用历史密码之前设置的密码不在历史记录中
+	if user.UserPassword != "" {
+		hashes = append(hashes, user.UserPassword)
+	}
+	for _, hash := range hashes {
+		ok, err := p.pwdHasher.Verify(password, hash)
+		if err != nil {
+			continue
+		}
+		if ok {
+			return fmt.Sprintf("不能使用最近 %d 次使用过的密码", p.opts.HistorySize), nil
+		}
+	}
+	return "", nil
+}
+
+func (p *passwordPolicyService) Record(ctx context.Context, uid int64, hash string) error {
+	if p.opts.HistorySize <= 0 {
+		return nil
+	}
+	return p.historyRepo.Add(ctx, uid, hash, p.opts.HistorySize)
+}
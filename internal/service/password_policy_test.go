@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/coderlewin/ucenter/internal/domain"
+	repomocks "github.com/coderlewin/ucenter/internal/repository/mocks"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/hasher"
+	"github.com/coderlewin/ucenter/pkg/pwdpolicy"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_passwordPolicyService_Validate(t *testing.T) {
+	pwdHasher := hasher.NewPasswordHasher(hasher.NewBcryptScheme(4))
+	hash := func(plain string) string {
+		encoded, err := pwdHasher.Hash(plain)
+		require.NoError(t, err)
+		return encoded
+	}
+	current := hash("kite7-mango-vault")
+	recent := []string{hash("river9-lemon-tower"), hash("cloud3-pepper-gate")}
+	policy, err := pwdpolicy.New(pwdpolicy.Options{MinLength: 8, MaxLength: hasher.BcryptMaxPasswordBytes})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+
+		mock        func(historyRepo *repomocks.MockPasswordHistoryRepository)
+		historySize int
+		user        domain.User
+		password    string
+
+		wantErr  error
+		wantDesc string
+	}{
+		{
+			name:     "不符合密码策略时不查询历史密码",
+			mock:     func(historyRepo *repomocks.MockPasswordHistoryRepository) {},
+			user:     domain.User{ID: 1},
+			password: "short",
+			wantErr:  errno.ErrParameterInvalid,
+			wantDesc: "密码长度不能少于 8 位",
+		},
+		{
+			name:        "新用户不检查历史密码",
+			mock:        func(historyRepo *repomocks.MockPasswordHistoryRepository) {},
+			historySize: 3,
+			password:    "river9-lemon-tower",
+		},
+		{
+			name:     "未开启历史密码检查",
+			mock:     func(historyRepo *repomocks.MockPasswordHistoryRepository) {},
+			user:     domain.User{ID: 1, UserPassword: current},
+			password: "kite7-mango-vault",
+		},
+		{
+			name: "与历史密码重复",
+			mock: func(historyRepo *repomocks.MockPasswordHistoryRepository) {
+				historyRepo.EXPECT().ListRecent(gomock.Any(), int64(1), 3).Return(recent, nil)
+			},
+			historySize: 3,
+			user:        domain.User{ID: 1, UserPassword: current},
+			password:    "cloud3-pepper-gate",
+			wantErr:     errno.ErrParameterInvalid,
+			wantDesc:    "不能使用最近 3 次使用过的密码",
+		},
+		{
+			name: "与当前密码重复, 当前密码不在历史记录中",
+			mock: func(historyRepo *repomocks.MockPasswordHistoryRepository) {
+				historyRepo.EXPECT().ListRecent(gomock.Any(), int64(1), 3).Return(nil, nil)
+			},
+			historySize: 3,
+			user:        domain.User{ID: 1, UserPassword: current},
+			password:    "kite7-mango-vault",
+			wantErr:     errno.ErrParameterInvalid,
+			wantDesc:    "不能使用最近 3 次使用过的密码",
+		},
+		{
+			name: "历史记录中的哈希损坏时跳过",
+			mock: func(historyRepo *repomocks.MockPasswordHistoryRepository) {
+				historyRepo.EXPECT().ListRecent(gomock.Any(), int64(1), 3).Return([]string{"broken"}, nil)
+			},
+			historySize: 3,
+			user:        domain.User{ID: 1, UserPassword: current},
+			password:    "sun4-olive-bridge",
+		},
+		{
+			name: "查询历史密码失败",
+			mock: func(historyRepo *repomocks.MockPasswordHistoryRepository) {
+				historyRepo.EXPECT().ListRecent(gomock.Any(), int64(1), 3).Return(nil, errors.New("db error"))
+			},
+			historySize: 3,
+			user:        domain.User{ID: 1},
+			password:    "sun4-olive-bridge",
+			wantErr:     errno.ErrDBFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			historyRepo := repomocks.NewMockPasswordHistoryRepository(ctrl)
+			tc.mock(historyRepo)
+			svc := NewPasswordPolicyService(PasswordPolicyOptions{Policy: policy, HistorySize: tc.historySize}, historyRepo, pwdHasher)
+
+			err := svc.Validate(context.Background(), tc.user, tc.password)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantDesc != "" {
+				assert.Equal(t, tc.wantDesc, errno.ErrParameterInvalid.Desc)
+			}
+		})
+	}
+}
+
+func Test_passwordPolicyService_Record(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	historyRepo := repomocks.NewMockPasswordHistoryRepository(ctrl)
+	policy, err := pwdpolicy.New(pwdpolicy.Options{MinLength: 8})
+	require.NoError(t, err)
+
+	// 未开启历史密码检查时不记录
+	svc := NewPasswordPolicyService(PasswordPolicyOptions{Policy: policy}, historyRepo, nil)
+	require.NoError(t, svc.Record(context.Background(), 1, "hash"))
+
+	historyRepo.EXPECT().Add(gomock.Any(), int64(1), "hash", 5).Return(nil)
+	svc = NewPasswordPolicyService(PasswordPolicyOptions{Policy: policy, HistorySize: 5}, historyRepo, nil)
+	require.NoError(t, svc.Record(context.Background(), 1, "hash"))
+}
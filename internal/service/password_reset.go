@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/repository"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/hasher"
+	"github.com/coderlewin/ucenter/pkg/mail"
+	"github.com/duke-git/lancet/v2/validator"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PasswordResetOptions 定义重置密码的选项
+type PasswordResetOptions struct {
+	// URL 重置密码页面的地址, %s 会被替换为重置 token
+	URL            string
+	Expiration     time.Duration // 重置链接的有效期
+	ResendInterval time.Duration // 同一用户两次发送重置邮件的最小间隔
+}
+
+//go:generate mockgen -source=./password_reset.go -package=svcmocks -destination=./mocks/password_reset.mock.go PasswordResetService
+type PasswordResetService interface {
+	// SendResetEmail 向邮箱发送重置密码链接, 邮箱未注册时同样返回成功, 避免泄露用户是否存在
+	SendResetEmail(ctx context.Context, email string) error
+	// Reset 使用重置 token 设置新密码, 并吊销该用户的全部登录会话
+	Reset(ctx context.Context, token string, password string, checkPassword string) error
+}
+
+func NewPasswordResetService(opts PasswordResetOptions, cmd redis.Cmdable, userRepo repository.UserRepository,
+	pwdHasher hasher.PasswordHasher, mailer mail.Mailer, sessionSvc SessionService,
+	loginStateSvc LoginStateService, pwdPolicySvc PasswordPolicyService) PasswordResetService {
+	return &passwordResetService{
+		opts:          opts,
+		cmd:           cmd,
+		userRepo:      userRepo,
+		pwdHasher:     pwdHasher,
+		mailer:        mailer,
+		sessionSvc:    sessionSvc,
+		loginStateSvc: loginStateSvc,
+		pwdPolicySvc:  pwdPolicySvc,
+	}
+}
+
+type passwordResetService struct {
+	opts          PasswordResetOptions
+	cmd           redis.Cmdable
+	userRepo      repository.UserRepository
+	pwdHasher     hasher.PasswordHasher
+	mailer        mail.Mailer
+	sessionSvc    SessionService
+	loginStateSvc LoginStateService
+	pwdPolicySvc  PasswordPolicyService
+}
+
+func (p *passwordResetService) SendResetEmail(ctx context.Context, email string) error {
+	email = strings.TrimSpace(email)
+	if !validator.IsEmail(email) {
+		return errno.ErrParameterInvalid.SetDescription("邮箱格式错误")
+	}
+	user, err := p.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if errors.Is(err, repository.ErrAmbiguousEmail) {
+			// 无法确定重置哪个账号, 不发送邮件, 同样静默返回
+			hlog.CtxWarnf(ctx, "email is used by multiple users, skip sending reset email")
+			return nil
+		}
+		return errno.ErrDBFailed
+	}
+
+	// 限制发送频率, 频率过高时同样静默返回
+	ok, err := p.cmd.SetNX(ctx, p.throttleKey(user.ID), 1, p.opts.ResendInterval).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	hashed := p.hash(token)
+	// 新的链接生效后, 之前发送的链接全部失效
+	old, err := p.cmd.GetSet(ctx, p.userKey(user.ID), hashed).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if old != "" {
+		if err = p.cmd.Del(ctx, p.tokenKey(old)).Err(); err != nil {
+			return err
+		}
+	}
+	if err = p.cmd.Expire(ctx, p.userKey(user.ID), p.opts.Expiration).Err(); err != nil {
+		return err
+	}
+	if err = p.cmd.Set(ctx, p.tokenKey(hashed), user.ID, p.opts.Expiration).Err(); err != nil {
+		return err
+	}
+
+	err = p.mailer.Send(ctx, mail.Message{
+		To:      []string{user.Email},
+		Subject: "重置密码",
+		Body: fmt.Sprintf("您好 %s:\n\n请在 %d 分钟内访问以下链接重置密码:\n%s\n\n如果这不是您本人的操作, 请忽略本邮件.\n",
+			user.Username, int(p.opts.Expiration.Minutes()), fmt.Sprintf(p.opts.URL, token)),
+	})
+	if err != nil {
+		// 发送失败时允许立即重试
+		p.cmd.Del(ctx, p.throttleKey(user.ID))
+		hlog.CtxErrorf(ctx, "send reset password email failed, uid=%d, err=%v", user.ID, err)
+		return err
+	}
+	return nil
+}
+
+func (p *passwordResetService) Reset(ctx context.Context, token string, password string, checkPassword string) error {
+	// 先校验密码, 避免输错密码时 token 被消耗
+	ud := domain.User{UserPassword: password, CheckPassword: checkPassword}
+	if err := ud.ValidatePassword(); err != nil {
+		return err
+	}
+
+	hashed := p.hash(token)
+	val, err := p.cmd.Get(ctx, p.tokenKey(hashed)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return errno.ErrParameterInvalid.SetDescription("重置链接无效或已过期")
+		}
+		return err
+	}
+	uid, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	// 新密码不符合密码策略时同样不消耗 token
+	user, err := p.userRepo.GetOneById(ctx, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errno.ErrParameterInvalid.SetDescription("重置链接无效或已过期")
+		}
+		return errno.ErrDBFailed
+	}
+	if err = p.pwdPolicySvc.Validate(ctx, user, password); err != nil {
+		return err
+	}
+
+	// 取出即删除, 每个 token 只能使用一次
+	if err = p.cmd.GetDel(ctx, p.tokenKey(hashed)).Err(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return errno.ErrParameterInvalid.SetDescription("重置链接无效或已过期")
+		}
+		return err
+	}
+	if err = p.cmd.Del(ctx, p.userKey(uid)).Err(); err != nil {
+		return err
+	}
+
+	if err = ud.EncryptPassword(p.pwdHasher); err != nil {
+		return err
+	}
+	if err = p.userRepo.UpdatePassword(ctx, uid, ud.UserPassword); err != nil {
+		return errno.ErrDBFailed
+	}
+	if err = p.pwdPolicySvc.Record(ctx, uid, ud.UserPassword); err != nil {
+		hlog.CtxWarnf(ctx, "record password history failed, uid=%d, err=%v", uid, err)
+	}
+	// 安全版本已经递增, 清除缓存使其立即生效
+	if err = p.loginStateSvc.Invalidate(ctx, uid); err != nil {
+		return err
+	}
+	// 密码可能已经泄露, 已登录的设备全部下线
+	return p.sessionSvc.RevokeAll(ctx, uid)
+}
+
+func (p *passwordResetService) hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (p *passwordResetService) tokenKey(hashed string) string {
+	return fmt.Sprintf("ucenter:password_reset:token:%s", hashed)
+}
+
+func (p *passwordResetService) userKey(uid int64) string {
+	return fmt.Sprintf("ucenter:password_reset:user:%d", uid)
+}
+
+func (p *passwordResetService) throttleKey(uid int64) string {
+	return fmt.Sprintf("ucenter:password_reset:throttle:%d", uid)
+}
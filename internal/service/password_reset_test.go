@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/repository"
+	repomocks "github.com/coderlewin/ucenter/internal/repository/mocks"
+	svcmocks "github.com/coderlewin/ucenter/internal/service/mocks"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/hasher"
+	"github.com/coderlewin/ucenter/pkg/mail"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type passwordResetTestDeps struct {
+	userRepo      *repomocks.MockUserRepository
+	sessionSvc    *svcmocks.MockSessionService
+	loginStateSvc *svcmocks.MockLoginStateService
+	pwdPolicySvc  *svcmocks.MockPasswordPolicyService
+	mailer        *mail.MemoryMailer
+	mr            *miniredis.Miniredis
+}
+
+func newTestPasswordResetService(t *testing.T, ctrl *gomock.Controller) (*passwordResetService, passwordResetTestDeps) {
+	deps := passwordResetTestDeps{
+		userRepo:      repomocks.NewMockUserRepository(ctrl),
+		sessionSvc:    svcmocks.NewMockSessionService(ctrl),
+		loginStateSvc: svcmocks.NewMockLoginStateService(ctrl),
+		pwdPolicySvc:  svcmocks.NewMockPasswordPolicyService(ctrl),
+		mailer:        mail.NewMemoryMailer(),
+		mr:            miniredis.RunT(t),
+	}
+	svc := NewPasswordResetService(PasswordResetOptions{
+		URL:            "https://uc.example.com/reset?token=%s",
+		Expiration:     30 * time.Minute,
+		ResendInterval: time.Minute,
+	}, redis.NewClient(&redis.Options{Addr: deps.mr.Addr()}), deps.userRepo, hasher.NewPasswordHasher(hasher.NewBcryptScheme(4)),
+		deps.mailer, deps.sessionSvc, deps.loginStateSvc, deps.pwdPolicySvc)
+	return svc.(*passwordResetService), deps
+}
+
+var mailTokenRegexp = regexp.MustCompile(`token=(\S+)`)
+
+// lastMailToken 返回最后一封邮件链接中的 token
+func lastMailToken(t *testing.T, mailer *mail.MemoryMailer) string {
+	msgs := mailer.Messages()
+	require.NotEmpty(t, msgs)
+	m := mailTokenRegexp.FindStringSubmatch(msgs[len(msgs)-1].Body)
+	require.Len(t, m, 2)
+	return m[1]
+}
+
+func Test_passwordResetService_SendResetEmail(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock  func(userRepo *repomocks.MockUserRepository)
+		email string
+
+		wantErr  error
+		wantSent bool
+	}{
+		{
+			name:    "邮箱格式错误",
+			mock:    func(userRepo *repomocks.MockUserRepository) {},
+			email:   "lewin",
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name: "邮箱未注册, 静默返回",
+			mock: func(userRepo *repomocks.MockUserRepository) {
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "none@example.com").Return(domain.User{}, gorm.ErrRecordNotFound)
+			},
+			email: " none@example.com ",
+		},
+		{
+			name: "邮箱被多个用户使用, 不发送邮件",
+			mock: func(userRepo *repomocks.MockUserRepository) {
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "dup@example.com").Return(domain.User{}, repository.ErrAmbiguousEmail)
+			},
+			email: "dup@example.com",
+		},
+		{
+			name: "发送成功",
+			mock: func(userRepo *repomocks.MockUserRepository) {
+				userRepo.EXPECT().FindByEmail(gomock.Any(), "lewin@example.com").
+					Return(domain.User{ID: 1, Username: "Lewin", Email: "lewin@example.com"}, nil)
+			},
+			email:    "lewin@example.com",
+			wantSent: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, deps := newTestPasswordResetService(t, ctrl)
+			tc.mock(deps.userRepo)
+
+			err := svc.SendResetEmail(context.Background(), tc.email)
+			assert.Equal(t, tc.wantErr, err)
+			msgs := deps.mailer.Messages()
+			if !tc.wantSent {
+				assert.Empty(t, msgs)
+				return
+			}
+			require.Len(t, msgs, 1)
+			assert.Equal(t, []string{"lewin@example.com"}, msgs[0].To)
+			// 只保存 token 的哈希
+			token := lastMailToken(t, deps.mailer)
+			assert.False(t, deps.mr.Exists(svc.tokenKey(token)))
+			assert.True(t, deps.mr.Exists(svc.tokenKey(svc.hash(token))))
+		})
+	}
+}
+
+func Test_passwordResetService_SendResetEmail_Throttle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, deps := newTestPasswordResetService(t, ctrl)
+	deps.userRepo.EXPECT().FindByEmail(gomock.Any(), "lewin@example.com").
+		Return(domain.User{ID: 1, Email: "lewin@example.com"}, nil).Times(3)
+	ctx := context.Background()
+
+	require.NoError(t, svc.SendResetEmail(ctx, "lewin@example.com"))
+	first := lastMailToken(t, deps.mailer)
+	// 发送间隔内再次请求, 静默返回不发送
+	require.NoError(t, svc.SendResetEmail(ctx, "lewin@example.com"))
+	assert.Len(t, deps.mailer.Messages(), 1)
+
+	// 间隔过后重新发送, 之前的链接失效
+	deps.mr.FastForward(time.Minute + time.Second)
+	require.NoError(t, svc.SendResetEmail(ctx, "lewin@example.com"))
+	assert.Len(t, deps.mailer.Messages(), 2)
+	assert.False(t, deps.mr.Exists(svc.tokenKey(svc.hash(first))))
+	assert.True(t, deps.mr.Exists(svc.tokenKey(svc.hash(lastMailToken(t, deps.mailer)))))
+}
+
+func Test_passwordResetService_Reset(t *testing.T) {
+	user := domain.User{ID: 1, UserAccount: "lewin", Email: "lewin@example.com"}
+	testCases := []struct {
+		name string
+
+		mock func(deps passwordResetTestDeps)
+		// 提交的 token, 为 nil 时使用邮件中的 token
+		token         func(token string) string
+		password      string
+		checkPassword string
+
+		wantErr error
+		// token 是否仍然可以使用
+		wantTokenValid bool
+	}{
+		{
+			name:           "两次密码不一致, 不消耗 token",
+			mock:           func(deps passwordResetTestDeps) {},
+			password:       "Abcd1234!",
+			checkPassword:  "Abcd1234?",
+			wantErr:        errno.ErrParameterInvalid,
+			wantTokenValid: true,
+		},
+		{
+			name: "token 无效",
+			mock: func(deps passwordResetTestDeps) {},
+			token: func(token string) string {
+				return "unknown"
+			},
+			password:       "Abcd1234!",
+			checkPassword:  "Abcd1234!",
+			wantErr:        errno.ErrParameterInvalid,
+			wantTokenValid: true,
+		},
+		{
+			name: "不符合密码策略, 不消耗 token",
+			mock: func(deps passwordResetTestDeps) {
+				deps.userRepo.EXPECT().GetOneById(gomock.Any(), int64(1)).Return(user, nil)
+				deps.pwdPolicySvc.EXPECT().Validate(gomock.Any(), user, "Abcd1234!").
+					Return(errno.ErrParameterInvalid.SetDescription("不能使用最近使用过的密码"))
+			},
+			password:       "Abcd1234!",
+			checkPassword:  "Abcd1234!",
+			wantErr:        errno.ErrParameterInvalid,
+			wantTokenValid: true,
+		},
+		{
+			name: "重置成功, 吊销全部会话",
+			mock: func(deps passwordResetTestDeps) {
+				deps.userRepo.EXPECT().GetOneById(gomock.Any(), int64(1)).Return(user, nil)
+				deps.pwdPolicySvc.EXPECT().Validate(gomock.Any(), user, "Abcd1234!").Return(nil)
+				deps.userRepo.EXPECT().UpdatePassword(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				deps.pwdPolicySvc.EXPECT().Record(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				deps.loginStateSvc.EXPECT().Invalidate(gomock.Any(), int64(1)).Return(nil)
+				deps.sessionSvc.EXPECT().RevokeAll(gomock.Any(), int64(1)).Return(nil)
+			},
+			password:      "Abcd1234!",
+			checkPassword: "Abcd1234!",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, deps := newTestPasswordResetService(t, ctrl)
+			deps.userRepo.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(user, nil)
+			require.NoError(t, svc.SendResetEmail(context.Background(), user.Email))
+			token := lastMailToken(t, deps.mailer)
+			tc.mock(deps)
+
+			submitted := token
+			if tc.token != nil {
+				submitted = tc.token(token)
+			}
+			err := svc.Reset(context.Background(), submitted, tc.password, tc.checkPassword)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantTokenValid, deps.mr.Exists(svc.tokenKey(svc.hash(token))))
+			if tc.wantErr != nil {
+				return
+			}
+
+			// token 只能使用一次
+			err = svc.Reset(context.Background(), token, tc.password, tc.checkPassword)
+			assert.Equal(t, errno.ErrParameterInvalid, err)
+			assert.Equal(t, "重置链接无效或已过期", errno.ErrParameterInvalid.Desc)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/repository"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/sms"
+)
+
+//go:generate mockgen -source=./profile.go -package=svcmocks -destination=./mocks/profile.mock.go ProfileService
+type ProfileService interface {
+	// UpdateProfile 用户修改自己的资料, 只修改 profile 中不为 nil 的字段, 返回修改后的用户.
+	// 修改手机号需要提供发送到新手机号的验证码; 修改邮箱不会立即生效, 而是向新邮箱发送确认链接,
+	// pendingEmail 为等待确认的新邮箱
+	UpdateProfile(ctx context.Context, uid int64, profile domain.UserProfile) (user domain.User, pendingEmail string, err error)
+	// ConfirmEmailChange 校验修改邮箱的确认链接并修改邮箱
+	ConfirmEmailChange(ctx context.Context, token string) error
+	// ForceUpdateProfile 修改资料, 手机号和邮箱不需要验证, 只用于管理员修改
+	ForceUpdateProfile(ctx context.Context, uid int64, profile domain.UserProfile) (domain.User, error)
+}
+
+// NewProfileService 手机号的规范化与短信登录使用同样的默认国家码
+func NewProfileService(userRepo repository.UserRepository, userSvc UserService, smsOpts SMSOptions, smsSvc SMSService,
+	emailVerifySvc EmailVerificationService, loginStateSvc LoginStateService) ProfileService {
+	return &profileService{
+		userRepo:           userRepo,
+		userSvc:            userSvc,
+		smsSvc:             smsSvc,
+		emailVerifySvc:     emailVerifySvc,
+		loginStateSvc:      loginStateSvc,
+		defaultCountryCode: smsOpts.DefaultCountryCode,
+	}
+}
+
+type profileService struct {
+	userRepo           repository.UserRepository
+	userSvc            UserService
+	smsSvc             SMSService
+	emailVerifySvc     EmailVerificationService
+	loginStateSvc      LoginStateService
+	defaultCountryCode string
+}
+
+func (p *profileService) UpdateProfile(ctx context.Context, uid int64,
+	profile domain.UserProfile) (domain.User, string, error) {
+	user, err := p.prepare(ctx, uid, &profile)
+	if err != nil {
+		return domain.User{}, "", err
+	}
+
+	// 确认用户持有新手机号
+	if profile.Phone != nil && *profile.Phone != "" {
+		if profile.PhoneCode == "" {
+			return domain.User{}, "", errno.ErrParameterInvalid.SetDescription("请填写发送到新手机号的验证码")
+		}
+		if _, err = p.smsSvc.VerifyBindCode(ctx, *profile.Phone, profile.PhoneCode); err != nil {
+			return domain.User{}, "", err
+		}
+	}
+
+	// 新邮箱在用户访问确认链接后才修改, 解除绑定立即生效
+	var pendingEmail string
+	if profile.Email != nil && *profile.Email != "" {
+		if err = p.emailVerifySvc.SendChangeEmail(ctx, uid, *profile.Email); err != nil {
+			return domain.User{}, "", err
+		}
+		pendingEmail = *profile.Email
+		profile.Email = nil
+	}
+
+	if profile.IsEmpty() {
+		return user, pendingEmail, nil
+	}
+	user, err = p.save(ctx, uid, profile)
+	if err != nil {
+		return domain.User{}, "", err
+	}
+	return user, pendingEmail, nil
+}
+
+func (p *profileService) ConfirmEmailChange(ctx context.Context, token string) error {
+	uid, email, err := p.emailVerifySvc.VerifyChange(ctx, token)
+	if err != nil {
+		return err
+	}
+	// 发送确认链接之后邮箱可能已被其他用户使用
+	count, err := p.userRepo.CountByEmail(ctx, email)
+	if err != nil {
+		return errno.ErrDBFailed
+	}
+	if count > 0 {
+		return errno.ErrEntityExists.SetDescription("邮箱已被注册")
+	}
+	_, err = p.save(ctx, uid, domain.UserProfile{Email: &email})
+	return err
+}
+
+func (p *profileService) ForceUpdateProfile(ctx context.Context, uid int64, profile domain.UserProfile) (domain.User, error) {
+	user, err := p.prepare(ctx, uid, &profile)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if profile.IsEmpty() {
+		return user, nil
+	}
+	return p.save(ctx, uid, profile)
+}
+
+// prepare 校验并规范化 profile, 检查手机号和邮箱是否已被其他用户使用, 返回修改前的用户.
+// 与当前相同的手机号和邮箱会被置为 nil, 不需要再验证
+func (p *profileService) prepare(ctx context.Context, uid int64, profile *domain.UserProfile) (domain.User, error) {
+	if err := profile.Validate(); err != nil {
+		return domain.User{}, err
+	}
+	user, err := p.userSvc.GetCurrentUser(ctx, uid)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if profile.Phone != nil && *profile.Phone != "" {
+		phone, err := sms.NormalizePhone(*profile.Phone, p.defaultCountryCode)
+		if err != nil {
+			return domain.User{}, errno.ErrParameterInvalid.SetDescription("手机号格式错误")
+		}
+		profile.Phone = &phone
+	}
+	if profile.Phone != nil && *profile.Phone == user.Phone {
+		profile.Phone = nil
+	}
+	// 手机号用于短信登录, 不能重复
+	if profile.Phone != nil && *profile.Phone != "" {
+		count, err := p.userRepo.CountByPhone(ctx, *profile.Phone)
+		if err != nil {
+			return domain.User{}, errno.ErrDBFailed
+		}
+		if count > 0 {
+			return domain.User{}, errno.ErrEntityExists.SetDescription("手机号已被使用")
+		}
+	}
+
+	if profile.Email != nil && *profile.Email == user.Email {
+		profile.Email = nil
+	}
+	// 邮箱用于找回密码, 不能重复
+	if profile.Email != nil && *profile.Email != "" {
+		count, err := p.userRepo.CountByEmail(ctx, *profile.Email)
+		if err != nil {
+			return domain.User{}, errno.ErrDBFailed
+		}
+		if count > 0 {
+			return domain.User{}, errno.ErrEntityExists.SetDescription("邮箱已被注册")
+		}
+	}
+	return user, nil
+}
+
+// save 保存资料, 并清除登录态缓存, 返回修改后的用户
+func (p *profileService) save(ctx context.Context, uid int64, profile domain.UserProfile) (domain.User, error) {
+	if err := p.userRepo.UpdateProfile(ctx, uid, profile); err != nil {
+		return domain.User{}, errno.ErrDBFailed
+	}
+	if err := p.loginStateSvc.Invalidate(ctx, uid); err != nil {
+		return domain.User{}, err
+	}
+	return p.userSvc.GetCurrentUser(ctx, uid)
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"github.com/coderlewin/ucenter/internal/domain"
+	repomocks "github.com/coderlewin/ucenter/internal/repository/mocks"
+	svcmocks "github.com/coderlewin/ucenter/internal/service/mocks"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type profileTestDeps struct {
+	userRepo       *repomocks.MockUserRepository
+	userSvc        *svcmocks.MockUserService
+	smsSvc         *svcmocks.MockSMSService
+	emailVerifySvc *svcmocks.MockEmailVerificationService
+	loginStateSvc  *svcmocks.MockLoginStateService
+}
+
+func newTestProfileService(ctrl *gomock.Controller) (ProfileService, profileTestDeps) {
+	deps := profileTestDeps{
+		userRepo:       repomocks.NewMockUserRepository(ctrl),
+		userSvc:        svcmocks.NewMockUserService(ctrl),
+		smsSvc:         svcmocks.NewMockSMSService(ctrl),
+		emailVerifySvc: svcmocks.NewMockEmailVerificationService(ctrl),
+		loginStateSvc:  svcmocks.NewMockLoginStateService(ctrl),
+	}
+	svc := NewProfileService(deps.userRepo, deps.userSvc, SMSOptions{DefaultCountryCode: "86"},
+		deps.smsSvc, deps.emailVerifySvc, deps.loginStateSvc)
+	return svc, deps
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func Test_profileService_UpdateProfile(t *testing.T) {
+	current := domain.User{ID: 1, Username: "Lewin", Phone: "+8613800138000", Email: "lewin@example.com"}
+	// expectSave 保存资料后清除登录态缓存并重新加载用户
+	expectSave := func(deps profileTestDeps, profile domain.UserProfile) {
+		deps.userRepo.EXPECT().UpdateProfile(gomock.Any(), int64(1), profile).Return(nil)
+		deps.loginStateSvc.EXPECT().Invalidate(gomock.Any(), int64(1)).Return(nil)
+		deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+	}
+
+	testCases := []struct {
+		name string
+
+		mock    func(deps profileTestDeps)
+		profile domain.UserProfile
+
+		wantErr          error
+		wantUser         domain.User
+		wantPendingEmail string
+	}{
+		{
+			name:    "没有需要修改的资料",
+			mock:    func(deps profileTestDeps) {},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name: "手机号格式错误",
+			mock: func(deps profileTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+			},
+			profile: domain.UserProfile{Phone: ptr("12345"), PhoneCode: "123456"},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name: "手机号已被使用",
+			mock: func(deps profileTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+				deps.userRepo.EXPECT().CountByPhone(gomock.Any(), "+8613900139000").Return(int64(1), nil)
+			},
+			profile: domain.UserProfile{Phone: ptr("139 0013 9000"), PhoneCode: "123456"},
+			wantErr: errno.ErrEntityExists,
+		},
+		{
+			name: "修改手机号缺少验证码",
+			mock: func(deps profileTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+				deps.userRepo.EXPECT().CountByPhone(gomock.Any(), "+8613900139000").Return(int64(0), nil)
+			},
+			profile: domain.UserProfile{Phone: ptr("13900139000")},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name: "新手机号的验证码错误",
+			mock: func(deps profileTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+				deps.userRepo.EXPECT().CountByPhone(gomock.Any(), "+8613900139000").Return(int64(0), nil)
+				deps.smsSvc.EXPECT().VerifyBindCode(gomock.Any(), "+8613900139000", "000000").
+					Return("", errno.ErrUnauthorization)
+			},
+			profile: domain.UserProfile{Phone: ptr("13900139000"), PhoneCode: "000000"},
+			wantErr: errno.ErrUnauthorization,
+		},
+		{
+			name: "修改手机号, 保存规范化后的号码",
+			mock: func(deps profileTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+				deps.userRepo.EXPECT().CountByPhone(gomock.Any(), "+8613900139000").Return(int64(0), nil)
+				deps.smsSvc.EXPECT().VerifyBindCode(gomock.Any(), "+8613900139000", "123456").Return("+8613900139000", nil)
+				expectSave(deps, domain.UserProfile{Phone: ptr("+8613900139000"), PhoneCode: "123456"})
+			},
+			profile:  domain.UserProfile{Phone: ptr("+86 139-0013-9000"), PhoneCode: "123456"},
+			wantUser: current,
+		},
+		{
+			name: "手机号未变化时不需要验证码",
+			mock: func(deps profileTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+				expectSave(deps, domain.UserProfile{Username: ptr("lewin")})
+			},
+			profile:  domain.UserProfile{Username: ptr(" lewin "), Phone: ptr("138 0013 8000")},
+			wantUser: current,
+		},
+		{
+			name: "解除绑定手机号",
+			mock: func(deps profileTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+				expectSave(deps, domain.UserProfile{Phone: ptr("")})
+			},
+			profile:  domain.UserProfile{Phone: ptr("")},
+			wantUser: current,
+		},
+		{
+			name: "邮箱已被注册",
+			mock: func(deps profileTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+				deps.userRepo.EXPECT().CountByEmail(gomock.Any(), "new@example.com").Return(int64(1), nil)
+			},
+			profile: domain.UserProfile{Email: ptr("new@example.com")},
+			wantErr: errno.ErrEntityExists,
+		},
+		{
+			name: "修改邮箱只发送确认链接",
+			mock: func(deps profileTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+				deps.userRepo.EXPECT().CountByEmail(gomock.Any(), "new@example.com").Return(int64(0), nil)
+				deps.emailVerifySvc.EXPECT().SendChangeEmail(gomock.Any(), int64(1), "new@example.com").Return(nil)
+			},
+			profile:          domain.UserProfile{Email: ptr(" new@example.com ")},
+			wantUser:         current,
+			wantPendingEmail: "new@example.com",
+		},
+		{
+			name: "同时修改昵称和邮箱, 昵称立即生效",
+			mock: func(deps profileTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+				deps.userRepo.EXPECT().CountByEmail(gomock.Any(), "new@example.com").Return(int64(0), nil)
+				deps.emailVerifySvc.EXPECT().SendChangeEmail(gomock.Any(), int64(1), "new@example.com").Return(nil)
+				expectSave(deps, domain.UserProfile{Username: ptr("lewin")})
+			},
+			profile:          domain.UserProfile{Username: ptr("lewin"), Email: ptr("new@example.com")},
+			wantUser:         current,
+			wantPendingEmail: "new@example.com",
+		},
+		{
+			name: "确认邮件发送过于频繁",
+			mock: func(deps profileTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+				deps.userRepo.EXPECT().CountByEmail(gomock.Any(), "new@example.com").Return(int64(0), nil)
+				deps.emailVerifySvc.EXPECT().SendChangeEmail(gomock.Any(), int64(1), "new@example.com").
+					Return(errno.ErrTooManyRequests)
+			},
+			profile: domain.UserProfile{Username: ptr("lewin"), Email: ptr("new@example.com")},
+			wantErr: errno.ErrTooManyRequests,
+		},
+		{
+			name: "解除绑定邮箱立即生效",
+			mock: func(deps profileTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil)
+				expectSave(deps, domain.UserProfile{Email: ptr("")})
+			},
+			profile:  domain.UserProfile{Email: ptr("")},
+			wantUser: current,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, deps := newTestProfileService(ctrl)
+			tc.mock(deps)
+
+			user, pendingEmail, err := svc.UpdateProfile(context.Background(), 1, tc.profile)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUser, user)
+			assert.Equal(t, tc.wantPendingEmail, pendingEmail)
+		})
+	}
+}
+
+func Test_profileService_ConfirmEmailChange(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(deps profileTestDeps)
+
+		wantErr error
+	}{
+		{
+			name: "确认链接无效",
+			mock: func(deps profileTestDeps) {
+				deps.emailVerifySvc.EXPECT().VerifyChange(gomock.Any(), "token").
+					Return(int64(0), "", errno.ErrParameterInvalid)
+			},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name: "邮箱在确认前被其他用户使用",
+			mock: func(deps profileTestDeps) {
+				deps.emailVerifySvc.EXPECT().VerifyChange(gomock.Any(), "token").
+					Return(int64(1), "new@example.com", nil)
+				deps.userRepo.EXPECT().CountByEmail(gomock.Any(), "new@example.com").Return(int64(1), nil)
+			},
+			wantErr: errno.ErrEntityExists,
+		},
+		{
+			name: "修改成功",
+			mock: func(deps profileTestDeps) {
+				deps.emailVerifySvc.EXPECT().VerifyChange(gomock.Any(), "token").
+					Return(int64(1), "new@example.com", nil)
+				deps.userRepo.EXPECT().CountByEmail(gomock.Any(), "new@example.com").Return(int64(0), nil)
+				deps.userRepo.EXPECT().UpdateProfile(gomock.Any(), int64(1), domain.UserProfile{Email: ptr("new@example.com")}).
+					Return(nil)
+				deps.loginStateSvc.EXPECT().Invalidate(gomock.Any(), int64(1)).Return(nil)
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(domain.User{ID: 1}, nil)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, deps := newTestProfileService(ctrl)
+			tc.mock(deps)
+			assert.Equal(t, tc.wantErr, svc.ConfirmEmailChange(context.Background(), "token"))
+		})
+	}
+}
+
+func Test_profileService_ForceUpdateProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc, deps := newTestProfileService(ctrl)
+	current := domain.User{ID: 1, Phone: "+8613800138000", Email: "lewin@example.com"}
+
+	// 管理员修改手机号和邮箱不需要验证, 但仍然不能与其他用户重复
+	deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(1)).Return(current, nil).Times(2)
+	deps.userRepo.EXPECT().CountByPhone(gomock.Any(), "+8613900139000").Return(int64(0), nil)
+	deps.userRepo.EXPECT().CountByEmail(gomock.Any(), "new@example.com").Return(int64(0), nil)
+	deps.userRepo.EXPECT().UpdateProfile(gomock.Any(), int64(1), domain.UserProfile{
+		Phone: ptr("+8613900139000"),
+		Email: ptr("new@example.com"),
+	}).Return(nil)
+	deps.loginStateSvc.EXPECT().Invalidate(gomock.Any(), int64(1)).Return(nil)
+
+	user, err := svc.ForceUpdateProfile(context.Background(), 1, domain.UserProfile{
+		Phone: ptr("13900139000"),
+		Email: ptr("new@example.com"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, current, user)
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed lua/check_session.lua
+var luaCheckSession string
+
+//go:generate mockgen -source=./session.go -package=svcmocks -destination=./mocks/session.mock.go SessionService
+type SessionService interface {
+	// Create 记录一次登录, 返回会话标识 ssid
+	Create(ctx context.Context, s domain.LoginSession, expiration time.Duration) (string, error)
+	// Check 检查会话是否仍然有效, 同时更新最后活跃时间. 会话信息不存在即视为已被吊销或已过期
+	Check(ctx context.Context, ssid string) error
+	// Extend 延长会话信息的保存时间, 用于刷新 token 等场景
+	Extend(ctx context.Context, ssid string, expiration time.Duration) error
+	// List 返回用户当前的全部会话, 按登录时间倒序
+	List(ctx context.Context, uid int64) ([]domain.LoginSession, error)
+	// Revoke 吊销会话
+	Revoke(ctx context.Context, ssid string) error
+	// RevokeForUser 吊销用户自己的会话, 会话不属于该用户时返回错误
+	RevokeForUser(ctx context.Context, uid int64, ssid string) error
+	// RevokeAll 吊销用户的全部会话
+	RevokeAll(ctx context.Context, uid int64) error
+}
+
+func NewRedisSessionService(cmd redis.Cmdable) SessionService {
+	return &redisSessionService{
+		cmd:             cmd,
+		indexExpiration: time.Hour * 24 * 30,
+		touchInterval:   time.Minute,
+		now:             time.Now,
+	}
+}
+
+type redisSessionService struct {
+	cmd redis.Cmdable
+	// 用户会话索引的最短过期时间, 会话的过期时间更长时随之延长
+	indexExpiration time.Duration
+	// 更新最后活跃时间的最小间隔, 避免每个请求都写 Redis
+	touchInterval time.Duration
+	now           func() time.Time
+}
+
+func (r *redisSessionService) Create(ctx context.Context, s domain.LoginSession, expiration time.Duration) (string, error) {
+	ssid := uuid.New().String()
+	now := r.now().UnixMilli()
+	key := r.key(ssid)
+	if err := r.cmd.HSet(ctx, key,
+		"uid", s.UserID,
+		"type", s.Type,
+		"user_agent", s.UserAgent,
+		"ip", s.IP,
+		"created", now,
+		"last_seen", now,
+	).Err(); err != nil {
+		return "", err
+	}
+	if err := r.cmd.Expire(ctx, key, expiration).Err(); err != nil {
+		return "", err
+	}
+
+	// 记录用户的全部会话, 以便列出和一次性吊销
+	if err := r.cmd.SAdd(ctx, r.indexKey(s.UserID), ssid).Err(); err != nil {
+		return "", err
+	}
+	if err := r.extendIndex(ctx, s.UserID, expiration); err != nil {
+		return "", err
+	}
+	return ssid, nil
+}
+
+func (r *redisSessionService) Check(ctx context.Context, ssid string) error {
+	res, err := r.cmd.Eval(ctx, luaCheckSession, []string{r.key(ssid)},
+		r.now().UnixMilli(), r.touchInterval.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if res == -1 {
+		return errno.ErrUnauthorization.SetDescription("登录已失效, 请重新登录")
+	}
+	return nil
+}
+
+func (r *redisSessionService) Extend(ctx context.Context, ssid string, expiration time.Duration) error {
+	key := r.key(ssid)
+	uid, err := r.cmd.HGet(ctx, key, "uid").Int64()
+	if err == redis.Nil {
+		// 会话已被吊销或已过期
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = r.cmd.Expire(ctx, key, expiration).Err(); err != nil {
+		return err
+	}
+	return r.extendIndex(ctx, uid, expiration)
+}
+
+// extendIndex 确保用户会话索引的过期时间不早于 expiration, 不会缩短已有的过期时间.
+// 索引先于会话过期时, 列出和吊销全部会话会遗漏仍然有效的会话
+func (r *redisSessionService) extendIndex(ctx context.Context, uid int64, expiration time.Duration) error {
+	index := r.indexKey(uid)
+	expiration = max(expiration, r.indexExpiration)
+	// 新建的索引没有过期时间, GT 不会生效, 需要先用 NX 设置
+	if err := r.cmd.ExpireNX(ctx, index, expiration).Err(); err != nil {
+		return err
+	}
+	return r.cmd.ExpireGT(ctx, index, expiration).Err()
+}
+
+func (r *redisSessionService) List(ctx context.Context, uid int64) ([]domain.LoginSession, error) {
+	index := r.indexKey(uid)
+	ssids, err := r.cmd.SMembers(ctx, index).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]domain.LoginSession, 0, len(ssids))
+	for _, ssid := range ssids {
+		vals, err := r.cmd.HGetAll(ctx, r.key(ssid)).Result()
+		if err != nil {
+			return nil, err
+		}
+		// 会话已过期, 顺便从索引中移除
+		if len(vals) == 0 {
+			if err = r.cmd.SRem(ctx, index, ssid).Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		sessions = append(sessions, r.toDomain(ssid, vals))
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreateTime.After(sessions[j].CreateTime)
+	})
+	return sessions, nil
+}
+
+func (r *redisSessionService) Revoke(ctx context.Context, ssid string) error {
+	uid, err := r.cmd.HGet(ctx, r.key(ssid), "uid").Int64()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+	// 会话信息的过期时间与登录凭证一致, 删除后凭证在剩余有效期内都无法通过检查
+	if err = r.cmd.Del(ctx, r.key(ssid)).Err(); err != nil {
+		return err
+	}
+	if uid > 0 {
+		return r.cmd.SRem(ctx, r.indexKey(uid), ssid).Err()
+	}
+	return nil
+}
+
+func (r *redisSessionService) RevokeForUser(ctx context.Context, uid int64, ssid string) error {
+	ok, err := r.cmd.SIsMember(ctx, r.indexKey(uid), ssid).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errno.ErrEntityNull.SetDescription("会话不存在")
+	}
+	return r.Revoke(ctx, ssid)
+}
+
+func (r *redisSessionService) RevokeAll(ctx context.Context, uid int64) error {
+	index := r.indexKey(uid)
+	ssids, err := r.cmd.SMembers(ctx, index).Result()
+	if err != nil {
+		return err
+	}
+	for _, ssid := range ssids {
+		if err = r.cmd.Del(ctx, r.key(ssid)).Err(); err != nil {
+			return err
+		}
+	}
+	return r.cmd.Del(ctx, index).Err()
+}
+
+func (r *redisSessionService) toDomain(ssid string, vals map[string]string) domain.LoginSession {
+	uid, _ := strconv.ParseInt(vals["uid"], 10, 64)
+	created, _ := strconv.ParseInt(vals["created"], 10, 64)
+	lastSeen, _ := strconv.ParseInt(vals["last_seen"], 10, 64)
+	return domain.LoginSession{
+		ID:           ssid,
+		Type:         vals["type"],
+		UserID:       uid,
+		UserAgent:    vals["user_agent"],
+		IP:           vals["ip"],
+		CreateTime:   time.UnixMilli(created),
+		LastSeenTime: time.UnixMilli(lastSeen),
+	}
+}
+
+func (r *redisSessionService) key(ssid string) string {
+	return fmt.Sprintf("ucenter:users:session:%s", ssid)
+}
+
+func (r *redisSessionService) indexKey(uid int64) string {
+	return fmt.Sprintf("ucenter:users:sessions:%d", uid)
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestSessionService(t *testing.T, now *time.Time) (*redisSessionService, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	svc := NewRedisSessionService(redis.NewClient(&redis.Options{Addr: mr.Addr()})).(*redisSessionService)
+	svc.now = func() time.Time { return *now }
+	return svc, mr
+}
+
+func Test_redisSessionService_Check(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc, mr := newTestSessionService(t, &now)
+	ctx := context.Background()
+
+	ssid, err := svc.Create(ctx, domain.LoginSession{Type: domain.LoginSessionCookie, UserID: 1, IP: "10.0.0.1"}, 30*24*time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, svc.Check(ctx, ssid))
+
+	// 间隔内不更新最后活跃时间
+	now = now.Add(30 * time.Second)
+	require.NoError(t, svc.Check(ctx, ssid))
+	sessions, err := svc.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, now.Add(-30*time.Second).UnixMilli(), sessions[0].LastSeenTime.UnixMilli())
+
+	now = now.Add(time.Minute)
+	require.NoError(t, svc.Check(ctx, ssid))
+	sessions, err = svc.List(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, now.UnixMilli(), sessions[0].LastSeenTime.UnixMilli())
+
+	// 会话在自身的有效期内一直有效, 过期后检查失败
+	mr.FastForward(8 * 24 * time.Hour)
+	require.NoError(t, svc.Check(ctx, ssid))
+	mr.FastForward(30 * 24 * time.Hour)
+	assert.Equal(t, errno.ErrUnauthorization, svc.Check(ctx, ssid))
+
+	// 不存在的会话
+	assert.Equal(t, errno.ErrUnauthorization, svc.Check(ctx, "unknown"))
+}
+
+func Test_redisSessionService_Revoke(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc, mr := newTestSessionService(t, &now)
+	ctx := context.Background()
+
+	first, err := svc.Create(ctx, domain.LoginSession{Type: domain.LoginSessionJWT, UserID: 1}, 30*24*time.Hour)
+	require.NoError(t, err)
+	now = now.Add(time.Second)
+	second, err := svc.Create(ctx, domain.LoginSession{Type: domain.LoginSessionJWT, UserID: 1}, 30*24*time.Hour)
+	require.NoError(t, err)
+	other, err := svc.Create(ctx, domain.LoginSession{Type: domain.LoginSessionJWT, UserID: 2}, time.Hour)
+	require.NoError(t, err)
+
+	// 只能吊销自己的会话
+	assert.Equal(t, errno.ErrEntityNull, svc.RevokeForUser(ctx, 1, other))
+	require.NoError(t, svc.RevokeForUser(ctx, 1, first))
+	assert.Equal(t, errno.ErrUnauthorization, svc.Check(ctx, first))
+	// 吊销后在凭证的剩余有效期内一直无效
+	mr.FastForward(8 * 24 * time.Hour)
+	assert.Equal(t, errno.ErrUnauthorization, svc.Check(ctx, first))
+	// 续期不能恢复已吊销的会话
+	require.NoError(t, svc.Extend(ctx, first, 30*24*time.Hour))
+	assert.Equal(t, errno.ErrUnauthorization, svc.Check(ctx, first))
+
+	sessions, err := svc.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, second, sessions[0].ID)
+	assert.NoError(t, svc.Check(ctx, second))
+}
+
+func Test_redisSessionService_RevokeAll(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc, _ := newTestSessionService(t, &now)
+	ctx := context.Background()
+
+	var ssids []string
+	for i := 0; i < 3; i++ {
+		ssid, err := svc.Create(ctx, domain.LoginSession{Type: domain.LoginSessionCookie, UserID: 1}, time.Hour)
+		require.NoError(t, err)
+		ssids = append(ssids, ssid)
+	}
+	other, err := svc.Create(ctx, domain.LoginSession{Type: domain.LoginSessionCookie, UserID: 2}, time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RevokeAll(ctx, 1))
+	for _, ssid := range ssids {
+		assert.Equal(t, errno.ErrUnauthorization, svc.Check(ctx, ssid))
+	}
+	sessions, err := svc.List(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	// 其他用户的会话不受影响
+	assert.NoError(t, svc.Check(ctx, other))
+}
+
+func Test_redisSessionService_ExtendIndex(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc, mr := newTestSessionService(t, &now)
+	ctx := context.Background()
+
+	// 会话的有效期长于索引的默认过期时间
+	_, err := svc.Create(ctx, domain.LoginSession{Type: domain.LoginSessionCookie, UserID: 1}, 60*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 60*24*time.Hour, mr.TTL(svc.indexKey(1)))
+	// 之后创建的短会话不会缩短索引的过期时间
+	_, err = svc.Create(ctx, domain.LoginSession{Type: domain.LoginSessionJWT, UserID: 1}, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 60*24*time.Hour, mr.TTL(svc.indexKey(1)))
+
+	// 刷新时不断延长的会话, 索引随之延长
+	refreshed, err := svc.Create(ctx, domain.LoginSession{Type: domain.LoginSessionJWT, UserID: 2}, 7*24*time.Hour)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		mr.FastForward(6 * 24 * time.Hour)
+		require.NoError(t, svc.Extend(ctx, refreshed, 7*24*time.Hour))
+	}
+	sessions, err := svc.List(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.NoError(t, svc.RevokeAll(ctx, 2))
+	assert.Equal(t, errno.ErrUnauthorization, svc.Check(ctx, refreshed))
+
+	// 已被吊销的会话不能延长
+	require.NoError(t, svc.Extend(ctx, refreshed, time.Hour))
+	assert.False(t, mr.Exists(svc.key(refreshed)))
+}
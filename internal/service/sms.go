@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/repository"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/hasher"
+	"github.com/coderlewin/ucenter/pkg/sms"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	//go:embed lua/set_sms_code.lua
+	luaSetSMSCode string
+	//go:embed lua/verify_sms_code.lua
+	luaVerifySMSCode string
+	//go:embed lua/rollback_sms_code.lua
+	luaRollbackSMSCode string
+)
+
+// SMSOptions 定义短信验证码登录的选项
+type SMSOptions struct {
+	DefaultCountryCode string        // 未携带国家码的手机号使用的国家码
+	CodeExpiration     time.Duration // 验证码有效期
+	ResendInterval     time.Duration // 同一手机号两次发送的最小间隔
+	DailyLimit         int64         // 同一手机号 24 小时内允许的发送次数
+	MaxVerifyAttempts  int64         // 每个验证码允许的校验次数
+	AutoRegister       bool          // 手机号未注册时是否自动注册
+}
+
+// 验证码用途, 不同用途的验证码互不通用
+const (
+	smsPurposeLogin = "login"
+	smsPurposeBind  = "bind"
+)
+
+//go:generate mockgen -source=./sms.go -package=svcmocks -destination=./mocks/sms.mock.go SMSService
+type SMSService interface {
+	// SendLoginCode 发送登录验证码
+	SendLoginCode(ctx context.Context, phone string) error
+	// LoginByCode 校验验证码并登录, 开启自动注册时未注册的手机号会创建新用户
+	LoginByCode(ctx context.Context, phone string, code string) (domain.User, error)
+	// SendBindCode 向新手机号发送绑定验证码, 用于修改手机号前确认用户持有该号码
+	SendBindCode(ctx context.Context, phone string) error
+	// VerifyBindCode 校验绑定验证码, 返回规范化后的手机号, 验证码只能使用一次
+	VerifyBindCode(ctx context.Context, phone string, code string) (string, error)
+}
+
+func NewSMSService(opts SMSOptions, cmd redis.Cmdable, sender sms.Sender, userRepo repository.UserRepository,
+	pwdHasher hasher.PasswordHasher, defaultAvatarSvc DefaultAvatarService) SMSService {
+	return &smsService{opts: opts, cmd: cmd, sender: sender, userRepo: userRepo, pwdHasher: pwdHasher,
+		defaultAvatarSvc: defaultAvatarSvc}
+}
+
+type smsService struct {
+	opts             SMSOptions
+	cmd              redis.Cmdable
+	sender           sms.Sender
+	userRepo         repository.UserRepository
+	pwdHasher        hasher.PasswordHasher
+	defaultAvatarSvc DefaultAvatarService
+}
+
+func (s *smsService) SendLoginCode(ctx context.Context, phone string) error {
+	return s.sendCode(ctx, smsPurposeLogin, phone, "您的登录验证码为 %s, %d 分钟内有效, 请勿泄露给他人.")
+}
+
+func (s *smsService) SendBindCode(ctx context.Context, phone string) error {
+	return s.sendCode(ctx, smsPurposeBind, phone, "您正在绑定手机号, 验证码为 %s, %d 分钟内有效, 请勿泄露给他人.")
+}
+
+func (s *smsService) VerifyBindCode(ctx context.Context, phone string, code string) (string, error) {
+	phone, err := s.normalize(phone)
+	if err != nil {
+		return "", err
+	}
+	if err = s.verifyCode(ctx, smsPurposeBind, phone, code); err != nil {
+		return "", err
+	}
+	return phone, nil
+}
+
+// sendCode 发送 purpose 用途的验证码, content 为短信模板, 参数依次为验证码和有效分钟数.
+// 同一手机号不同用途的验证码共用发送次数限制
+func (s *smsService) sendCode(ctx context.Context, purpose string, phone string, content string) error {
+	phone, err := s.normalize(phone)
+	if err != nil {
+		return err
+	}
+	code, err := s.generateCode()
+	if err != nil {
+		return err
+	}
+
+	res, err := s.cmd.Eval(ctx, luaSetSMSCode, []string{s.codeKey(purpose, phone), s.countKey(phone)},
+		code, s.opts.CodeExpiration.Milliseconds(), s.opts.ResendInterval.Milliseconds(),
+		s.opts.MaxVerifyAttempts, s.opts.DailyLimit, (24 * time.Hour).Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	switch res {
+	case -1:
+		return errno.ErrTooManyRequests.SetDescription("发送过于频繁, 请稍后重试")
+	case -2:
+		return errno.ErrTooManyRequests.SetDescription("发送次数已达上限, 请稍后再试")
+	}
+
+	content = fmt.Sprintf(content, code, int(s.opts.CodeExpiration.Minutes()))
+	if err = s.sender.Send(ctx, phone, content); err != nil {
+		// 发送失败时允许立即重试, 且不占用当天的发送次数
+		if rerr := s.cmd.Eval(ctx, luaRollbackSMSCode, []string{s.codeKey(purpose, phone), s.countKey(phone)}, code).Err(); rerr != nil {
+			hlog.CtxWarnf(ctx, "rollback sms code failed, err=%v", rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *smsService) LoginByCode(ctx context.Context, phone string, code string) (domain.User, error) {
+	phone, err := s.normalize(phone)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if err = s.verifyCode(ctx, smsPurposeLogin, phone, code); err != nil {
+		return domain.User{}, err
+	}
+
+	user, err := s.userRepo.FindByPhone(ctx, phone)
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound) && s.opts.AutoRegister:
+		if user, err = s.register(ctx, phone); err != nil {
+			return domain.User{}, err
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return domain.User{}, errno.ErrEntityNull.SetDescription("手机号未注册")
+	case errors.Is(err, repository.ErrAmbiguousPhone):
+		// 无法确定登录哪个账号, 不能随意选择其中一个
+		return domain.User{}, errno.ErrForbidden.SetDescription("手机号被多个账号使用, 请使用账号密码登录")
+	default:
+		return domain.User{}, errno.ErrDBFailed
+	}
+
+	// 是否被冻结
+	if user.IsFreeze() {
+		return domain.User{}, errno.ErrForbidden.SetDescription("账号已被冻结")
+	}
+	// 与账号密码登录相同, 邮箱验证前不允许登录
+	if user.IsPending() {
+		return domain.User{}, errno.ErrEmailUnverified.SetDescription("请先完成邮箱验证")
+	}
+	return user, nil
+}
+
+// register 使用手机号自动注册, 账号由手机号生成, 密码随机生成, 用户之后可以通过重置密码设置
+func (s *smsService) register(ctx context.Context, phone string) (domain.User, error) {
+	password, err := randomToken()
+	if err != nil {
+		return domain.User{}, err
+	}
+	digits := strings.TrimPrefix(phone, "+")
+	ud := domain.User{
+		Username:     "用户" + digits[len(digits)-4:],
+		UserAccount:  "m" + digits,
+		UserPassword: password,
+		Phone:        phone,
+	}
+	// 账号由手机号生成, 只能用用户名生成首字母头像
+	ud.AvatarURL = s.defaultAvatarSvc.URL(ud.UserAccount, ud.Username)
+	count, err := s.userRepo.CountByAccount(ctx, ud.UserAccount)
+	if err != nil {
+		return domain.User{}, errno.ErrDBFailed
+	}
+	if count > 0 {
+		return domain.User{}, errno.ErrEntityExists.SetDescription("账号已存在")
+	}
+	if err = ud.EncryptPassword(s.pwdHasher); err != nil {
+		return domain.User{}, err
+	}
+	ud.ID, err = s.userRepo.Create(ctx, ud)
+	if err != nil {
+		return domain.User{}, errno.ErrDBFailed
+	}
+	hlog.CtxInfof(ctx, "user registered by sms, uid=%d", ud.ID)
+	return ud, nil
+}
+
+// verifyCode 校验规范化后的手机号 phone 收到的 purpose 用途的验证码
+func (s *smsService) verifyCode(ctx context.Context, purpose string, phone string, code string) error {
+	res, err := s.cmd.Eval(ctx, luaVerifySMSCode, []string{s.codeKey(purpose, phone)}, strings.TrimSpace(code)).Int()
+	if err != nil {
+		return err
+	}
+	switch res {
+	case -1:
+		return errno.ErrUnauthorization.SetDescription("验证码已过期, 请重新获取")
+	case -2:
+		return errno.ErrUnauthorization.SetDescription("验证码错误次数过多, 请重新获取")
+	case -3:
+		return errno.ErrUnauthorization.SetDescription("验证码错误")
+	}
+	return nil
+}
+
+func (s *smsService) normalize(phone string) (string, error) {
+	phone, err := sms.NormalizePhone(phone, s.opts.DefaultCountryCode)
+	if err != nil {
+		return "", errno.ErrParameterInvalid.SetDescription("手机号格式错误")
+	}
+	return phone, nil
+}
+
+func (s *smsService) generateCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func (s *smsService) codeKey(purpose string, phone string) string {
+	return fmt.Sprintf("ucenter:sms:%s_code:%s", purpose, phone)
+}
+
+func (s *smsService) countKey(phone string) string {
+	return fmt.Sprintf("ucenter:sms:send_count:%s", phone)
+}
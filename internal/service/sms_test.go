@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/repository"
+	repomocks "github.com/coderlewin/ucenter/internal/repository/mocks"
+	svcmocks "github.com/coderlewin/ucenter/internal/service/mocks"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/hasher"
+	"github.com/coderlewin/ucenter/pkg/sms"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+const testPhone = "+8613800138000"
+
+// smsSenderFunc 把函数适配为 sms.Sender
+type smsSenderFunc func(ctx context.Context, phone string, content string) error
+
+func (f smsSenderFunc) Send(ctx context.Context, phone string, content string) error {
+	return f(ctx, phone, content)
+}
+
+type smsTestDeps struct {
+	userRepo         *repomocks.MockUserRepository
+	defaultAvatarSvc *svcmocks.MockDefaultAvatarService
+	mr               *miniredis.Miniredis
+}
+
+func newTestSMSService(t *testing.T, ctrl *gomock.Controller, sender sms.Sender, autoRegister bool) (*smsService, smsTestDeps) {
+	deps := smsTestDeps{
+		userRepo:         repomocks.NewMockUserRepository(ctrl),
+		defaultAvatarSvc: svcmocks.NewMockDefaultAvatarService(ctrl),
+		mr:               miniredis.RunT(t),
+	}
+	svc := NewSMSService(SMSOptions{
+		DefaultCountryCode: "86",
+		CodeExpiration:     5 * time.Minute,
+		ResendInterval:     time.Minute,
+		DailyLimit:         3,
+		MaxVerifyAttempts:  3,
+		AutoRegister:       autoRegister,
+	}, redis.NewClient(&redis.Options{Addr: deps.mr.Addr()}), sender, deps.userRepo,
+		hasher.NewPasswordHasher(hasher.NewBcryptScheme(4)), deps.defaultAvatarSvc)
+	return svc.(*smsService), deps
+}
+
+var smsCodeRegexp = regexp.MustCompile(`\d{6}`)
+
+// lastSMSCode 返回最后一条短信中的验证码
+func lastSMSCode(t *testing.T, sender *sms.MemorySender) string {
+	msgs := sender.Messages()
+	require.NotEmpty(t, msgs)
+	code := smsCodeRegexp.FindString(msgs[len(msgs)-1].Content)
+	require.NotEmpty(t, code)
+	return code
+}
+
+func Test_smsService_SendLoginCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	sender := sms.NewMemorySender()
+	svc, deps := newTestSMSService(t, ctrl, sender, false)
+	ctx := context.Background()
+
+	err := svc.SendLoginCode(ctx, "12345")
+	assert.Equal(t, errno.ErrParameterInvalid, err)
+
+	// 发送到规范化后的号码
+	require.NoError(t, svc.SendLoginCode(ctx, "138-0013-8000"))
+	require.Len(t, sender.Messages(), 1)
+	assert.Equal(t, testPhone, sender.Messages()[0].Phone)
+
+	// 发送间隔内不能重复发送
+	err = svc.SendLoginCode(ctx, testPhone)
+	assert.Equal(t, errno.ErrTooManyRequests, err)
+	assert.Equal(t, "发送过于频繁, 请稍后重试", errno.ErrTooManyRequests.Desc)
+
+	// 超过 24 小时内的发送次数
+	for i := 0; i < 2; i++ {
+		deps.mr.FastForward(time.Minute)
+		require.NoError(t, svc.SendLoginCode(ctx, testPhone))
+	}
+	deps.mr.FastForward(time.Minute)
+	err = svc.SendLoginCode(ctx, testPhone)
+	assert.Equal(t, errno.ErrTooManyRequests, err)
+	assert.Equal(t, "发送次数已达上限, 请稍后再试", errno.ErrTooManyRequests.Desc)
+
+	// 计数器过期后恢复
+	deps.mr.FastForward(24 * time.Hour)
+	assert.NoError(t, svc.SendLoginCode(ctx, testPhone))
+}
+
+func Test_smsService_SendLoginCode_SendFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	sendErr := errors.New("provider unavailable")
+	failed := true
+	svc, deps := newTestSMSService(t, ctrl, smsSenderFunc(func(ctx context.Context, phone string, content string) error {
+		if failed {
+			return sendErr
+		}
+		return nil
+	}), false)
+	ctx := context.Background()
+
+	// 多次发送失败既不限制重发, 也不占用发送次数
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, sendErr, svc.SendLoginCode(ctx, testPhone))
+		assert.False(t, deps.mr.Exists(svc.codeKey(smsPurposeLogin, testPhone)))
+		count, _ := deps.mr.Get(svc.countKey(testPhone))
+		assert.Equal(t, "0", count)
+	}
+
+	failed = false
+	require.NoError(t, svc.SendLoginCode(ctx, testPhone))
+	count, err := deps.mr.Get(svc.countKey(testPhone))
+	require.NoError(t, err)
+	assert.Equal(t, "1", count)
+}
+
+func Test_smsService_LoginByCode(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		autoRegister bool
+		mock         func(deps smsTestDeps)
+		// 提交的验证码, 为 nil 时使用短信中的验证码
+		code func(code string) string
+
+		wantErr  error
+		wantUser domain.User
+	}{
+		{
+			name: "验证码错误",
+			mock: func(deps smsTestDeps) {},
+			code: func(code string) string {
+				if code == "000000" {
+					return "000001"
+				}
+				return "000000"
+			},
+			wantErr: errno.ErrUnauthorization,
+		},
+		{
+			name: "已注册用户登录",
+			mock: func(deps smsTestDeps) {
+				deps.userRepo.EXPECT().FindByPhone(gomock.Any(), testPhone).Return(domain.User{ID: 1, Phone: testPhone}, nil)
+			},
+			wantUser: domain.User{ID: 1, Phone: testPhone},
+		},
+		{
+			name: "用户已被冻结",
+			mock: func(deps smsTestDeps) {
+				deps.userRepo.EXPECT().FindByPhone(gomock.Any(), testPhone).
+					Return(domain.User{ID: 1, Phone: testPhone, UserStatus: constants.UserStatusDisabled}, nil)
+			},
+			wantErr: errno.ErrForbidden,
+		},
+		{
+			name: "邮箱未验证",
+			mock: func(deps smsTestDeps) {
+				deps.userRepo.EXPECT().FindByPhone(gomock.Any(), testPhone).
+					Return(domain.User{ID: 1, Phone: testPhone, UserStatus: constants.UserStatusPending}, nil)
+			},
+			wantErr: errno.ErrEmailUnverified,
+		},
+		{
+			name:         "手机号被多个账号使用",
+			autoRegister: true,
+			mock: func(deps smsTestDeps) {
+				deps.userRepo.EXPECT().FindByPhone(gomock.Any(), testPhone).Return(domain.User{}, repository.ErrAmbiguousPhone)
+			},
+			wantErr: errno.ErrForbidden,
+		},
+		{
+			name: "未开启自动注册",
+			mock: func(deps smsTestDeps) {
+				deps.userRepo.EXPECT().FindByPhone(gomock.Any(), testPhone).Return(domain.User{}, gorm.ErrRecordNotFound)
+			},
+			wantErr: errno.ErrEntityNull,
+		},
+		{
+			name:         "自动注册",
+			autoRegister: true,
+			mock: func(deps smsTestDeps) {
+				deps.userRepo.EXPECT().FindByPhone(gomock.Any(), testPhone).Return(domain.User{}, gorm.ErrRecordNotFound)
+				deps.defaultAvatarSvc.EXPECT().URL("m8613800138000", "用户8000").Return("https://avatar")
+				deps.userRepo.EXPECT().CountByAccount(gomock.Any(), "m8613800138000").Return(int64(0), nil)
+				deps.userRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, u domain.User) (int64, error) {
+						// 随机生成密码
+						assert.NotEmpty(t, u.UserPassword)
+						return 2, nil
+					})
+			},
+			wantUser: domain.User{ID: 2, Username: "用户8000", UserAccount: "m8613800138000", Phone: testPhone, AvatarURL: "https://avatar"},
+		},
+		{
+			name:         "自动注册时账号已存在",
+			autoRegister: true,
+			mock: func(deps smsTestDeps) {
+				deps.userRepo.EXPECT().FindByPhone(gomock.Any(), testPhone).Return(domain.User{}, gorm.ErrRecordNotFound)
+				deps.defaultAvatarSvc.EXPECT().URL(gomock.Any(), gomock.Any()).Return("https://avatar")
+				deps.userRepo.EXPECT().CountByAccount(gomock.Any(), "m8613800138000").Return(int64(1), nil)
+			},
+			wantErr: errno.ErrEntityExists,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			sender := sms.NewMemorySender()
+			svc, deps := newTestSMSService(t, ctrl, sender, tc.autoRegister)
+			tc.mock(deps)
+			require.NoError(t, svc.SendLoginCode(context.Background(), testPhone))
+			code := lastSMSCode(t, sender)
+			if tc.code != nil {
+				code = tc.code(code)
+			}
+
+			user, err := svc.LoginByCode(context.Background(), "13800138000", code)
+			assert.Equal(t, tc.wantErr, err)
+			user.UserPassword = ""
+			assert.Equal(t, tc.wantUser, user)
+		})
+	}
+}
+
+func Test_smsService_LoginByCode_Attempts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	sender := sms.NewMemorySender()
+	svc, deps := newTestSMSService(t, ctrl, sender, false)
+	ctx := context.Background()
+	require.NoError(t, svc.SendLoginCode(ctx, testPhone))
+	code := lastSMSCode(t, sender)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "000001"
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.LoginByCode(ctx, testPhone, wrong)
+		assert.Equal(t, errno.ErrUnauthorization, err)
+	}
+	// 校验次数用完后, 正确的验证码也不能使用
+	_, err := svc.LoginByCode(ctx, testPhone, code)
+	assert.Equal(t, errno.ErrUnauthorization, err)
+	assert.Equal(t, "验证码错误次数过多, 请重新获取", errno.ErrUnauthorization.Desc)
+
+	// 验证码只能使用一次
+	deps.mr.FastForward(time.Minute)
+	require.NoError(t, svc.SendLoginCode(ctx, testPhone))
+	code = lastSMSCode(t, sender)
+	deps.userRepo.EXPECT().FindByPhone(gomock.Any(), testPhone).Return(domain.User{ID: 1}, nil)
+	_, err = svc.LoginByCode(ctx, testPhone, code)
+	require.NoError(t, err)
+	_, err = svc.LoginByCode(ctx, testPhone, code)
+	assert.Equal(t, errno.ErrUnauthorization, err)
+	assert.Equal(t, "验证码已过期, 请重新获取", errno.ErrUnauthorization.Desc)
+}
+
+func Test_smsService_BindCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	sender := sms.NewMemorySender()
+	svc, deps := newTestSMSService(t, ctrl, sender, false)
+	ctx := context.Background()
+
+	require.NoError(t, svc.SendBindCode(ctx, "13800138000"))
+	code := lastSMSCode(t, sender)
+
+	// 绑定验证码不能用于登录
+	_, err := svc.LoginByCode(ctx, testPhone, code)
+	assert.Equal(t, errno.ErrUnauthorization, err)
+
+	// 与登录验证码共用发送次数
+	require.NoError(t, svc.SendLoginCode(ctx, testPhone))
+	count, err := deps.mr.Get(svc.countKey(testPhone))
+	require.NoError(t, err)
+	assert.Equal(t, "2", count)
+
+	phone, err := svc.VerifyBindCode(ctx, "138 0013 8000", code)
+	require.NoError(t, err)
+	assert.Equal(t, testPhone, phone)
+	// 验证码只能使用一次
+	_, err = svc.VerifyBindCode(ctx, testPhone, code)
+	assert.Equal(t, errno.ErrUnauthorization, err)
+}
@@ -4,33 +4,96 @@ import (
 	"context"
 	"errors"
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/coderlewin/ucenter/internal/domain"
 	"github.com/coderlewin/ucenter/internal/repository"
 	"github.com/coderlewin/ucenter/pkg/core"
 	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/hasher"
 	"gorm.io/gorm"
 )
 
 //go:generate mockgen -source=./user.go -package=svcmocks -destination=./mocks/user.mock.go UserService
 type UserService interface {
 	Register(ctx context.Context, ud domain.User) (int64, error)
-	Login(ctx context.Context, ud domain.User) (domain.User, error)
+	// CheckRegister 校验注册参数和密码策略, 并检查账号、星球编号、邮箱是否已被使用.
+	// 不合法时返回原因而不是共享的错误变量, 可以在后台任务中并发调用
+	CheckRegister(ctx context.Context, ud domain.User) (string, error)
+	// Login 账号密码登录, 账号或 ip 失败次数过多时锁定
+	Login(ctx context.Context, ud domain.User, ip string) (domain.User, error)
 	Logout(ctx context.Context, c *app.RequestContext) error
 	GetCurrentUser(ctx context.Context, id int64) (domain.User, error)
 	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, username string, current int, size int) ([]domain.User, int64, error)
+	// List 按筛选条件和排序分页查询用户
+	List(ctx context.Context, query domain.UserQuery) ([]domain.User, int64, error)
+	// ListByCursor 按筛选条件和排序游标分页查询用户, 按 query.Count 统计总数
+	ListByCursor(ctx context.Context, query domain.UserCursorQuery) (domain.UserCursorPage, error)
+	// ChangePassword 校验原密码后修改密码, ud 中为新密码和确认密码.
+	// 修改后用户的全部登录会话失效, 返回更新了安全版本的用户, 用于重新保存当前登录态
+	ChangePassword(ctx context.Context, uid int64, oldPassword string, ud domain.User, ip, userAgent string) (domain.User, error)
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo repository.UserRepository, auditRepo repository.AuditLogRepository,
+	pwdHasher hasher.PasswordHasher, loginStateSvc LoginStateService, pwdPolicySvc PasswordPolicyService,
+	sessionSvc SessionService, loginLimitSvc LoginLimitService) UserService {
+	return &userService{
+		userRepo:      userRepo,
+		auditRepo:     auditRepo,
+		pwdHasher:     pwdHasher,
+		loginStateSvc: loginStateSvc,
+		pwdPolicySvc:  pwdPolicySvc,
+		sessionSvc:    sessionSvc,
+		loginLimitSvc: loginLimitSvc,
+	}
 }
 
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo      repository.UserRepository
+	auditRepo     repository.AuditLogRepository
+	pwdHasher     hasher.PasswordHasher
+	loginStateSvc LoginStateService
+	pwdPolicySvc  PasswordPolicyService
+	loginLimitSvc LoginLimitService
+	sessionSvc    SessionService
+}
+
+func (svc *userService) List(ctx context.Context, query domain.UserQuery) ([]domain.User, int64, error) {
+	if err := query.Validate(); err != nil {
+		return nil, 0, err
+	}
+	return svc.userRepo.Search(ctx, query)
 }
 
-func (svc *userService) List(ctx context.Context, username string, current int, size int) ([]domain.User, int64, error) {
-	return svc.userRepo.ListByUsername(ctx, username, (current-1)*size, size)
+func (svc *userService) ListByCursor(ctx context.Context, query domain.UserCursorQuery) (domain.UserCursorPage, error) {
+	if err := query.Validate(); err != nil {
+		return domain.UserCursorPage{}, err
+	}
+	sorts := query.KeysetSorts()
+	var after []any
+	if query.Cursor != "" {
+		var err error
+		if after, err = domain.DecodeUserCursor(sorts, query.Cursor); err != nil {
+			return domain.UserCursorPage{}, err
+		}
+	}
+	// 多查一个用户判断是否还有下一页
+	users, err := svc.userRepo.SearchAfter(ctx, query.Filter, sorts, after, query.Size+1)
+	if err != nil {
+		return domain.UserCursorPage{}, err
+	}
+	page := domain.UserCursorPage{Users: users, Total: -1}
+	if len(users) > query.Size {
+		page.Users = users[:query.Size]
+		page.NextCursor = domain.EncodeUserCursor(sorts, page.Users[query.Size-1])
+	}
+	switch query.Count {
+	case domain.CountExact:
+		page.Total, err = svc.userRepo.CountByFilter(ctx, query.Filter)
+	case domain.CountApprox:
+		page.Total, err = svc.userRepo.EstimateCountByFilter(ctx, query.Filter)
+		page.Approximate = true
+	}
+	return page, err
 }
 
 func (svc *userService) Delete(ctx context.Context, id int64) error {
@@ -38,7 +101,62 @@ func (svc *userService) Delete(ctx context.Context, id int64) error {
 		return errno.ErrParameterInvalid
 	}
 
-	return svc.userRepo.Delete(ctx, id)
+	if err := svc.userRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	// 被删除用户的登录态立即失效
+	return svc.loginStateSvc.Invalidate(ctx, id)
+}
+
+func (svc *userService) ChangePassword(ctx context.Context, uid int64, oldPassword string, ud domain.User,
+	ip, userAgent string) (domain.User, error) {
+	if err := ud.ValidatePassword(); err != nil {
+		return domain.User{}, err
+	}
+	user, err := svc.GetCurrentUser(ctx, uid)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	ok, err := user.ComparePassword(svc.pwdHasher, oldPassword)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if !ok {
+		return domain.User{}, errno.ErrParameterInvalid.SetDescription("原密码错误")
+	}
+	if err = svc.pwdPolicySvc.Validate(ctx, user, ud.UserPassword); err != nil {
+		return domain.User{}, err
+	}
+
+	// 与注册使用同样的哈希算法, 同时递增安全版本
+	if err = ud.EncryptPassword(svc.pwdHasher); err != nil {
+		return domain.User{}, err
+	}
+	if err = svc.userRepo.UpdatePassword(ctx, uid, ud.UserPassword); err != nil {
+		return domain.User{}, errno.ErrDBFailed
+	}
+	if err = svc.pwdPolicySvc.Record(ctx, uid, ud.UserPassword); err != nil {
+		hlog.CtxWarnf(ctx, "record password history failed, uid=%d, err=%v", uid, err)
+	}
+	if err = svc.auditRepo.Create(ctx, domain.AuditLog{
+		UserID:     uid,
+		OperatorID: uid,
+		Action:     domain.AuditActionPasswordChange,
+		IP:         ip,
+		UserAgent:  userAgent,
+	}); err != nil {
+		hlog.CtxErrorf(ctx, "record audit log failed, uid=%d, action=%s, err=%v", uid, domain.AuditActionPasswordChange, err)
+	}
+
+	// 其他设备上的登录态全部失效
+	if err = svc.loginStateSvc.Invalidate(ctx, uid); err != nil {
+		return domain.User{}, err
+	}
+	if err = svc.sessionSvc.RevokeAll(ctx, uid); err != nil {
+		return domain.User{}, err
+	}
+	return svc.GetCurrentUser(ctx, uid)
 }
 
 func (svc *userService) GetCurrentUser(ctx context.Context, id int64) (domain.User, error) {
@@ -56,15 +174,34 @@ func (svc *userService) Logout(ctx context.Context, c *app.RequestContext) error
 	return core.RemoveUserLoginState(c)
 }
 
-func (svc *userService) Login(ctx context.Context, ud domain.User) (domain.User, error) {
+func (svc *userService) Login(ctx context.Context, ud domain.User, ip string) (domain.User, error) {
 	// 校验登录参数是否合法
 	if err := ud.ValidateLoginParameters(); err != nil {
 		return domain.User{}, err
 	}
 
-	// 密码加密
-	ud.EncryptPassword()
+	// 账号或 IP 失败次数过多时直接拒绝, 不再校验密码
+	if err := svc.loginLimitSvc.Check(ctx, ud.UserAccount, ip); err != nil {
+		return domain.User{}, err
+	}
+	user, err := svc.authenticate(ctx, ud)
+	if err != nil {
+		// 账号不存在和密码错误都计入失败次数
+		if errors.Is(err, errno.ErrEntityNull) {
+			if lockErr := svc.loginLimitSvc.Fail(ctx, ud.UserAccount, ip); lockErr != nil {
+				err = lockErr
+			}
+		}
+		return domain.User{}, err
+	}
+	if err = svc.loginLimitSvc.Succeed(ctx, ud.UserAccount); err != nil {
+		hlog.CtxWarnf(ctx, "clear login failures failed, account=%s, err=%v", ud.UserAccount, err)
+	}
+	return user, nil
+}
 
+// authenticate 校验账号密码和账号状态
+func (svc *userService) authenticate(ctx context.Context, ud domain.User) (domain.User, error) {
 	user, err := svc.userRepo.FindByAccount(ctx, ud.UserAccount)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -74,7 +211,11 @@ func (svc *userService) Login(ctx context.Context, ud domain.User) (domain.User,
 	}
 
 	// 对比密码
-	if ud.UserPassword != user.UserPassword {
+	ok, err := user.ComparePassword(svc.pwdHasher, ud.UserPassword)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if !ok {
 		return domain.User{}, errno.ErrEntityNull.SetDescription("账号和密码不匹配")
 	}
 
@@ -83,35 +224,119 @@ func (svc *userService) Login(ctx context.Context, ud domain.User) (domain.User,
 		return domain.User{}, errno.ErrForbidden.SetDescription("账号已被冻结")
 	}
 
+	// 邮箱验证前不允许登录
+	if user.IsPending() {
+		return domain.User{}, errno.ErrEmailUnverified.SetDescription("请先完成邮箱验证")
+	}
+
+	// 历史密码哈希升级为当前配置的算法
+	svc.rehashPassword(ctx, &user, ud.UserPassword)
+
 	return user, nil
 }
 
+// rehashPassword 在登录成功后将过时的密码哈希升级为当前配置的算法, 失败不影响登录
+func (svc *userService) rehashPassword(ctx context.Context, user *domain.User, plain string) {
+	if !svc.pwdHasher.NeedsRehash(user.UserPassword) {
+		return
+	}
+	encoded, err := svc.pwdHasher.Hash(plain)
+	if err != nil {
+		hlog.CtxWarnf(ctx, "rehash password failed, uid=%d, err=%v", user.ID, err)
+		return
+	}
+	// 不能使用 UpdatePassword, 递增安全版本会使本次登录和其他设备的登录态立即失效
+	ok, err := svc.userRepo.RehashPassword(ctx, user.ID, user.UserPassword, encoded)
+	if err != nil {
+		hlog.CtxWarnf(ctx, "update rehashed password failed, uid=%d, err=%v", user.ID, err)
+		return
+	}
+	if ok {
+		user.UserPassword = encoded
+	}
+}
+
 func (svc *userService) Register(ctx context.Context, ud domain.User) (int64, error) {
-	// 校验注册参数是否合法
-	if err := ud.ValidateRegisterParameters(); err != nil {
+	if err := svc.validateRegister(ctx, ud); err != nil {
 		return 0, err
 	}
 
+	// 密码加密
+	if err := ud.EncryptPassword(svc.pwdHasher); err != nil {
+		return 0, err
+	}
+	// 保存用户
+	uid, err := svc.userRepo.Create(ctx, ud)
+	if err != nil {
+		return 0, err
+	}
+	if err = svc.pwdPolicySvc.Record(ctx, uid, ud.UserPassword); err != nil {
+		hlog.CtxWarnf(ctx, "record password history failed, uid=%d, err=%v", uid, err)
+	}
+	return uid, nil
+}
+
+func (svc *userService) CheckRegister(ctx context.Context, ud domain.User) (string, error) {
+	_, reason, err := svc.checkRegister(ctx, ud)
+	return reason, err
+}
+
+// validateRegister 校验注册信息, 不合法时返回带有原因的错误码
+func (svc *userService) validateRegister(ctx context.Context, ud domain.User) error {
+	// 参数不能为空
+	if err := ud.ValidateRegisterParameters(); err != nil {
+		return err
+	}
+	e, reason, err := svc.checkRegister(ctx, ud)
+	if err != nil {
+		return err
+	}
+	if e != nil {
+		return e.SetDescription(reason)
+	}
+	return nil
+}
+
+// checkRegister 校验注册信息, 不合法时返回对应的错误码和原因, 不修改错误码中的描述
+func (svc *userService) checkRegister(ctx context.Context, ud domain.User) (*errno.Errno, string, error) {
+	if reason := ud.CheckRegisterParameters(); reason != "" {
+		return errno.ErrParameterInvalid, reason, nil
+	}
+	reason, err := svc.pwdPolicySvc.Check(ctx, domain.User{UserAccount: ud.UserAccount}, ud.UserPassword)
+	if err != nil {
+		return nil, "", err
+	}
+	if reason != "" {
+		return errno.ErrParameterInvalid, reason, nil
+	}
+
 	// 判断账号是否已注册
 	count, err := svc.userRepo.CountByAccount(ctx, ud.UserAccount)
 	if err != nil {
-		return 0, err
+		return nil, "", err
 	}
 	if count > 0 {
-		return 0, errno.ErrEntityExists.SetDescription("账号已存在")
+		return errno.ErrEntityExists, "账号已存在", nil
 	}
 
 	// 判断星球编号是否已注册
 	count, err = svc.userRepo.CountByPlanetCode(ctx, ud.PlanetCode)
 	if err != nil {
-		return 0, err
+		return nil, "", err
 	}
 	if count > 0 {
-		return 0, errno.ErrEntityExists.SetDescription("星球编号已存在")
+		return errno.ErrEntityExists, "星球编号已存在", nil
 	}
 
-	// 密码加密
-	ud.EncryptPassword()
-	// 保存用户
-	return svc.userRepo.Create(ctx, ud)
+	// 判断邮箱是否已注册
+	if ud.Email != "" {
+		count, err = svc.userRepo.CountByEmail(ctx, ud.Email)
+		if err != nil {
+			return nil, "", err
+		}
+		if count > 0 {
+			return errno.ErrEntityExists, "邮箱已被注册", nil
+		}
+	}
+	return nil, "", nil
 }
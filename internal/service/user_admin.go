@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/repository"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//go:generate mockgen -source=./user_admin.go -package=svcmocks -destination=./mocks/user_admin.mock.go UserAdminService
+type UserAdminService interface {
+	// Create 创建用户, 可以指定角色和状态, 校验规则与注册相同
+	Create(ctx context.Context, op domain.Operator, ud domain.User) (int64, error)
+	// Freeze 冻结用户并使其全部登录会话失效, until 为零值时永久冻结
+	Freeze(ctx context.Context, op domain.Operator, uid int64, reason string, until time.Time) error
+	Unfreeze(ctx context.Context, op domain.Operator, uid int64) error
+	// ChangeRole 修改用户角色, 不能撤销最后一个管理员
+	ChangeRole(ctx context.Context, op domain.Operator, uid int64, role int32) error
+	// UpdateProfile 修改任意用户的资料, 只修改 profile 中不为 nil 的字段
+	UpdateProfile(ctx context.Context, op domain.Operator, uid int64, profile domain.UserProfile) (domain.User, error)
+}
+
+func NewUserAdminService(userRepo repository.UserRepository, auditRepo repository.AuditLogRepository,
+	userSvc UserService, profileSvc ProfileService, loginStateSvc LoginStateService,
+	sessionSvc SessionService, defaultAvatarSvc DefaultAvatarService) UserAdminService {
+	return &userAdminService{
+		userRepo:         userRepo,
+		auditRepo:        auditRepo,
+		userSvc:          userSvc,
+		profileSvc:       profileSvc,
+		loginStateSvc:    loginStateSvc,
+		sessionSvc:       sessionSvc,
+		defaultAvatarSvc: defaultAvatarSvc,
+	}
+}
+
+type userAdminService struct {
+	userRepo         repository.UserRepository
+	auditRepo        repository.AuditLogRepository
+	userSvc          UserService
+	profileSvc       ProfileService
+	loginStateSvc    LoginStateService
+	sessionSvc       SessionService
+	defaultAvatarSvc DefaultAvatarService
+}
+
+func (u *userAdminService) Create(ctx context.Context, op domain.Operator, ud domain.User) (int64, error) {
+	if ud.UserRole != 0 && ud.UserRole != constants.AdminRole {
+		return 0, errno.ErrParameterInvalid.SetDescription("角色只能是 0-普通用户 1-管理员")
+	}
+	if ud.UserStatus != 0 && ud.UserStatus != constants.UserStatusDisabled {
+		return 0, errno.ErrParameterInvalid.SetDescription("状态只能是 0-正常 1-冻结")
+	}
+	if ud.UserStatus == constants.UserStatusDisabled {
+		ud.FreezeReason = "管理员创建时冻结"
+	}
+	// 管理员设置的初始密码不需要再次确认
+	ud.CheckPassword = ud.UserPassword
+	if ud.Username == "" {
+		ud.Username = strings.ToUpper(ud.UserAccount)
+	}
+	ud.AvatarURL = u.defaultAvatarSvc.URL(ud.UserAccount, ud.Username)
+
+	uid, err := u.userSvc.Register(ctx, ud)
+	if err != nil {
+		return 0, err
+	}
+	u.audit(ctx, op, uid, domain.AuditActionUserCreate, fmt.Sprintf("role=%d, status=%d", ud.UserRole, ud.UserStatus))
+	return uid, nil
+}
+
+func (u *userAdminService) Freeze(ctx context.Context, op domain.Operator, uid int64, reason string, until time.Time) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > 512 {
+		return errno.ErrParameterInvalid.SetDescription("冻结原因不能为空且不能超过 512 个字符")
+	}
+	if !until.IsZero() && !until.After(time.Now()) {
+		return errno.ErrParameterInvalid.SetDescription("冻结截止时间必须晚于当前时间")
+	}
+	if uid == op.ID {
+		return errno.ErrParameterInvalid.SetDescription("不能冻结本人")
+	}
+
+	if _, err := u.userSvc.GetCurrentUser(ctx, uid); err != nil {
+		return err
+	}
+	ok, err := u.userRepo.Freeze(ctx, uid, reason, until)
+	if err != nil {
+		return errno.ErrDBFailed
+	}
+	if !ok {
+		return errno.ErrForbidden.SetDescription("不能冻结最后一个管理员")
+	}
+	// 立即下线
+	if err = u.loginStateSvc.Invalidate(ctx, uid); err != nil {
+		return err
+	}
+	if err = u.sessionSvc.RevokeAll(ctx, uid); err != nil {
+		return err
+	}
+
+	detail := reason
+	if !until.IsZero() {
+		detail = fmt.Sprintf("%s, until=%s", reason, until.Format(time.RFC3339))
+	}
+	u.audit(ctx, op, uid, domain.AuditActionUserFreeze, detail)
+	return nil
+}
+
+func (u *userAdminService) Unfreeze(ctx context.Context, op domain.Operator, uid int64) error {
+	ok, err := u.userRepo.Unfreeze(ctx, uid)
+	if err != nil {
+		return errno.ErrDBFailed
+	}
+	if !ok {
+		return errno.ErrParameterInvalid.SetDescription("用户不存在或未被冻结")
+	}
+	if err = u.loginStateSvc.Invalidate(ctx, uid); err != nil {
+		return err
+	}
+	u.audit(ctx, op, uid, domain.AuditActionUserUnfreeze, "")
+	return nil
+}
+
+func (u *userAdminService) ChangeRole(ctx context.Context, op domain.Operator, uid int64, role int32) error {
+	if role != 0 && role != constants.AdminRole {
+		return errno.ErrParameterInvalid.SetDescription("角色只能是 0-普通用户 1-管理员")
+	}
+	user, err := u.userSvc.GetCurrentUser(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if user.UserRole == role {
+		return nil
+	}
+	ok, err := u.userRepo.UpdateRole(ctx, uid, role)
+	if err != nil {
+		return errno.ErrDBFailed
+	}
+	if !ok {
+		return errno.ErrForbidden.SetDescription("不能撤销最后一个管理员")
+	}
+	// 角色在每次请求时重新加载, 清除缓存即可立即生效
+	if err = u.loginStateSvc.Invalidate(ctx, uid); err != nil {
+		return err
+	}
+	u.audit(ctx, op, uid, domain.AuditActionRoleChange, fmt.Sprintf("role: %d -> %d", user.UserRole, role))
+	return nil
+}
+
+func (u *userAdminService) UpdateProfile(ctx context.Context, op domain.Operator, uid int64,
+	profile domain.UserProfile) (domain.User, error) {
+	user, err := u.profileSvc.ForceUpdateProfile(ctx, uid, profile)
+	if err != nil {
+		return domain.User{}, err
+	}
+	u.audit(ctx, op, uid, domain.AuditActionProfileUpdate, strings.Join(profileFields(profile), ","))
+	return user, nil
+}
+
+// audit 审计日志写入失败不影响操作结果
+func (u *userAdminService) audit(ctx context.Context, op domain.Operator, uid int64, action, detail string) {
+	err := u.auditRepo.Create(ctx, domain.AuditLog{
+		UserID:     uid,
+		OperatorID: op.ID,
+		Action:     action,
+		Detail:     detail,
+		IP:         op.IP,
+		UserAgent:  op.UserAgent,
+	})
+	if err != nil {
+		hlog.CtxErrorf(ctx, "record audit log failed, uid=%d, action=%s, err=%v", uid, action, err)
+	}
+}
+
+// profileFields 返回修改了的字段名
+func profileFields(profile domain.UserProfile) []string {
+	var fields []string
+	if profile.Username != nil {
+		fields = append(fields, "username")
+	}
+	if profile.AvatarURL != nil {
+		fields = append(fields, "avatar_url")
+	}
+	if profile.Gender != nil {
+		fields = append(fields, "gender")
+	}
+	if profile.Phone != nil {
+		fields = append(fields, "phone")
+	}
+	if profile.Email != nil {
+		fields = append(fields, "email")
+	}
+	return fields
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/domain"
+	repomocks "github.com/coderlewin/ucenter/internal/repository/mocks"
+	svcmocks "github.com/coderlewin/ucenter/internal/service/mocks"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type userAdminTestDeps struct {
+	userRepo      *repomocks.MockUserRepository
+	auditRepo     *repomocks.MockAuditLogRepository
+	userSvc       *svcmocks.MockUserService
+	loginStateSvc *svcmocks.MockLoginStateService
+	sessionSvc    *svcmocks.MockSessionService
+}
+
+func newTestUserAdminService(ctrl *gomock.Controller) (UserAdminService, userAdminTestDeps) {
+	deps := userAdminTestDeps{
+		userRepo:      repomocks.NewMockUserRepository(ctrl),
+		auditRepo:     repomocks.NewMockAuditLogRepository(ctrl),
+		userSvc:       svcmocks.NewMockUserService(ctrl),
+		loginStateSvc: svcmocks.NewMockLoginStateService(ctrl),
+		sessionSvc:    svcmocks.NewMockSessionService(ctrl),
+	}
+	svc := NewUserAdminService(deps.userRepo, deps.auditRepo, deps.userSvc, svcmocks.NewMockProfileService(ctrl),
+		deps.loginStateSvc, deps.sessionSvc, svcmocks.NewMockDefaultAvatarService(ctrl))
+	return svc, deps
+}
+
+// expectAudit 预期写入一条审计日志
+func expectAudit(t *testing.T, deps userAdminTestDeps, uid int64, action string) {
+	deps.auditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, log domain.AuditLog) error {
+		assert.Equal(t, uid, log.UserID)
+		assert.Equal(t, int64(1), log.OperatorID)
+		assert.Equal(t, action, log.Action)
+		return nil
+	})
+}
+
+func Test_userAdminService_Freeze(t *testing.T) {
+	until := time.Now().Add(time.Hour)
+	testCases := []struct {
+		name string
+
+		mock   func(t *testing.T, deps userAdminTestDeps)
+		uid    int64
+		reason string
+		until  time.Time
+
+		wantErr  error
+		wantDesc string
+	}{
+		{
+			name: "冻结成功, 立即下线",
+			mock: func(t *testing.T, deps userAdminTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(2)).Return(domain.User{ID: 2}, nil)
+				deps.userRepo.EXPECT().Freeze(gomock.Any(), int64(2), "违规", until).Return(true, nil)
+				deps.loginStateSvc.EXPECT().Invalidate(gomock.Any(), int64(2)).Return(nil)
+				deps.sessionSvc.EXPECT().RevokeAll(gomock.Any(), int64(2)).Return(nil)
+				expectAudit(t, deps, 2, domain.AuditActionUserFreeze)
+			},
+			uid:    2,
+			reason: " 违规 ",
+			until:  until,
+		},
+		{
+			name: "最后一个管理员",
+			mock: func(t *testing.T, deps userAdminTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(2)).
+					Return(domain.User{ID: 2, UserRole: constants.AdminRole}, nil)
+				deps.userRepo.EXPECT().Freeze(gomock.Any(), int64(2), "违规", time.Time{}).Return(false, nil)
+			},
+			uid:      2,
+			reason:   "违规",
+			wantErr:  errno.ErrForbidden,
+			wantDesc: "不能冻结最后一个管理员",
+		},
+		{
+			name:     "不能冻结本人",
+			mock:     func(t *testing.T, deps userAdminTestDeps) {},
+			uid:      1,
+			reason:   "违规",
+			wantErr:  errno.ErrParameterInvalid,
+			wantDesc: "不能冻结本人",
+		},
+		{
+			name:     "截止时间已过",
+			mock:     func(t *testing.T, deps userAdminTestDeps) {},
+			uid:      2,
+			reason:   "违规",
+			until:    time.Now().Add(-time.Minute),
+			wantErr:  errno.ErrParameterInvalid,
+			wantDesc: "冻结截止时间必须晚于当前时间",
+		},
+		{
+			name: "数据库错误",
+			mock: func(t *testing.T, deps userAdminTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(2)).Return(domain.User{ID: 2}, nil)
+				deps.userRepo.EXPECT().Freeze(gomock.Any(), int64(2), "违规", time.Time{}).Return(false, errors.New("db error"))
+			},
+			uid:     2,
+			reason:  "违规",
+			wantErr: errno.ErrDBFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, deps := newTestUserAdminService(ctrl)
+			tc.mock(t, deps)
+
+			err := svc.Freeze(context.Background(), domain.Operator{ID: 1}, tc.uid, tc.reason, tc.until)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantDesc != "" {
+				assert.Equal(t, tc.wantDesc, tc.wantErr.(*errno.Errno).Desc)
+			}
+		})
+	}
+}
+
+func Test_userAdminService_Unfreeze(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(t *testing.T, deps userAdminTestDeps)
+
+		wantErr error
+	}{
+		{
+			name: "解冻成功",
+			mock: func(t *testing.T, deps userAdminTestDeps) {
+				deps.userRepo.EXPECT().Unfreeze(gomock.Any(), int64(2)).Return(true, nil)
+				deps.loginStateSvc.EXPECT().Invalidate(gomock.Any(), int64(2)).Return(nil)
+				expectAudit(t, deps, 2, domain.AuditActionUserUnfreeze)
+			},
+		},
+		{
+			name: "用户未被冻结",
+			mock: func(t *testing.T, deps userAdminTestDeps) {
+				deps.userRepo.EXPECT().Unfreeze(gomock.Any(), int64(2)).Return(false, nil)
+			},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name: "数据库错误",
+			mock: func(t *testing.T, deps userAdminTestDeps) {
+				deps.userRepo.EXPECT().Unfreeze(gomock.Any(), int64(2)).Return(false, errors.New("db error"))
+			},
+			wantErr: errno.ErrDBFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, deps := newTestUserAdminService(ctrl)
+			tc.mock(t, deps)
+
+			err := svc.Unfreeze(context.Background(), domain.Operator{ID: 1}, 2)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_userAdminService_ChangeRole(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(t *testing.T, deps userAdminTestDeps)
+		role int32
+
+		wantErr  error
+		wantDesc string
+	}{
+		{
+			name: "设为管理员",
+			mock: func(t *testing.T, deps userAdminTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(2)).Return(domain.User{ID: 2}, nil)
+				deps.userRepo.EXPECT().UpdateRole(gomock.Any(), int64(2), int32(constants.AdminRole)).Return(true, nil)
+				deps.loginStateSvc.EXPECT().Invalidate(gomock.Any(), int64(2)).Return(nil)
+				expectAudit(t, deps, 2, domain.AuditActionRoleChange)
+			},
+			role: constants.AdminRole,
+		},
+		{
+			name: "角色未变化",
+			mock: func(t *testing.T, deps userAdminTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(2)).
+					Return(domain.User{ID: 2, UserRole: constants.AdminRole}, nil)
+			},
+			role: constants.AdminRole,
+		},
+		{
+			name: "撤销最后一个管理员",
+			mock: func(t *testing.T, deps userAdminTestDeps) {
+				deps.userSvc.EXPECT().GetCurrentUser(gomock.Any(), int64(2)).
+					Return(domain.User{ID: 2, UserRole: constants.AdminRole}, nil)
+				deps.userRepo.EXPECT().UpdateRole(gomock.Any(), int64(2), int32(0)).Return(false, nil)
+			},
+			role:     0,
+			wantErr:  errno.ErrForbidden,
+			wantDesc: "不能撤销最后一个管理员",
+		},
+		{
+			name:     "非法角色",
+			mock:     func(t *testing.T, deps userAdminTestDeps) {},
+			role:     2,
+			wantErr:  errno.ErrParameterInvalid,
+			wantDesc: "角色只能是 0-普通用户 1-管理员",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, deps := newTestUserAdminService(ctrl)
+			tc.mock(t, deps)
+
+			err := svc.ChangeRole(context.Background(), domain.Operator{ID: 1}, 2, tc.role)
+			assert.Equal(t, tc.wantErr, err)
+			if tc.wantDesc != "" {
+				assert.Equal(t, tc.wantDesc, tc.wantErr.(*errno.Errno).Desc)
+			}
+		})
+	}
+}
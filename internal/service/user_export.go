@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/repository"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/spreadsheet"
+	"io"
+	"strings"
+)
+
+// exportBatchSize 每次从数据库查询的用户数量
+const exportBatchSize = 500
+
+// exportColumns 允许导出的列及取值方式, 密码等敏感字段不在其中, 无法导出
+var exportColumns = map[string]func(user domain.User, unmask bool) any{
+	"id":            func(user domain.User, _ bool) any { return user.ID },
+	"username":      func(user domain.User, _ bool) any { return user.Username },
+	"user_account":  func(user domain.User, _ bool) any { return user.UserAccount },
+	"avatar_url":    func(user domain.User, _ bool) any { return user.AvatarURL },
+	"gender":        func(user domain.User, _ bool) any { return user.Gender },
+	"phone":         func(user domain.User, unmask bool) any { return maskIf(maskPhone, user.Phone, !unmask) },
+	"email":         func(user domain.User, unmask bool) any { return maskIf(maskEmail, user.Email, !unmask) },
+	"user_status":   func(user domain.User, _ bool) any { return user.UserStatus },
+	"user_role":     func(user domain.User, _ bool) any { return user.UserRole },
+	"planet_code":   func(user domain.User, _ bool) any { return user.PlanetCode },
+	"freeze_reason": func(user domain.User, _ bool) any { return user.FreezeReason },
+	"freeze_until":  func(user domain.User, _ bool) any { return user.FreezeUntil },
+	"create_time":   func(user domain.User, _ bool) any { return user.CreateTime },
+	"update_time":   func(user domain.User, _ bool) any { return user.UpdateTime },
+}
+
+// defaultExportColumns 未指定列时导出的列
+var defaultExportColumns = []string{
+	"id", "username", "user_account", "gender", "phone", "email",
+	"user_status", "user_role", "planet_code", "create_time",
+}
+
+//go:generate mockgen -source=./user_export.go -package=svcmocks -destination=./mocks/user_export.mock.go UserExportService
+type UserExportService interface {
+	// Prepare 校验导出参数并补全默认值, 需要在开始写入响应之前调用
+	Prepare(req domain.UserExport) (domain.UserExport, error)
+	// Export 按 id 顺序分批查询用户并写入 w, 不会一次性加载全部用户
+	Export(ctx context.Context, op domain.Operator, req domain.UserExport, w io.Writer) error
+}
+
+func NewUserExportService(userRepo repository.UserRepository, auditRepo repository.AuditLogRepository) UserExportService {
+	return &userExportService{userRepo: userRepo, auditRepo: auditRepo}
+}
+
+type userExportService struct {
+	userRepo  repository.UserRepository
+	auditRepo repository.AuditLogRepository
+}
+
+func (u *userExportService) Prepare(req domain.UserExport) (domain.UserExport, error) {
+	if err := req.Filter.Validate(); err != nil {
+		return req, err
+	}
+	if req.Format == "" {
+		req.Format = spreadsheet.FormatCSV
+	}
+	switch req.Format {
+	case spreadsheet.FormatCSV, spreadsheet.FormatXLSX, spreadsheet.FormatJSONL:
+	default:
+		return req, errno.ErrParameterInvalid.SetDescription("导出格式只能是 csv、xlsx 或 jsonl")
+	}
+	if len(req.Columns) == 0 {
+		req.Columns = defaultExportColumns
+		return req, nil
+	}
+	seen := make(map[string]struct{}, len(req.Columns))
+	columns := make([]string, 0, len(req.Columns))
+	for _, col := range req.Columns {
+		col = strings.TrimSpace(col)
+		if _, ok := exportColumns[col]; !ok {
+			return req, errno.ErrParameterInvalid.SetDescription("不支持导出的列 %s", col)
+		}
+		if _, ok := seen[col]; ok {
+			continue
+		}
+		seen[col] = struct{}{}
+		columns = append(columns, col)
+	}
+	req.Columns = columns
+	return req, nil
+}
+
+func (u *userExportService) Export(ctx context.Context, op domain.Operator, req domain.UserExport, w io.Writer) (err error) {
+	req, err = u.Prepare(req)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	// 导出的是批量的个人信息, 需要留痕. 中途失败时已经写出的数据同样需要记录
+	defer func() {
+		detail := fmt.Sprintf("format=%s, count=%d, unmask=%t, username=%s, columns=%s",
+			req.Format, count, req.Unmask, req.Filter.Username, strings.Join(req.Columns, ","))
+		if err != nil {
+			detail += ", error=" + err.Error()
+		}
+		auditErr := u.auditRepo.Create(ctx, domain.AuditLog{
+			OperatorID: op.ID,
+			Action:     domain.AuditActionUserExport,
+			Detail:     detail,
+			IP:         op.IP,
+			UserAgent:  op.UserAgent,
+		})
+		if auditErr != nil {
+			hlog.CtxErrorf(ctx, "record audit log failed, action=%s, err=%v", domain.AuditActionUserExport, auditErr)
+		}
+	}()
+
+	writer, err := spreadsheet.NewWriter(w, req.Format, req.Columns)
+	if err != nil {
+		return err
+	}
+	var afterID int64
+	values := make([]any, len(req.Columns))
+	for {
+		var users []domain.User
+		users, err = u.userRepo.ListBatch(ctx, req.Filter, afterID, exportBatchSize)
+		if err != nil {
+			return errno.ErrDBFailed
+		}
+		for _, user := range users {
+			for i, col := range req.Columns {
+				values[i] = exportColumns[col](user, req.Unmask)
+			}
+			if err = writer.Write(values); err != nil {
+				return err
+			}
+			count++
+		}
+		if len(users) < exportBatchSize {
+			break
+		}
+		afterID = users[len(users)-1].ID
+	}
+	return writer.Close()
+}
+
+func maskIf(mask func(string) string, s string, enabled bool) string {
+	if !enabled {
+		return s
+	}
+	return mask(s)
+}
+
+// maskPhone 只保留最后 4 位和开头的国家码及号段, 如 +86138****5678
+func maskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return strings.Repeat("*", len(phone))
+	}
+	keep := max(len(phone)-8, 0)
+	return phone[:keep] + strings.Repeat("*", len(phone)-keep-4) + phone[len(phone)-4:]
+}
+
+// maskEmail 只保留用户名的第一个字符和域名, 如 c***@example.com
+func maskEmail(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at <= 0 {
+		return maskPhone(email)
+	}
+	return email[:1] + "***" + email[at:]
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/coderlewin/ucenter/internal/domain"
+	repomocks "github.com/coderlewin/ucenter/internal/repository/mocks"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/spreadsheet"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_userExportService_Prepare(t *testing.T) {
+	testCases := []struct {
+		name string
+		req  domain.UserExport
+
+		wantColumns []string
+		wantFormat  string
+		wantErr     error
+	}{
+		{
+			name:        "使用默认列和格式",
+			wantColumns: defaultExportColumns,
+			wantFormat:  spreadsheet.FormatCSV,
+		},
+		{
+			name:        "去掉重复的列",
+			req:         domain.UserExport{Format: spreadsheet.FormatJSONL, Columns: []string{"id", " phone", "id"}},
+			wantColumns: []string{"id", "phone"},
+			wantFormat:  spreadsheet.FormatJSONL,
+		},
+		{
+			name:    "密码不在允许导出的列中",
+			req:     domain.UserExport{Columns: []string{"id", "user_password"}},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name:    "不支持的格式",
+			req:     domain.UserExport{Format: "pdf"},
+			wantErr: errno.ErrParameterInvalid,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewUserExportService(nil, nil)
+			req, err := svc.Prepare(tc.req)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantColumns, req.Columns)
+			assert.Equal(t, tc.wantFormat, req.Format)
+		})
+	}
+}
+
+// exportTestUsers 返回 id 从 from 开始的 n 个用户
+func exportTestUsers(from, n int) []domain.User {
+	users := make([]domain.User, 0, n)
+	for i := from; i < from+n; i++ {
+		users = append(users, domain.User{
+			ID:           int64(i),
+			UserAccount:  "user" + strconv.Itoa(i),
+			Phone:        "+8613800138000",
+			Email:        "coderlu@example.com",
+			UserPassword: "$2a$10$hash",
+		})
+	}
+	return users
+}
+
+func Test_userExportService_Export(t *testing.T) {
+	columns := []string{"id", "user_account", "phone", "email"}
+	testCases := []struct {
+		name string
+
+		mock   func(userRepo *repomocks.MockUserRepository)
+		unmask bool
+
+		wantErr    error
+		wantRows   int
+		wantRow    []string
+		wantDetail string
+	}{
+		{
+			name: "跨过分批边界, 默认隐藏手机号和邮箱",
+			mock: func(userRepo *repomocks.MockUserRepository) {
+				userRepo.EXPECT().ListBatch(gomock.Any(), gomock.Any(), int64(0), exportBatchSize).
+					Return(exportTestUsers(1, exportBatchSize), nil)
+				userRepo.EXPECT().ListBatch(gomock.Any(), gomock.Any(), int64(exportBatchSize), exportBatchSize).
+					Return(exportTestUsers(exportBatchSize+1, 1), nil)
+			},
+			wantRows: exportBatchSize + 1,
+			// 隐藏后的手机号不再是数字, 写入 CSV 时按公式转义
+			wantRow:    []string{"1", "user1", "'+86138****8000", "c***@example.com"},
+			wantDetail: "format=csv, count=501, unmask=false",
+		},
+		{
+			name: "恰好一批时再查询一次",
+			mock: func(userRepo *repomocks.MockUserRepository) {
+				userRepo.EXPECT().ListBatch(gomock.Any(), gomock.Any(), int64(0), exportBatchSize).
+					Return(exportTestUsers(1, exportBatchSize), nil)
+				userRepo.EXPECT().ListBatch(gomock.Any(), gomock.Any(), int64(exportBatchSize), exportBatchSize).
+					Return(nil, nil)
+			},
+			unmask:     true,
+			wantRows:   exportBatchSize,
+			wantRow:    []string{"1", "user1", "+8613800138000", "coderlu@example.com"},
+			wantDetail: "format=csv, count=500, unmask=true",
+		},
+		{
+			name: "中途失败也记录审计日志",
+			mock: func(userRepo *repomocks.MockUserRepository) {
+				userRepo.EXPECT().ListBatch(gomock.Any(), gomock.Any(), int64(0), exportBatchSize).
+					Return(exportTestUsers(1, exportBatchSize), nil)
+				userRepo.EXPECT().ListBatch(gomock.Any(), gomock.Any(), int64(exportBatchSize), exportBatchSize).
+					Return(nil, errors.New("db error"))
+			},
+			wantErr:    errno.ErrDBFailed,
+			wantDetail: "format=csv, count=500, unmask=false",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userRepo := repomocks.NewMockUserRepository(ctrl)
+			auditRepo := repomocks.NewMockAuditLogRepository(ctrl)
+			tc.mock(userRepo)
+			var audit domain.AuditLog
+			auditRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, log domain.AuditLog) error {
+				audit = log
+				return nil
+			})
+			svc := NewUserExportService(userRepo, auditRepo)
+
+			var buf bytes.Buffer
+			op := domain.Operator{ID: 1, IP: "10.0.0.1"}
+			err := svc.Export(context.Background(), op, domain.UserExport{Columns: columns, Unmask: tc.unmask}, &buf)
+			assert.Equal(t, tc.wantErr, err)
+
+			assert.Equal(t, domain.AuditActionUserExport, audit.Action)
+			assert.Equal(t, int64(1), audit.OperatorID)
+			assert.True(t, strings.HasPrefix(audit.Detail, tc.wantDetail), audit.Detail)
+			assert.Equal(t, err != nil, strings.Contains(audit.Detail, "error="), audit.Detail)
+			if err != nil {
+				return
+			}
+
+			records, err := spreadsheet.Read(buf.Bytes(), exportBatchSize+2)
+			require.NoError(t, err)
+			require.Len(t, records, tc.wantRows+1)
+			assert.Equal(t, columns, records[0])
+			assert.Equal(t, tc.wantRow, records[1])
+			assert.NotContains(t, buf.String(), "$2a$10$hash")
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/spreadsheet"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UserImportOptions 定义批量导入用户的选项
+type UserImportOptions struct {
+	MaxRows       int           // 单个文件允许的最大数据行数
+	JobExpiration time.Duration // 任务结束后保留进度和错误报告的时长
+}
+
+// importColumns 表头到字段的映射, 同时支持英文和中文表头
+var importColumns = map[string]string{
+	"account":     "account",
+	"账号":          "account",
+	"password":    "password",
+	"密码":          "password",
+	"planet_code": "planet_code",
+	"星球编号":        "planet_code",
+	"username":    "username",
+	"用户名":         "username",
+	"email":       "email",
+	"邮箱":          "email",
+	"role":        "role",
+	"角色":          "role",
+}
+
+//go:generate mockgen -source=./user_import.go -package=svcmocks -destination=./mocks/user_import.mock.go UserImportService
+type UserImportService interface {
+	// Import 解析 CSV 或 XLSX 文件并在后台逐行校验和导入, 返回刚创建的任务.
+	// 第一行为表头, 必须包含 account、password 和 planet_code 列. dryRun 为 true 时只校验不导入
+	Import(ctx context.Context, op domain.Operator, data []byte, dryRun bool) (domain.ImportJob, error)
+	// GetJob 查询任务的进度和错误报告
+	GetJob(ctx context.Context, id string) (domain.ImportJob, error)
+}
+
+func NewUserImportService(opts UserImportOptions, cmd redis.Cmdable, userSvc UserService,
+	userAdminSvc UserAdminService) UserImportService {
+	return &userImportService{opts: opts, cmd: cmd, userSvc: userSvc, userAdminSvc: userAdminSvc}
+}
+
+type userImportService struct {
+	opts         UserImportOptions
+	cmd          redis.Cmdable
+	userSvc      UserService
+	userAdminSvc UserAdminService
+}
+
+// importRowError 保存在 redis 中的单行错误
+type importRowError struct {
+	Row     int    `json:"row"`
+	Account string `json:"account"`
+	Message string `json:"message"`
+}
+
+// importRow 表格中的一行数据
+type importRow struct {
+	num  int
+	user domain.User
+	err  string // 解析阶段发现的错误
+}
+
+func (u *userImportService) Import(ctx context.Context, op domain.Operator, data []byte, dryRun bool) (domain.ImportJob, error) {
+	records, err := spreadsheet.Read(data, u.opts.MaxRows+1)
+	if errors.Is(err, spreadsheet.ErrTooManyRows) {
+		return domain.ImportJob{}, errno.ErrParameterInvalid.SetDescription("单次最多导入 %d 行", u.opts.MaxRows)
+	}
+	if err != nil {
+		return domain.ImportJob{}, errno.ErrParameterInvalid.SetDescription("文件无法解析, 只支持 UTF-8 编码的 CSV 和 XLSX")
+	}
+	rows, err := parseImportRows(records)
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+
+	job := domain.ImportJob{
+		ID:         uuid.NewString(),
+		DryRun:     dryRun,
+		Status:     domain.ImportStatusRunning,
+		OperatorID: op.ID,
+		Total:      len(rows),
+		CreateTime: time.Now(),
+	}
+	key := importJobKey(job.ID)
+	pipe := u.cmd.TxPipeline()
+	pipe.HSet(ctx, key, map[string]any{
+		"dry_run":     strconv.FormatBool(dryRun),
+		"status":      job.Status,
+		"operator_id": op.ID,
+		"total":       job.Total,
+		"processed":   0,
+		"succeeded":   0,
+		"failed":      0,
+		"create_time": job.CreateTime.UnixMilli(),
+	})
+	// 进程退出导致任务中断时, 进度也会在过期后被清理
+	pipe.Expire(ctx, key, u.opts.JobExpiration)
+	if _, err = pipe.Exec(ctx); err != nil {
+		return domain.ImportJob{}, err
+	}
+
+	go u.run(context.WithoutCancel(ctx), op, job, rows)
+	return job, nil
+}
+
+func (u *userImportService) GetJob(ctx context.Context, id string) (domain.ImportJob, error) {
+	fields, err := u.cmd.HGetAll(ctx, importJobKey(id)).Result()
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+	if len(fields) == 0 {
+		return domain.ImportJob{}, errno.ErrEntityNull.SetDescription("导入任务不存在或已过期")
+	}
+	items, err := u.cmd.LRange(ctx, importErrorsKey(id), 0, -1).Result()
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+
+	atoi := func(field string) int {
+		v, _ := strconv.Atoi(fields[field])
+		return v
+	}
+	operatorID, _ := strconv.ParseInt(fields["operator_id"], 10, 64)
+	job := domain.ImportJob{
+		ID:         id,
+		DryRun:     fields["dry_run"] == "true",
+		Status:     fields["status"],
+		OperatorID: operatorID,
+		Total:      atoi("total"),
+		Processed:  atoi("processed"),
+		Succeeded:  atoi("succeeded"),
+		Failed:     atoi("failed"),
+		Errors:     make([]domain.ImportRowError, 0, len(items)),
+		CreateTime: time.UnixMilli(int64(atoi("create_time"))),
+	}
+	if finish := atoi("finish_time"); finish > 0 {
+		job.FinishTime = time.UnixMilli(int64(finish))
+	}
+	for _, item := range items {
+		var rowErr importRowError
+		if err = json.Unmarshal([]byte(item), &rowErr); err != nil {
+			return domain.ImportJob{}, err
+		}
+		job.Errors = append(job.Errors, domain.ImportRowError(rowErr))
+	}
+	return job, nil
+}
+
+// run 逐行校验和导入, 单行失败不影响其他行
+func (u *userImportService) run(ctx context.Context, op domain.Operator, job domain.ImportJob, rows []importRow) {
+	key := importJobKey(job.ID)
+	status := domain.ImportStatusFailed
+	defer func() {
+		if r := recover(); r != nil {
+			hlog.CtxErrorf(ctx, "import users panic, job=%s, err=%v", job.ID, r)
+		}
+		pipe := u.cmd.TxPipeline()
+		pipe.HSet(ctx, key, "status", status, "finish_time", time.Now().UnixMilli())
+		// 从结束时开始计算保留时长
+		pipe.Expire(ctx, key, u.opts.JobExpiration)
+		pipe.Expire(ctx, importErrorsKey(job.ID), u.opts.JobExpiration)
+		if _, err := pipe.Exec(ctx); err != nil {
+			hlog.CtxErrorf(ctx, "finish import job failed, job=%s, err=%v", job.ID, err)
+		}
+	}()
+
+	// 文件内部的重复也需要检查, 数据库中的重复由注册校验检查
+	accounts := make(map[string]int, len(rows))
+	planetCodes := make(map[string]int, len(rows))
+	emails := make(map[string]int, len(rows))
+	for _, row := range rows {
+		msg := row.err
+		if msg == "" {
+			msg = checkDuplicate(accounts, row.user.UserAccount, row.num, "账号") +
+				checkDuplicate(planetCodes, row.user.PlanetCode, row.num, "星球编号") +
+				checkDuplicate(emails, row.user.Email, row.num, "邮箱")
+		}
+		if msg == "" {
+			msg = u.importRow(ctx, op, job, row.user)
+		}
+
+		pipe := u.cmd.TxPipeline()
+		pipe.HIncrBy(ctx, key, "processed", 1)
+		if msg == "" {
+			pipe.HIncrBy(ctx, key, "succeeded", 1)
+		} else {
+			data, _ := json.Marshal(importRowError{Row: row.num, Account: row.user.UserAccount, Message: msg})
+			pipe.HIncrBy(ctx, key, "failed", 1)
+			pipe.RPush(ctx, importErrorsKey(job.ID), data)
+			pipe.Expire(ctx, importErrorsKey(job.ID), u.opts.JobExpiration)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			hlog.CtxErrorf(ctx, "update import progress failed, job=%s, err=%v", job.ID, err)
+			return
+		}
+	}
+	status = domain.ImportStatusFinished
+}
+
+// importRow 校验并导入一行, 返回错误信息, 成功时返回空字符串.
+// 后台任务与请求并发执行, 错误信息不能读取共享错误变量中的描述
+func (u *userImportService) importRow(ctx context.Context, op domain.Operator, job domain.ImportJob, ud domain.User) string {
+	ud.CheckPassword = ud.UserPassword
+	reason, err := u.userSvc.CheckRegister(ctx, ud)
+	if err == nil && reason == "" && !job.DryRun {
+		_, err = u.userAdminSvc.Create(ctx, op, ud)
+	}
+	if err == nil {
+		return reason
+	}
+	// 校验通过后仍然失败, 如账号被并发注册, 只返回不会被修改的错误信息
+	var e *errno.Errno
+	if errors.As(err, &e) {
+		return e.Msg
+	}
+	hlog.CtxErrorf(ctx, "import user failed, job=%s, account=%s, err=%v", job.ID, ud.UserAccount, err)
+	return "服务器内部错误"
+}
+
+// checkDuplicate 值在前面的行中出现过时返回错误信息
+func checkDuplicate(seen map[string]int, value string, num int, name string) string {
+	if value == "" {
+		return ""
+	}
+	if first, ok := seen[value]; ok {
+		return fmt.Sprintf("%s与第 %d 行重复", name, first)
+	}
+	seen[value] = num
+	return ""
+}
+
+// parseImportRows 按表头解析数据行, 跳过空行
+func parseImportRows(records [][]string) ([]importRow, error) {
+	if len(records) == 0 {
+		return nil, errno.ErrParameterInvalid.SetDescription("文件为空")
+	}
+	columns := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		if field, ok := importColumns[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+	for _, field := range []string{"account", "password", "planet_code"} {
+		if _, ok := columns[field]; !ok {
+			return nil, errno.ErrParameterInvalid.SetDescription("表头缺少 %s 列", field)
+		}
+	}
+
+	rows := make([]importRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		value := func(field string) string {
+			col, ok := columns[field]
+			if !ok || col >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[col])
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		row := importRow{
+			num: i + 2,
+			user: domain.User{
+				UserAccount:  value("account"),
+				UserPassword: value("password"),
+				PlanetCode:   value("planet_code"),
+				Username:     value("username"),
+				Email:        value("email"),
+			},
+		}
+		if row.user.UserAccount == "" || row.user.UserPassword == "" || row.user.PlanetCode == "" {
+			row.err = "账号、密码和星球编号不能为空"
+		}
+		switch role := value("role"); role {
+		case "", "0":
+		case strconv.Itoa(constants.AdminRole):
+			row.user.UserRole = constants.AdminRole
+		default:
+			row.err = "角色只能是 0-普通用户 1-管理员"
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, errno.ErrParameterInvalid.SetDescription("文件中没有数据")
+	}
+	return rows, nil
+}
+
+func importJobKey(id string) string {
+	return fmt.Sprintf("ucenter:users:import:%s", id)
+}
+
+func importErrorsKey(id string) string {
+	return fmt.Sprintf("ucenter:users:import:%s:errors", id)
+}
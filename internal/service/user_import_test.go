@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coderlewin/ucenter/internal/domain"
+	svcmocks "github.com/coderlewin/ucenter/internal/service/mocks"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func Test_parseImportRows(t *testing.T) {
+	testCases := []struct {
+		name    string
+		records [][]string
+		want    []importRow
+		wantErr error
+	}{
+		{
+			name: "中文表头, 跳过空行",
+			records: [][]string{
+				{"账号", "密码", "星球编号", "角色"},
+				{"coderlu", "12345678", "1", "1"},
+				{"", " ", ""},
+				{"lewin", "12345678", "2", ""},
+			},
+			want: []importRow{
+				{num: 2, user: domain.User{UserAccount: "coderlu", UserPassword: "12345678", PlanetCode: "1", UserRole: 1}},
+				{num: 4, user: domain.User{UserAccount: "lewin", UserPassword: "12345678", PlanetCode: "2"}},
+			},
+		},
+		{
+			name: "缺少字段和非法角色",
+			records: [][]string{
+				{"account", "password", "planet_code", "role", "email"},
+				{"coderlu", "", "1"},
+				{"lewin", "12345678", "2", "2", "a@b.com"},
+			},
+			want: []importRow{
+				{num: 2, user: domain.User{UserAccount: "coderlu", PlanetCode: "1"}, err: "账号、密码和星球编号不能为空"},
+				{num: 3, user: domain.User{UserAccount: "lewin", UserPassword: "12345678", PlanetCode: "2", Email: "a@b.com"},
+					err: "角色只能是 0-普通用户 1-管理员"},
+			},
+		},
+		{
+			name:    "缺少必填列",
+			records: [][]string{{"account", "password"}, {"coderlu", "12345678"}},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name:    "没有数据",
+			records: [][]string{{"account", "password", "planet_code"}},
+			wantErr: errno.ErrParameterInvalid,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rows, err := parseImportRows(tc.records)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, rows)
+		})
+	}
+}
+
+func Test_checkDuplicate(t *testing.T) {
+	seen := map[string]int{}
+	assert.Equal(t, "", checkDuplicate(seen, "coderlu", 2, "账号"))
+	assert.Equal(t, "", checkDuplicate(seen, "", 3, "账号"))
+	assert.Equal(t, "账号与第 2 行重复", checkDuplicate(seen, "coderlu", 4, "账号"))
+}
+
+// testImportCSV 第 3 行缺少密码, 第 4 行账号与第 2 行重复
+const testImportCSV = `account,password,planet_code,role
+coderlu,Abcd1234!,1,1
+lewin,,2,
+coderlu,Abcd1234!,3,
+kitety,Abcd1234!,4,
+mercury,Abcd1234!,5,
+`
+
+// accountMatcher 匹配指定账号的用户, 导入时确认密码与密码相同
+type accountMatcher string
+
+func (m accountMatcher) Matches(x any) bool {
+	ud, ok := x.(domain.User)
+	return ok && ud.UserAccount == string(m) && ud.CheckPassword == ud.UserPassword
+}
+
+func (m accountMatcher) String() string {
+	return "account is " + string(m)
+}
+
+func Test_userImportService_Import(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock   func(userSvc *svcmocks.MockUserService, userAdminSvc *svcmocks.MockUserAdminService)
+		dryRun bool
+
+		wantSucceeded int
+		wantErrors    []domain.ImportRowError
+	}{
+		{
+			name: "只校验不导入",
+			mock: func(userSvc *svcmocks.MockUserService, userAdminSvc *svcmocks.MockUserAdminService) {
+				userSvc.EXPECT().CheckRegister(gomock.Any(), accountMatcher("coderlu")).Return("", nil)
+				userSvc.EXPECT().CheckRegister(gomock.Any(), accountMatcher("kitety")).Return("星球编号已存在", nil)
+				userSvc.EXPECT().CheckRegister(gomock.Any(), accountMatcher("mercury")).Return("", nil)
+			},
+			dryRun:        true,
+			wantSucceeded: 2,
+			wantErrors: []domain.ImportRowError{
+				{Row: 3, Account: "lewin", Message: "账号、密码和星球编号不能为空"},
+				{Row: 4, Account: "coderlu", Message: "账号与第 2 行重复"},
+				{Row: 5, Account: "kitety", Message: "星球编号已存在"},
+			},
+		},
+		{
+			name: "校验通过后导入",
+			mock: func(userSvc *svcmocks.MockUserService, userAdminSvc *svcmocks.MockUserAdminService) {
+				userSvc.EXPECT().CheckRegister(gomock.Any(), accountMatcher("coderlu")).Return("", nil)
+				userSvc.EXPECT().CheckRegister(gomock.Any(), accountMatcher("kitety")).Return("", nil)
+				userSvc.EXPECT().CheckRegister(gomock.Any(), accountMatcher("mercury")).Return("", nil)
+				userAdminSvc.EXPECT().Create(gomock.Any(), domain.Operator{ID: 1}, accountMatcher("coderlu")).
+					DoAndReturn(func(ctx context.Context, op domain.Operator, ud domain.User) (int64, error) {
+						assert.Equal(t, int32(1), ud.UserRole)
+						return 2, nil
+					})
+				// 校验之后账号被并发注册, 不读取共享错误变量中的描述
+				userAdminSvc.EXPECT().Create(gomock.Any(), domain.Operator{ID: 1}, accountMatcher("kitety")).
+					Return(int64(0), errno.ErrEntityExists.SetDescription("账号已存在"))
+				userAdminSvc.EXPECT().Create(gomock.Any(), domain.Operator{ID: 1}, accountMatcher("mercury")).
+					Return(int64(0), errors.New("db error"))
+			},
+			wantSucceeded: 1,
+			wantErrors: []domain.ImportRowError{
+				{Row: 3, Account: "lewin", Message: "账号、密码和星球编号不能为空"},
+				{Row: 4, Account: "coderlu", Message: "账号与第 2 行重复"},
+				{Row: 5, Account: "kitety", Message: errno.ErrEntityExists.Msg},
+				{Row: 6, Account: "mercury", Message: "服务器内部错误"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc := svcmocks.NewMockUserService(ctrl)
+			userAdminSvc := svcmocks.NewMockUserAdminService(ctrl)
+			tc.mock(userSvc, userAdminSvc)
+			svc := NewUserImportService(UserImportOptions{MaxRows: 10, JobExpiration: time.Hour},
+				redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}), userSvc, userAdminSvc)
+
+			job, err := svc.Import(context.Background(), domain.Operator{ID: 1}, []byte(testImportCSV), tc.dryRun)
+			require.NoError(t, err)
+			assert.Equal(t, 5, job.Total)
+			require.Eventually(t, func() bool {
+				job, err = svc.GetJob(context.Background(), job.ID)
+				return err == nil && job.Status != domain.ImportStatusRunning
+			}, time.Second, 10*time.Millisecond)
+
+			assert.Equal(t, domain.ImportStatusFinished, job.Status)
+			assert.Equal(t, tc.dryRun, job.DryRun)
+			assert.Equal(t, 5, job.Processed)
+			assert.Equal(t, tc.wantSucceeded, job.Succeeded)
+			assert.Equal(t, 5-tc.wantSucceeded, job.Failed)
+			assert.Equal(t, tc.wantErrors, job.Errors)
+		})
+	}
+}
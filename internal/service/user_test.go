@@ -2,16 +2,31 @@ package service
 
 import (
 	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coderlewin/ucenter/internal/constants"
 	"github.com/coderlewin/ucenter/internal/domain"
 	"github.com/coderlewin/ucenter/internal/repository"
 	repomocks "github.com/coderlewin/ucenter/internal/repository/mocks"
+	svcmocks "github.com/coderlewin/ucenter/internal/service/mocks"
 	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/hasher"
+	"github.com/coderlewin/ucenter/pkg/pwdpolicy"
 	"github.com/golang/mock/gomock"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
+// legacyHasher 使用历史的 MD5 加盐算法, 便于断言生成的密码
+var legacyHasher = hasher.NewPasswordHasher(hasher.NewLegacyMD5Scheme(constants.PwdSalt))
+
 func Test_userService_Register(t *testing.T) {
+	policy, err := pwdpolicy.New(pwdpolicy.Options{MinLength: 8})
+	assert.NoError(t, err)
+
 	testCases := []struct {
 		name string
 
@@ -190,7 +205,9 @@ func Test_userService_Register(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			repo := tc.mock(ctrl)
-			svc := NewUserService(repo)
+			svc := NewUserService(repo, nil, legacyHasher, nil, NewPasswordPolicyService(PasswordPolicyOptions{
+				Policy: policy,
+			}, nil, legacyHasher), nil, nil)
 			result, err := svc.Register(tc.ctx, domain.User{
 				UserAccount:   tc.account,
 				UserPassword:  tc.password,
@@ -202,3 +219,532 @@ func Test_userService_Register(t *testing.T) {
 		})
 	}
 }
+
+func Test_userService_Login(t *testing.T) {
+	// 主算法为 bcrypt, 同时兼容历史 MD5 密码
+	pwdHasher := hasher.NewPasswordHasher(hasher.NewBcryptScheme(4), hasher.NewLegacyMD5Scheme(constants.PwdSalt))
+	bcryptPwd, err := pwdHasher.Hash("12345678")
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (repository.UserRepository, LoginLimitService)
+
+		// 输入
+		ctx      context.Context
+		account  string
+		password string
+
+		// 预期中的输出
+		wantErr error
+		wantID  int64
+	}{
+		{
+			name: "密码长度过短",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginLimitService) {
+				return repomocks.NewMockUserRepository(ctrl), svcmocks.NewMockLoginLimitService(ctrl)
+			},
+			ctx:      context.Background(),
+			account:  "lewin",
+			password: "123456",
+			wantErr:  errno.ErrParameterInvalid,
+		},
+		{
+			name: "账号已锁定, 不再校验密码",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginLimitService) {
+				limitSvc := svcmocks.NewMockLoginLimitService(ctrl)
+				limitSvc.EXPECT().Check(gomock.Any(), "lewin", "127.0.0.1").Return(errno.ErrTooManyAttempts)
+				return repomocks.NewMockUserRepository(ctrl), limitSvc
+			},
+			ctx:      context.Background(),
+			account:  "lewin",
+			password: "12345678",
+			wantErr:  errno.ErrTooManyAttempts,
+		},
+		{
+			name: "账号不存在, 计入失败次数",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginLimitService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByAccount(gomock.Any(), "lewin").Return(domain.User{}, gorm.ErrRecordNotFound)
+				limitSvc := svcmocks.NewMockLoginLimitService(ctrl)
+				limitSvc.EXPECT().Check(gomock.Any(), "lewin", "127.0.0.1").Return(nil)
+				limitSvc.EXPECT().Fail(gomock.Any(), "lewin", "127.0.0.1").Return(nil)
+				return repo, limitSvc
+			},
+			ctx:      context.Background(),
+			account:  "lewin",
+			password: "12345678",
+			wantErr:  errno.ErrEntityNull,
+		},
+		{
+			name: "密码不匹配",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginLimitService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByAccount(gomock.Any(), "lewin").Return(domain.User{
+					ID:           1,
+					UserAccount:  "lewin",
+					UserPassword: bcryptPwd,
+				}, nil)
+				limitSvc := svcmocks.NewMockLoginLimitService(ctrl)
+				limitSvc.EXPECT().Check(gomock.Any(), "lewin", "127.0.0.1").Return(nil)
+				limitSvc.EXPECT().Fail(gomock.Any(), "lewin", "127.0.0.1").Return(nil)
+				return repo, limitSvc
+			},
+			ctx:      context.Background(),
+			account:  "lewin",
+			password: "87654321",
+			wantErr:  errno.ErrEntityNull,
+		},
+		{
+			name: "密码不匹配且达到锁定阈值",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginLimitService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByAccount(gomock.Any(), "lewin").Return(domain.User{
+					ID:           1,
+					UserAccount:  "lewin",
+					UserPassword: bcryptPwd,
+				}, nil)
+				limitSvc := svcmocks.NewMockLoginLimitService(ctrl)
+				limitSvc.EXPECT().Check(gomock.Any(), "lewin", "127.0.0.1").Return(nil)
+				limitSvc.EXPECT().Fail(gomock.Any(), "lewin", "127.0.0.1").Return(errno.ErrTooManyAttempts)
+				return repo, limitSvc
+			},
+			ctx:      context.Background(),
+			account:  "lewin",
+			password: "87654321",
+			wantErr:  errno.ErrTooManyAttempts,
+		},
+		{
+			name: "存储的密码哈希损坏",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginLimitService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByAccount(gomock.Any(), "lewin").Return(domain.User{
+					ID:           1,
+					UserAccount:  "lewin",
+					UserPassword: "$2a$04$broken",
+				}, nil)
+				limitSvc := svcmocks.NewMockLoginLimitService(ctrl)
+				limitSvc.EXPECT().Check(gomock.Any(), "lewin", "127.0.0.1").Return(nil)
+				limitSvc.EXPECT().Fail(gomock.Any(), "lewin", "127.0.0.1").Return(nil)
+				return repo, limitSvc
+			},
+			ctx:      context.Background(),
+			account:  "lewin",
+			password: "12345678",
+			wantErr:  errno.ErrEntityNull,
+		},
+		{
+			name: "登录成功, 清除失败次数",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginLimitService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByAccount(gomock.Any(), "lewin").Return(domain.User{
+					ID:           1,
+					UserAccount:  "lewin",
+					UserPassword: bcryptPwd,
+				}, nil)
+				limitSvc := svcmocks.NewMockLoginLimitService(ctrl)
+				limitSvc.EXPECT().Check(gomock.Any(), "lewin", "127.0.0.1").Return(nil)
+				limitSvc.EXPECT().Succeed(gomock.Any(), "lewin").Return(nil)
+				return repo, limitSvc
+			},
+			ctx:      context.Background(),
+			account:  "lewin",
+			password: "12345678",
+			wantID:   1,
+		},
+		{
+			name: "邮箱未验证, 不计入失败次数",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginLimitService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByAccount(gomock.Any(), "lewin").Return(domain.User{
+					ID:           1,
+					UserAccount:  "lewin",
+					UserPassword: bcryptPwd,
+					UserStatus:   constants.UserStatusPending,
+				}, nil)
+				limitSvc := svcmocks.NewMockLoginLimitService(ctrl)
+				limitSvc.EXPECT().Check(gomock.Any(), "lewin", "127.0.0.1").Return(nil)
+				return repo, limitSvc
+			},
+			ctx:      context.Background(),
+			account:  "lewin",
+			password: "12345678",
+			wantErr:  errno.ErrEmailUnverified,
+		},
+		{
+			name: "历史MD5密码登录成功并升级",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginLimitService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByAccount(gomock.Any(), "lewin").Return(domain.User{
+					ID:           1,
+					UserAccount:  "lewin",
+					UserPassword: "9825417a996f1b031543e79ab88ec7ea",
+				}, nil)
+				repo.EXPECT().RehashPassword(gomock.Any(), int64(1), "9825417a996f1b031543e79ab88ec7ea", gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id int64, oldHash, password string) (bool, error) {
+						ok, err := pwdHasher.Verify("12345678", password)
+						assert.NoError(t, err)
+						assert.True(t, ok)
+						assert.False(t, pwdHasher.NeedsRehash(password))
+						return true, nil
+					})
+				limitSvc := svcmocks.NewMockLoginLimitService(ctrl)
+				limitSvc.EXPECT().Check(gomock.Any(), "lewin", "127.0.0.1").Return(nil)
+				limitSvc.EXPECT().Succeed(gomock.Any(), "lewin").Return(nil)
+				return repo, limitSvc
+			},
+			ctx:      context.Background(),
+			account:  "lewin",
+			password: "12345678",
+			wantID:   1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, limitSvc := tc.mock(ctrl)
+			svc := NewUserService(repo, nil, pwdHasher, nil, nil, nil, limitSvc)
+			user, err := svc.Login(tc.ctx, domain.User{
+				UserAccount:  tc.account,
+				UserPassword: tc.password,
+			}, "127.0.0.1")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantID, user.ID)
+		})
+	}
+}
+
+func Test_userService_Login_RehashKeepsLoginState(t *testing.T) {
+	pwdHasher := hasher.NewPasswordHasher(hasher.NewBcryptScheme(4), hasher.NewLegacyMD5Scheme(constants.PwdSalt))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	stored := domain.User{
+		ID:              1,
+		UserAccount:     "lewin",
+		UserPassword:    "9825417a996f1b031543e79ab88ec7ea",
+		SecurityVersion: 3,
+	}
+	repo := repomocks.NewMockUserRepository(ctrl)
+	repo.EXPECT().FindByAccount(gomock.Any(), "lewin").Return(stored, nil)
+	// 升级哈希不递增安全版本
+	repo.EXPECT().RehashPassword(gomock.Any(), int64(1), stored.UserPassword, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, id int64, oldHash, newHash string) (bool, error) {
+			stored.UserPassword = newHash
+			return true, nil
+		})
+	repo.EXPECT().GetOneById(gomock.Any(), int64(1)).DoAndReturn(func(ctx context.Context, id int64) (domain.User, error) {
+		return stored, nil
+	})
+	limitSvc := svcmocks.NewMockLoginLimitService(ctrl)
+	limitSvc.EXPECT().Check(gomock.Any(), "lewin", "127.0.0.1").Return(nil)
+	limitSvc.EXPECT().Succeed(gomock.Any(), "lewin").Return(nil)
+	svc := NewUserService(repo, nil, pwdHasher, nil, nil, nil, limitSvc)
+
+	user, err := svc.Login(context.Background(), domain.User{UserAccount: "lewin", UserPassword: "12345678"}, "127.0.0.1")
+	require.NoError(t, err)
+	assert.False(t, pwdHasher.NeedsRehash(user.UserPassword))
+
+	// 登录态中保存的是登录时的安全版本, 下一次请求仍然有效
+	loginStateSvc := NewLoginStateService(repo, redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}))
+	_, err = loginStateSvc.Check(context.Background(), user.ID, user.SecurityVersion)
+	assert.NoError(t, err)
+}
+
+func Test_userService_ChangePassword(t *testing.T) {
+	pwdHasher := hasher.NewPasswordHasher(hasher.NewBcryptScheme(4))
+	oldPwd, err := pwdHasher.Hash("12345678")
+	assert.NoError(t, err)
+	policy, err := pwdpolicy.New(pwdpolicy.Options{MinLength: 8, DisallowAccount: true})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (repository.UserRepository, repository.AuditLogRepository,
+			LoginStateService, SessionService)
+
+		// 输入
+		oldPassword string
+		password    string
+
+		// 预期中的输出
+		wantErr     error
+		wantVersion int32
+	}{
+		{
+			name: "原密码错误",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.AuditLogRepository,
+				LoginStateService, SessionService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().GetOneById(gomock.Any(), int64(1)).Return(domain.User{
+					ID:           1,
+					UserAccount:  "lewin",
+					UserPassword: oldPwd,
+				}, nil)
+				return repo, nil, nil, nil
+			},
+			oldPassword: "87654321",
+			password:    "kite7-mango-vault",
+			wantErr:     errno.ErrParameterInvalid,
+		},
+		{
+			name: "新密码包含账号",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.AuditLogRepository,
+				LoginStateService, SessionService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().GetOneById(gomock.Any(), int64(1)).Return(domain.User{
+					ID:           1,
+					UserAccount:  "lewin",
+					UserPassword: oldPwd,
+				}, nil)
+				return repo, nil, nil, nil
+			},
+			oldPassword: "12345678",
+			password:    "lewin-2024-kite",
+			wantErr:     errno.ErrParameterInvalid,
+		},
+		{
+			name: "修改成功",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, repository.AuditLogRepository,
+				LoginStateService, SessionService) {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				gomock.InOrder(
+					repo.EXPECT().GetOneById(gomock.Any(), int64(1)).Return(domain.User{
+						ID:           1,
+						UserAccount:  "lewin",
+						UserPassword: oldPwd,
+					}, nil),
+					repo.EXPECT().UpdatePassword(gomock.Any(), int64(1), gomock.Any()).
+						DoAndReturn(func(ctx context.Context, id int64, password string) error {
+							ok, err := pwdHasher.Verify("kite7-mango-vault", password)
+							assert.NoError(t, err)
+							assert.True(t, ok)
+							return nil
+						}),
+					repo.EXPECT().GetOneById(gomock.Any(), int64(1)).Return(domain.User{
+						ID:              1,
+						SecurityVersion: 1,
+					}, nil),
+				)
+				auditRepo := repomocks.NewMockAuditLogRepository(ctrl)
+				auditRepo.EXPECT().Create(gomock.Any(), domain.AuditLog{
+					UserID:     1,
+					OperatorID: 1,
+					Action:     domain.AuditActionPasswordChange,
+					IP:         "127.0.0.1",
+					UserAgent:  "test",
+				}).Return(nil)
+				loginStateSvc := svcmocks.NewMockLoginStateService(ctrl)
+				loginStateSvc.EXPECT().Invalidate(gomock.Any(), int64(1)).Return(nil)
+				sessionSvc := svcmocks.NewMockSessionService(ctrl)
+				sessionSvc.EXPECT().RevokeAll(gomock.Any(), int64(1)).Return(nil)
+				return repo, auditRepo, loginStateSvc, sessionSvc
+			},
+			oldPassword: "12345678",
+			password:    "kite7-mango-vault",
+			wantVersion: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, auditRepo, loginStateSvc, sessionSvc := tc.mock(ctrl)
+			pwdPolicySvc := NewPasswordPolicyService(PasswordPolicyOptions{Policy: policy}, nil, pwdHasher)
+			svc := NewUserService(repo, auditRepo, pwdHasher, loginStateSvc, pwdPolicySvc, sessionSvc, nil)
+			user, err := svc.ChangePassword(context.Background(), 1, tc.oldPassword, domain.User{
+				UserPassword:  tc.password,
+				CheckPassword: tc.password,
+			}, "127.0.0.1", "test")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantVersion, user.SecurityVersion)
+		})
+	}
+}
+
+func Test_userService_List(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.UserRepository
+
+		// 输入
+		query domain.UserQuery
+
+		// 预期中的输出
+		wantErr   error
+		wantTotal int64
+	}{
+		{
+			name: "页码小于 1",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			query:   domain.UserQuery{Current: 0, Size: 10},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name: "每页数量超出上限",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			query:   domain.UserQuery{Current: 1, Size: domain.MaxPageSize + 1},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name: "不支持的排序字段",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			query: domain.UserQuery{
+				Current: 1,
+				Size:    10,
+				Sorts:   []domain.UserSort{{Field: "user_password"}},
+			},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name: "创建时间范围颠倒",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			query: domain.UserQuery{
+				Current: 1,
+				Size:    10,
+				Filter: domain.UserFilter{
+					CreatedFrom: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+					CreatedTo:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name: "查询成功",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().Search(gomock.Any(), domain.UserQuery{
+					Current: 2,
+					Size:    20,
+					Sorts:   []domain.UserSort{{Field: "create_time", Desc: true}},
+				}).Return([]domain.User{{ID: 21}}, int64(21), nil)
+				return repo
+			},
+			query: domain.UserQuery{
+				Current: 2,
+				Size:    20,
+				Sorts:   []domain.UserSort{{Field: "create_time", Desc: true}},
+			},
+			wantTotal: 21,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserService(tc.mock(ctrl), nil, legacyHasher, nil, nil, nil, nil)
+			_, total, err := svc.List(context.Background(), tc.query)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantTotal, total)
+		})
+	}
+}
+
+func Test_userService_ListByCursor(t *testing.T) {
+	createTime := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	sorts := []domain.UserSort{{Field: "create_time", Desc: true}, {Field: "id"}}
+	cursor := domain.EncodeUserCursor(sorts, domain.User{ID: 7, CreateTime: createTime})
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.UserRepository
+
+		// 输入
+		query domain.UserCursorQuery
+
+		// 预期中的输出
+		wantErr    error
+		wantIDs    []int64
+		wantCursor bool
+		wantTotal  int64
+	}{
+		{
+			name: "第一页, 还有下一页",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().SearchAfter(gomock.Any(), domain.UserFilter{},
+					[]domain.UserSort{{Field: "id"}}, []any(nil), 3).
+					Return([]domain.User{{ID: 1}, {ID: 2}, {ID: 3}}, nil)
+				return repo
+			},
+			query:      domain.UserCursorQuery{Size: 2},
+			wantIDs:    []int64{1, 2},
+			wantCursor: true,
+			wantTotal:  -1,
+		},
+		{
+			name: "按游标翻页并精确统计",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repomocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().SearchAfter(gomock.Any(), domain.UserFilter{}, sorts,
+					[]any{createTime, int64(7)}, 3).
+					Return([]domain.User{{ID: 5}}, nil)
+				repo.EXPECT().CountByFilter(gomock.Any(), domain.UserFilter{}).Return(int64(8), nil)
+				return repo
+			},
+			query: domain.UserCursorQuery{
+				Sorts:  []domain.UserSort{{Field: "create_time", Desc: true}},
+				Cursor: cursor,
+				Size:   2,
+				Count:  domain.CountExact,
+			},
+			wantIDs:   []int64{5},
+			wantTotal: 8,
+		},
+		{
+			name: "游标与排序条件不匹配",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			query:   domain.UserCursorQuery{Cursor: cursor, Size: 2},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name: "游标格式错误",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			query:   domain.UserCursorQuery{Cursor: "not-a-cursor", Size: 2},
+			wantErr: errno.ErrParameterInvalid,
+		},
+		{
+			name: "不支持的统计方式",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				return repomocks.NewMockUserRepository(ctrl)
+			},
+			query:   domain.UserCursorQuery{Size: 2, Count: "all"},
+			wantErr: errno.ErrParameterInvalid,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserService(tc.mock(ctrl), nil, legacyHasher, nil, nil, nil, nil)
+			page, err := svc.ListByCursor(context.Background(), tc.query)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			ids := make([]int64, 0, len(page.Users))
+			for _, user := range page.Users {
+				ids = append(ids, user.ID)
+			}
+			assert.Equal(t, tc.wantIDs, ids)
+			assert.Equal(t, tc.wantCursor, page.NextCursor != "")
+			assert.Equal(t, tc.wantTotal, page.Total)
+		})
+	}
+}
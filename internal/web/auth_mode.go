@@ -0,0 +1,25 @@
+package web
+
+// AuthMode 登录态的保存方式
+type AuthMode string
+
+const (
+	// AuthModeSession 使用 cookie 会话保存登录态
+	AuthModeSession AuthMode = "session"
+	// AuthModeJWT 使用 Authorization 请求头携带的 JWT 保存登录态
+	AuthModeJWT AuthMode = "jwt"
+	// AuthModeBoth 同时启用会话与 JWT, 任一方式认证通过即可
+	AuthModeBoth AuthMode = "both"
+)
+
+func (m AuthMode) UseSession() bool {
+	return m == AuthModeSession || m == AuthModeBoth
+}
+
+func (m AuthMode) UseJWT() bool {
+	return m == AuthModeJWT || m == AuthModeBoth
+}
+
+func (m AuthMode) IsValid() bool {
+	return m.UseSession() || m.UseJWT()
+}
@@ -0,0 +1,38 @@
+package web
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/internal/web/vo"
+	"github.com/coderlewin/ucenter/pkg/core"
+)
+
+type CaptchaHandler struct {
+	captchaSvc service.CaptchaService
+}
+
+func NewCaptchaHandler(captchaSvc service.CaptchaService) *CaptchaHandler {
+	return &CaptchaHandler{captchaSvc: captchaSvc}
+}
+
+// ConfigRoutes 配置路由
+func (h *CaptchaHandler) ConfigRoutes(g *route.RouterGroup) {
+	g.GET("/captcha", h.generate)
+}
+
+// generate 生成图形验证码
+func (h *CaptchaHandler) generate(ctx context.Context, c *app.RequestContext) {
+	ca, err := h.captchaSvc.Generate(ctx)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	core.SendResponse(c, nil, vo.CaptchaVO{
+		CaptchaID: ca.ID,
+		Image:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(ca.Image),
+	})
+}
@@ -0,0 +1,52 @@
+package web
+
+import (
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"net/http"
+	"strings"
+)
+
+// DefaultAvatarHandler 提供新用户的默认头像
+type DefaultAvatarHandler struct {
+	defaultAvatarSvc service.DefaultAvatarService
+}
+
+func NewDefaultAvatarHandler(defaultAvatarSvc service.DefaultAvatarService) *DefaultAvatarHandler {
+	return &DefaultAvatarHandler{defaultAvatarSvc: defaultAvatarSvc}
+}
+
+// ConfigRoutes 配置路由
+func (d *DefaultAvatarHandler) ConfigRoutes(h *route.RouterGroup) {
+	group := h.Group("/avatar")
+	{
+		group.GET("/identicon/:file", d.render(service.DefaultAvatarStyleIdenticon, ".png"))
+		group.GET("/initials/:file", d.render(service.DefaultAvatarStyleInitials, ".svg"))
+	}
+}
+
+// render 同一个地址总是得到相同的图片, 允许客户端长期缓存
+func (d *DefaultAvatarHandler) render(style, ext string) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		file := c.Param("file")
+		seed, ok := strings.CutSuffix(file, ext)
+		if !ok {
+			core.SendResponse(c, errno.ErrPageNotFound, nil)
+			return
+		}
+		data, contentType, err := d.defaultAvatarSvc.Render(style, seed, c.Query("text"))
+		if err != nil {
+			core.SendResponse(c, err, nil)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		c.Header("X-Content-Type-Options", "nosniff")
+		// SVG 被直接打开时禁止执行脚本
+		c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+		c.Data(http.StatusOK, contentType, data)
+	}
+}
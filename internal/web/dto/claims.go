@@ -3,14 +3,31 @@ package dto
 import "github.com/golang-jwt/jwt/v5"
 
 type UserClaims struct {
-	Id        int64
-	UserAgent string
-	Ssid      string
+	Id              int64
+	Role            int32
+	SecurityVersion int32
+	UserAgent       string
+	Ssid            string
 	jwt.RegisteredClaims
 }
 
 type RefreshClaims struct {
-	Id   int64
-	Ssid string
+	Id              int64
+	Role            int32
+	SecurityVersion int32
+	Ssid            string
+	jwt.RegisteredClaims
+}
+
+// SessionState 会话中保存的登录态, 角色和状态在每次请求时重新加载
+type SessionState struct {
+	Id              int64
+	SecurityVersion int32
+}
+
+// IDTokenClaims OIDC 的 ID Token
+type IDTokenClaims struct {
+	Nonce    string           `json:"nonce,omitempty"`
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
 	jwt.RegisteredClaims
 }
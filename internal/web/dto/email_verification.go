@@ -0,0 +1,9 @@
+package dto
+
+type VerifyEmailQuery struct {
+	Token string `query:"token,required"`
+}
+
+type ResendVerifyEmailDTO struct {
+	Email string `json:"email,required"`
+}
@@ -0,0 +1,6 @@
+package dto
+
+type LoginLockQuery struct {
+	Account string `query:"account"`
+	IP      string `query:"ip"`
+}
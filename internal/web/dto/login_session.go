@@ -0,0 +1,5 @@
+package dto
+
+type SessionIdInPathDTO struct {
+	ID string `path:"ssid,required"`
+}
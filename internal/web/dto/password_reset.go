@@ -0,0 +1,17 @@
+package dto
+
+type ForgotPasswordDTO struct {
+	Email string `json:"email,required"`
+}
+
+type ResetPasswordDTO struct {
+	Token         string `json:"token,required"`
+	Password      string `json:"password,required"`
+	CheckPassword string `json:"check_password,required"`
+}
+
+type ChangePasswordDTO struct {
+	OldPassword   string `json:"old_password,required"`
+	Password      string `json:"password,required"`
+	CheckPassword string `json:"check_password,required"`
+}
@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+
+type FreezeUserDTO struct {
+	ID     int64  `path:"id,required"`
+	Reason string `json:"reason,required"`
+	// Until 冻结截止时间, 不传时永久冻结
+	Until *time.Time `json:"until"`
+}
+
+type ChangeRoleDTO struct {
+	ID   int64 `path:"id,required"`
+	Role int32 `json:"role,required"`
+}
+
+// AdminUpdateProfileDTO 未携带的字段不修改
+type AdminUpdateProfileDTO struct {
+	ID        int64   `path:"id,required"`
+	Username  *string `json:"username"`
+	AvatarURL *string `json:"avatar_url"`
+	Gender    *int32  `json:"gender"`
+	Phone     *string `json:"phone"`
+	Email     *string `json:"email"`
+}
+
+type AdminCreateUserDTO struct {
+	Account    string `json:"account,required"`
+	Password   string `json:"password,required"`
+	PlanetCode string `json:"planet_code,required"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	// Role 0-普通用户 1-管理员
+	Role int32 `json:"role"`
+	// Status 0-正常 1-冻结
+	Status int32 `json:"status"`
+}
+
+type ImportUsersDTO struct {
+	// DryRun 只校验不导入
+	DryRun bool `query:"dry_run"`
+}
+
+type ImportJobIdDTO struct {
+	ID string `path:"id,required"`
+}
@@ -3,4 +3,16 @@ package dto
 type UserLoginDTO struct {
 	Account  string `json:"account,required"`
 	Password string `json:"password,required"`
+	// CaptchaID 和 CaptchaAnswer 是否必填取决于验证码策略
+	CaptchaID     string `json:"captcha_id"`
+	CaptchaAnswer string `json:"captcha_answer"`
+}
+
+type SendSMSCodeDTO struct {
+	Phone string `json:"phone,required"`
+}
+
+type UserLoginSMSDTO struct {
+	Phone string `json:"phone,required"`
+	Code  string `json:"code,required"`
 }
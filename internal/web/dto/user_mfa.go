@@ -0,0 +1,11 @@
+package dto
+
+type MFACodeDTO struct {
+	Code string `json:"code,required"`
+}
+
+type UserLoginMFADTO struct {
+	MFAToken string `json:"mfa_token,required"`
+	// Code TOTP 验证码或恢复码
+	Code string `json:"code,required"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+// UpdateProfileDTO 未携带的字段不修改
+type UpdateProfileDTO struct {
+	Username  *string `json:"username"`
+	AvatarURL *string `json:"avatar_url"`
+	Gender    *int32  `json:"gender"`
+	Phone     *string `json:"phone"`
+	// PhoneCode 修改手机号时, 发送到新手机号的验证码
+	PhoneCode string  `json:"phone_code"`
+	Email     *string `json:"email"`
+}
@@ -5,4 +5,9 @@ type UserRegisterDTO struct {
 	Password      string `json:"password,required"`
 	CheckPassword string `json:"check_password,required"`
 	PlanetCode    string `json:"planet_code,required"`
+	// Email 开启邮箱验证时必填
+	Email string `json:"email"`
+	// CaptchaID 和 CaptchaAnswer 是否必填取决于验证码策略
+	CaptchaID     string `json:"captcha_id"`
+	CaptchaAnswer string `json:"captcha_answer"`
 }
@@ -1,7 +1,40 @@
 package dto
 
+// UserFilterQuery 用户列表的筛选条件, 搜索和导出共用
+type UserFilterQuery struct {
+	Username   string `query:"username"`
+	Account    string `query:"account"`
+	PlanetCode string `query:"planet_code"`
+	Email      string `query:"email"`
+	Phone      string `query:"phone"`
+	Role       *int32 `query:"role"`
+	Status     *int32 `query:"status"`
+	Gender     *int32 `query:"gender"`
+	// CreatedFrom、CreatedTo 创建时间范围, 格式为 2006-01-02 或 RFC3339, 左闭右开
+	CreatedFrom string `query:"created_from"`
+	CreatedTo   string `query:"created_to"`
+}
+
 type UserSearchQuery struct {
-	Current  int    `query:"current" default:"1"`
-	Size     int    `query:"size" default:"10"`
-	Username string `query:"username"`
+	UserFilterQuery
+	Current int `query:"current" default:"1"`
+	Size    int `query:"size" default:"10"`
+	// Sort 逗号分隔的排序字段, 字段前加 - 表示降序, 如 -create_time,id
+	Sort string `query:"sort"`
+	// Paging 分页方式, offset 按页码分页, cursor 按游标分页, 默认为 offset. 传入 Cursor 时按游标分页
+	Paging string `query:"paging"`
+	// Cursor 上一页返回的 next_cursor
+	Cursor string `query:"cursor"`
+	// Count 游标分页时统计总数的方式: none、exact 或 approx, 默认不统计
+	Count string `query:"count"`
+}
+
+type UserExportQuery struct {
+	UserFilterQuery
+	// Format csv、xlsx 或 jsonl, 默认为 csv
+	Format string `query:"format"`
+	// Columns 逗号分隔的列名, 为空时导出默认列
+	Columns string `query:"columns"`
+	// Unmask 为 true 时导出完整的手机号和邮箱
+	Unmask bool `query:"unmask"`
 }
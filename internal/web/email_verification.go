@@ -0,0 +1,57 @@
+package web
+
+import (
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/internal/web/dto"
+	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/coderlewin/ucenter/pkg/errno"
+)
+
+// EmailVerificationHandler 注册邮箱验证
+type EmailVerificationHandler struct {
+	emailVerifySvc service.EmailVerificationService
+}
+
+func NewEmailVerificationHandler(emailVerifySvc service.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{emailVerifySvc: emailVerifySvc}
+}
+
+// ConfigRoutes 配置路由
+func (e *EmailVerificationHandler) ConfigRoutes(h *route.RouterGroup) {
+	group := h.Group("/user/email")
+	{
+		group.GET("/verify", e.verify)
+		group.POST("/resend", e.resend)
+	}
+}
+
+// verify 访问邮件中的验证链接
+func (e *EmailVerificationHandler) verify(ctx context.Context, c *app.RequestContext) {
+	var req dto.VerifyEmailQuery
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	if err := e.emailVerifySvc.Verify(ctx, req.Token); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
+
+// resend 重新发送验证邮件
+func (e *EmailVerificationHandler) resend(ctx context.Context, c *app.RequestContext) {
+	var req dto.ResendVerifyEmailDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	if err := e.emailVerifySvc.Resend(ctx, req.Email); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
@@ -3,44 +3,128 @@ package middleware
 import (
 	"context"
 	"encoding/gob"
+	"errors"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/internal/web/dto"
 	"github.com/coderlewin/ucenter/internal/web/vo"
 	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/coderlewin/ucenter/pkg/errno"
 	"github.com/ecodeclub/ekit/set"
 	"net/http"
+	"strings"
 )
 
 type CheckSessionAuthMiddlewareBuilder struct {
-	publicPaths set.Set[string]
+	publicPaths    set.Set[string]
+	publicPrefixes []string
+	jwtSvc         service.JWTService
+	loginStateSvc  service.LoginStateService
 }
 
-func NewCheckSessionAuthMiddlewareBuilder() *CheckSessionAuthMiddlewareBuilder {
-	s := set.NewMapSet[string](3)
+func NewCheckSessionAuthMiddlewareBuilder(jwtSvc service.JWTService,
+	loginStateSvc service.LoginStateService) *CheckSessionAuthMiddlewareBuilder {
+	s := set.NewMapSet[string](32)
 	s.Add("/api/user/register")
 	s.Add("/api/user/login")
+	s.Add("/api/user/login/mfa")
+	s.Add("/api/user/login/sms/code")
+	s.Add("/api/user/login/sms")
+	s.Add("/api/user/refresh_token")
+	s.Add("/api/user/password/forgot")
+	s.Add("/api/user/password/reset")
+	s.Add("/api/user/email/verify")
+	s.Add("/api/user/email/resend")
+	s.Add("/api/user/profile/email/confirm")
+	s.Add("/api/captcha")
+	s.Add("/.well-known/jwks.json")
+	s.Add("/.well-known/openid-configuration")
+	s.Add("/oauth2/token")
+	s.Add("/oauth2/userinfo")
 	return &CheckSessionAuthMiddlewareBuilder{
-		publicPaths: s,
+		publicPaths:   s,
+		jwtSvc:        jwtSvc,
+		loginStateSvc: loginStateSvc,
 	}
 }
 
+// PublicPrefixes 以 prefixes 开头的路径不需要认证, 如静态文件
+func (m *CheckSessionAuthMiddlewareBuilder) PublicPrefixes(prefixes ...string) *CheckSessionAuthMiddlewareBuilder {
+	m.publicPrefixes = append(m.publicPrefixes, prefixes...)
+	return m
+}
+
 func (m *CheckSessionAuthMiddlewareBuilder) Build() app.HandlerFunc {
-	gob.Register(&vo.UserVO{})
+	gob.Register(&dto.SessionState{})
 	return func(c context.Context, ctx *app.RequestContext) {
 		// 不需要校验用户认证
-		if m.publicPaths.Exist(string(ctx.Request.URI().Path())) {
+		if isPublicPath(m.publicPaths, m.publicPrefixes, string(ctx.Request.URI().Path())) {
 			return
 		}
 
-		loginUser, err := core.GetUserLoginState(ctx)
+		// 已经通过其他方式认证, 如 JWT
+		if _, exists := ctx.Get(constants.LoginUser); exists {
+			return
+		}
+
+		state, err := core.GetUserLoginState(ctx)
 		if err != nil {
 			core.SendResponse(ctx, err, nil)
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
 
-		ctx.Set(constants.LoginUser, loginUser)
+		// 检查登录会话是否已被吊销
+		ssid := core.GetSessionID(ctx)
+		if ssid == "" {
+			core.SendResponse(ctx, errno.ErrUnauthorization.SetDescription("登录已过期"), nil)
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if err = m.jwtSvc.CheckSession(c, ssid); err != nil {
+			core.SendResponse(ctx, err, nil)
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		// 重新加载角色和状态, 降级、冻结和修改密码立即生效
+		user, err := m.loginStateSvc.Check(c, state.Id, state.SecurityVersion)
+		if err != nil {
+			abortWithLoginStateError(ctx, err)
+			return
+		}
+
+		ctx.Set(constants.SessionID, ssid)
+		ctx.Set(constants.LoginUser, &vo.UserVO{
+			ID:         user.ID,
+			UserRole:   user.UserRole,
+			UserStatus: user.UserStatus,
+		})
 
 		ctx.Next(c)
 	}
 }
+
+// abortWithLoginStateError 账号被冻结时返回 403, 其他情况需要重新登录
+func abortWithLoginStateError(ctx *app.RequestContext, err error) {
+	core.SendResponse(ctx, err, nil)
+	if errors.Is(err, errno.ErrForbidden) {
+		ctx.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+	ctx.AbortWithStatus(http.StatusUnauthorized)
+}
+
+// isPublicPath 路径在 paths 中或以 prefixes 中的任意一个开头
+func isPublicPath(paths set.Set[string], prefixes []string, path string) bool {
+	if paths.Exist(path) {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/internal/web/vo"
+	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/ecodeclub/ekit/set"
+	"net/http"
+)
+
+type CheckJWTAuthMiddlewareBuilder struct {
+	publicPaths    set.Set[string]
+	publicPrefixes []string
+	jwtSvc         service.JWTService
+	loginStateSvc  service.LoginStateService
+	// optional 为 true 时, 未携带 token 的请求交给后续的认证中间件处理
+	optional bool
+}
+
+func NewCheckJWTAuthMiddlewareBuilder(jwtSvc service.JWTService,
+	loginStateSvc service.LoginStateService) *CheckJWTAuthMiddlewareBuilder {
+	s := set.NewMapSet[string](32)
+	s.Add("/api/user/register")
+	s.Add("/api/user/login")
+	s.Add("/api/user/login/mfa")
+	s.Add("/api/user/login/sms/code")
+	s.Add("/api/user/login/sms")
+	s.Add("/api/user/refresh_token")
+	s.Add("/api/user/password/forgot")
+	s.Add("/api/user/password/reset")
+	s.Add("/api/user/email/verify")
+	s.Add("/api/user/email/resend")
+	s.Add("/api/user/profile/email/confirm")
+	s.Add("/api/captcha")
+	s.Add("/.well-known/jwks.json")
+	s.Add("/.well-known/openid-configuration")
+	s.Add("/oauth2/token")
+	s.Add("/oauth2/userinfo")
+	return &CheckJWTAuthMiddlewareBuilder{
+		publicPaths:   s,
+		jwtSvc:        jwtSvc,
+		loginStateSvc: loginStateSvc,
+	}
+}
+
+// Optional 未携带 token 时不拒绝请求, 用于和会话认证同时启用
+func (m *CheckJWTAuthMiddlewareBuilder) Optional() *CheckJWTAuthMiddlewareBuilder {
+	m.optional = true
+	return m
+}
+
+// PublicPrefixes 以 prefixes 开头的路径不需要认证, 如静态文件
+func (m *CheckJWTAuthMiddlewareBuilder) PublicPrefixes(prefixes ...string) *CheckJWTAuthMiddlewareBuilder {
+	m.publicPrefixes = append(m.publicPrefixes, prefixes...)
+	return m
+}
+
+func (m *CheckJWTAuthMiddlewareBuilder) Build() app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		// 不需要校验用户认证
+		if isPublicPath(m.publicPaths, m.publicPrefixes, string(ctx.Request.URI().Path())) {
+			return
+		}
+
+		tokenString := m.jwtSvc.ExtractTokenString(ctx)
+		if tokenString == "" {
+			if m.optional {
+				return
+			}
+			m.abort(ctx, errno.ErrUnauthorization.SetDescription("未登录"))
+			return
+		}
+
+		claims, err := m.jwtSvc.ParseAccessToken(tokenString)
+		if err != nil {
+			m.abort(ctx, err)
+			return
+		}
+
+		// 换了浏览器或设备, 视为 token 被盗用
+		if claims.UserAgent != string(ctx.GetHeader("User-Agent")) {
+			m.abort(ctx, errno.ErrUnauthorization.SetDescription("token 无效"))
+			return
+		}
+
+		// 检查是否已经退出登录
+		if err = m.jwtSvc.CheckSession(c, claims.Ssid); err != nil {
+			m.abort(ctx, err)
+			return
+		}
+
+		// token 中的角色可能已经过时, 以当前的角色和状态为准
+		user, err := m.loginStateSvc.Check(c, claims.Id, claims.SecurityVersion)
+		if err != nil {
+			abortWithLoginStateError(ctx, err)
+			return
+		}
+
+		ctx.Set(constants.SessionID, claims.Ssid)
+		ctx.Set(constants.UserLoginState, claims)
+		ctx.Set(constants.LoginUser, &vo.UserVO{
+			ID:         user.ID,
+			UserRole:   user.UserRole,
+			UserStatus: user.UserStatus,
+		})
+
+		ctx.Next(c)
+	}
+}
+
+func (m *CheckJWTAuthMiddlewareBuilder) abort(ctx *app.RequestContext, err error) {
+	core.SendResponse(ctx, err, nil)
+	ctx.AbortWithStatus(http.StatusUnauthorized)
+}
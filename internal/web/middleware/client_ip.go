@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"net"
+	"strings"
+)
+
+// NewClientIPFunc 返回获取客户端 IP 的函数, 只有对端地址属于受信任的代理时才读取 X-Forwarded-For 和 X-Real-IP.
+// trustedProxies 为 CIDR 或单个 IP, 为空时始终使用对端地址, 避免伪造请求头绕过按 IP 的限流和登录锁定
+func NewClientIPFunc(trustedProxies []string) (app.ClientIP, error) {
+	var cidrs []*net.IPNet
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("受信任的代理 %s 不是合法的 IP", proxy)
+			}
+			if ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, cidr, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("受信任的代理 %s 不是合法的 CIDR: %w", proxy, err)
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return app.ClientIPWithOption(app.ClientIPOptions{
+		RemoteIPHeaders: []string{"X-Forwarded-For", "X-Real-IP"},
+		TrustedCIDRs:    cidrs,
+	}), nil
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/web/vo"
+	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/ratelimit"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 限流对象的维度
+const (
+	RateLimitByIP    = "ip"
+	RateLimitByUser  = "user"
+	RateLimitByRoute = "route"
+)
+
+// RateLimitRule 定义一条路由的限流规则
+type RateLimitRule struct {
+	// Path 路由路径, 如 /api/user/:id, 以 * 结尾时按前缀匹配
+	Path string `mapstructure:"path"`
+	// Method 请求方法, 为空时匹配所有方法
+	Method string `mapstructure:"method"`
+	// Key 限流对象的维度, ip、user 或 route, 默认为 ip
+	Key    string        `mapstructure:"key"`
+	Limit  int64         `mapstructure:"limit"`
+	Window time.Duration `mapstructure:"window"`
+}
+
+func (r RateLimitRule) match(method string, path string) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.Path, "*"); ok {
+		return strings.HasPrefix(path, prefix)
+	}
+	return r.Path == path
+}
+
+// RateLimitMiddlewareBuilder 按路由规则限流, 需要在认证中间件之后使用, 才能按用户限流
+type RateLimitMiddlewareBuilder struct {
+	limiter ratelimit.Limiter
+	rules   []RateLimitRule
+}
+
+func NewRateLimitMiddlewareBuilder(limiter ratelimit.Limiter, rules []RateLimitRule) *RateLimitMiddlewareBuilder {
+	return &RateLimitMiddlewareBuilder{limiter: limiter, rules: rules}
+}
+
+func (m *RateLimitMiddlewareBuilder) Build() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		// 使用路由模板而不是实际路径, 未匹配到路由的请求不限流
+		path := c.FullPath()
+		method := string(c.Request.Header.Method())
+		if path == "" {
+			c.Next(ctx)
+			return
+		}
+
+		// 按顺序使用第一条匹配的规则
+		for _, rule := range m.rules {
+			if !rule.match(method, path) {
+				continue
+			}
+
+			key := fmt.Sprintf("ucenter:ratelimit:%s:%s:%s", method, path, m.subject(c, rule.Key))
+			res, err := m.limiter.Limit(ctx, key, ratelimit.Rule{Limit: rule.Limit, Window: rule.Window})
+			if err != nil {
+				// 限流器不可用时放行, 不影响正常请求
+				hlog.CtxErrorf(ctx, "rate limit failed, key=%s, err=%v", key, err)
+				break
+			}
+
+			reset := int64(math.Ceil(res.Reset.Seconds()))
+			c.Header("RateLimit-Limit", strconv.FormatInt(res.Limit, 10))
+			c.Header("RateLimit-Remaining", strconv.FormatInt(res.Remaining, 10))
+			c.Header("RateLimit-Reset", strconv.FormatInt(reset, 10))
+			if !res.Allowed {
+				c.Header("Retry-After", strconv.FormatInt(reset, 10))
+				core.SendResponse(c, errno.ErrTooManyRequests.SetDescription("请在 %d 秒后重试", reset), nil)
+				c.AbortWithStatus(http.StatusTooManyRequests)
+				return
+			}
+			break
+		}
+
+		c.Next(ctx)
+	}
+}
+
+// subject 返回限流对象, 未登录时按用户限流会退化为按 IP 限流
+func (m *RateLimitMiddlewareBuilder) subject(c *app.RequestContext, key string) string {
+	switch key {
+	case RateLimitByRoute:
+		return "route"
+	case RateLimitByUser:
+		if value, exists := c.Get(constants.LoginUser); exists {
+			return "user:" + strconv.FormatInt(value.(*vo.UserVO).ID, 10)
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// keyRecorder 记录限流的 key, 总是放行
+type keyRecorder struct {
+	keys []string
+}
+
+func (r *keyRecorder) Limit(ctx context.Context, key string, rule ratelimit.Rule) (ratelimit.Result, error) {
+	r.keys = append(r.keys, key)
+	return ratelimit.Result{Allowed: true, Limit: rule.Limit, Remaining: rule.Limit - 1}, nil
+}
+
+func TestRateLimitMiddlewareBuilder_ClientIP(t *testing.T) {
+	// 单元测试中请求的对端地址为 0.0.0.0
+	testCases := []struct {
+		name           string
+		trustedProxies []string
+		headers        []ut.Header
+
+		wantKey string
+	}{
+		{
+			name:    "没有代理头",
+			wantKey: "ucenter:ratelimit:GET:/ping:ip:0.0.0.0",
+		},
+		{
+			name: "未配置受信任的代理时忽略伪造的 X-Forwarded-For",
+			headers: []ut.Header{
+				{Key: "X-Forwarded-For", Value: "1.2.3.4"},
+				{Key: "X-Real-IP", Value: "5.6.7.8"},
+			},
+			wantKey: "ucenter:ratelimit:GET:/ping:ip:0.0.0.0",
+		},
+		{
+			name:           "对端不是受信任的代理",
+			trustedProxies: []string{"10.0.0.0/8"},
+			headers:        []ut.Header{{Key: "X-Forwarded-For", Value: "1.2.3.4"}},
+			wantKey:        "ucenter:ratelimit:GET:/ping:ip:0.0.0.0",
+		},
+		{
+			name:           "受信任的代理转发",
+			trustedProxies: []string{"0.0.0.0"},
+			headers:        []ut.Header{{Key: "X-Forwarded-For", Value: "1.2.3.4"}},
+			wantKey:        "ucenter:ratelimit:GET:/ping:ip:1.2.3.4",
+		},
+		{
+			name:           "受信任的代理转发时只取代理追加的地址",
+			trustedProxies: []string{"0.0.0.0/32"},
+			headers:        []ut.Header{{Key: "X-Forwarded-For", Value: "9.9.9.9, 1.2.3.4"}},
+			wantKey:        "ucenter:ratelimit:GET:/ping:ip:1.2.3.4",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clientIP, err := NewClientIPFunc(tc.trustedProxies)
+			require.NoError(t, err)
+			limiter := &keyRecorder{}
+			engine := route.NewEngine(config.NewOptions(nil))
+			// ut.PerformRequest 创建的请求不会使用 engine.SetClientIPFunc 设置的函数, 在请求上单独设置
+			engine.Use(func(ctx context.Context, c *app.RequestContext) {
+				c.SetClientIPFunc(clientIP)
+				c.Next(ctx)
+			}, NewRateLimitMiddlewareBuilder(limiter, []RateLimitRule{
+				{Path: "/ping", Limit: 10, Window: time.Minute},
+			}).Build())
+			engine.GET("/ping", func(ctx context.Context, c *app.RequestContext) {
+				c.Status(http.StatusOK)
+			})
+
+			w := ut.PerformRequest(engine, http.MethodGet, "/ping", nil, tc.headers...)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, []string{tc.wantKey}, limiter.keys)
+		})
+	}
+}
+
+func TestNewClientIPFunc(t *testing.T) {
+	for _, proxy := range []string{"10.0.0.0/33", "localhost", ""} {
+		_, err := NewClientIPFunc([]string{proxy})
+		assert.Error(t, err, proxy)
+	}
+	_, err := NewClientIPFunc([]string{"10.0.0.0/8", "::1"})
+	assert.NoError(t, err)
+}
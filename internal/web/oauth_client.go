@@ -0,0 +1,93 @@
+package web
+
+import (
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/internal/web/dto"
+	"github.com/coderlewin/ucenter/internal/web/middleware"
+	"github.com/coderlewin/ucenter/internal/web/vo"
+	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/duke-git/lancet/v2/slice"
+)
+
+// OAuthClientHandler 管理接入 OIDC 的客户端应用
+type OAuthClientHandler struct {
+	oidcSvc service.OIDCService
+}
+
+func NewOAuthClientHandler(oidcSvc service.OIDCService) *OAuthClientHandler {
+	return &OAuthClientHandler{oidcSvc: oidcSvc}
+}
+
+// ConfigRoutes 配置路由
+func (o *OAuthClientHandler) ConfigRoutes(h *route.RouterGroup) {
+	group := h.Group("/oauth/clients")
+	{
+		group.Use(middleware.NewCheckRoleMiddlewareBuilder(constants.AdminRole).Build())
+		group.POST("", o.create)
+		group.GET("", o.list)
+		group.DELETE("/:id", o.delete)
+	}
+}
+
+// create 创建客户端应用, 客户端密钥只在创建时返回一次
+func (o *OAuthClientHandler) create(ctx context.Context, c *app.RequestContext) {
+	var req dto.OAuthClientCreateDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	client, err := o.oidcSvc.CreateClient(ctx, domain.OAuthClient{
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+	})
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, o.domainToVO(client))
+}
+
+// list 客户端应用列表
+func (o *OAuthClientHandler) list(ctx context.Context, c *app.RequestContext) {
+	clients, err := o.oidcSvc.ListClients(ctx)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, slice.Map(clients, func(index int, client domain.OAuthClient) *vo.OAuthClientVO {
+		// 列表中不返回密钥哈希
+		client.ClientSecret = ""
+		return o.domainToVO(client)
+	}))
+}
+
+// delete 删除客户端应用
+func (o *OAuthClientHandler) delete(ctx context.Context, c *app.RequestContext) {
+	var req dto.IdInPathDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	if err := o.oidcSvc.DeleteClient(ctx, req.ID); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
+
+func (o *OAuthClientHandler) domainToVO(client domain.OAuthClient) *vo.OAuthClientVO {
+	return &vo.OAuthClientVO{
+		ID:           client.ID,
+		ClientID:     client.ClientID,
+		ClientSecret: client.ClientSecret,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIs,
+		CreateTime:   client.CreateTime,
+	}
+}
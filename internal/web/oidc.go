@@ -0,0 +1,232 @@
+package web
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/internal/web/dto"
+	"github.com/coderlewin/ucenter/internal/web/vo"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/keyring"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// OIDCHandler OIDC 协议端点, 需要挂载在根路径下
+type OIDCHandler struct {
+	oidcSvc service.OIDCService
+}
+
+func NewOIDCHandler(oidcSvc service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcSvc: oidcSvc}
+}
+
+// ConfigRoutes 配置路由
+func (o *OIDCHandler) ConfigRoutes(h *route.RouterGroup) {
+	h.GET("/.well-known/openid-configuration", o.discovery)
+	group := h.Group("/oauth2")
+	{
+		group.GET("/authorize", o.authorize)
+		group.POST("/token", o.token)
+		group.GET("/userinfo", o.userinfo)
+		group.POST("/userinfo", o.userinfo)
+	}
+}
+
+// discovery OIDC 发现文档
+func (o *OIDCHandler) discovery(ctx context.Context, c *app.RequestContext) {
+	issuer := strings.TrimSuffix(o.oidcSvc.Issuer(), "/")
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, vo.OIDCDiscoveryVO{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             issuer + "/oauth2/authorize",
+		TokenEndpoint:                     issuer + "/oauth2/token",
+		UserinfoEndpoint:                  issuer + "/oauth2/userinfo",
+		JwksURI:                           issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{keyring.AlgRS256, keyring.AlgEdDSA},
+		ScopesSupported:                   service.SupportedScopes(),
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post"},
+		ClaimsSupported:                   []string{"sub", "name", "preferred_username", "picture", "email", "phone_number"},
+		CodeChallengeMethodsSupported:     []string{service.CodeChallengeS256},
+	})
+}
+
+// authorize 授权端点, 用户需要先登录 ucenter
+func (o *OIDCHandler) authorize(ctx context.Context, c *app.RequestContext) {
+	var req dto.AuthorizeQuery
+	if err := c.BindAndValidate(&req); err != nil {
+		o.sendError(c, &service.OAuthError{Code: "invalid_request", Description: err.Error()})
+		return
+	}
+
+	// 客户端或回调地址不合法时不能重定向, 直接返回错误
+	areq, err := o.oidcSvc.CheckAuthorizeRequest(ctx, domain.AuthorizeRequest{
+		ResponseType:        req.ResponseType,
+		ClientID:            req.ClientID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               strings.Fields(req.Scope),
+		State:               req.State,
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+	})
+	if err != nil {
+		o.sendError(c, err)
+		return
+	}
+
+	// 授权端点需要登录, 未登录的请求已被认证中间件拒绝
+	loginUser := c.MustGet(constants.LoginUser).(*vo.UserVO)
+
+	code, err := o.oidcSvc.Authorize(ctx, loginUser.ID, areq)
+	if err != nil {
+		var oauthErr *service.OAuthError
+		if !errors.As(err, &oauthErr) {
+			o.sendError(c, err)
+			return
+		}
+		o.redirect(c, areq.RedirectURI, url.Values{
+			"error":             {oauthErr.Code},
+			"error_description": {oauthErr.Description},
+			"state":             {areq.State},
+		})
+		return
+	}
+	o.redirect(c, areq.RedirectURI, url.Values{"code": {code}, "state": {areq.State}})
+}
+
+// token 令牌端点, 支持 client_secret_basic 和 client_secret_post 两种客户端认证方式
+func (o *OIDCHandler) token(ctx context.Context, c *app.RequestContext) {
+	var req dto.TokenForm
+	if err := c.BindAndValidate(&req); err != nil {
+		o.sendError(c, &service.OAuthError{Code: "invalid_request", Description: err.Error()})
+		return
+	}
+	if clientID, clientSecret, ok := o.basicAuth(c); ok {
+		req.ClientID, req.ClientSecret = clientID, clientSecret
+	}
+
+	res, err := o.oidcSvc.Exchange(ctx, domain.TokenRequest{
+		GrantType:    req.GrantType,
+		Code:         req.Code,
+		RedirectURI:  req.RedirectURI,
+		ClientID:     req.ClientID,
+		ClientSecret: req.ClientSecret,
+		CodeVerifier: req.CodeVerifier,
+	})
+	if err != nil {
+		o.sendError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, vo.OAuthTokenVO{
+		AccessToken: res.AccessToken,
+		TokenType:   res.TokenType,
+		ExpiresIn:   res.ExpiresIn,
+		IDToken:     res.IDToken,
+		Scope:       strings.Join(res.Scope, " "),
+	})
+}
+
+// userinfo 返回 access token 对应用户的信息, 字段按授权范围返回
+func (o *OIDCHandler) userinfo(ctx context.Context, c *app.RequestContext) {
+	accessToken, found := strings.CutPrefix(string(c.GetHeader("Authorization")), "Bearer ")
+	if !found || accessToken == "" {
+		o.sendError(c, &service.OAuthError{Code: "invalid_token", Description: "缺少 access token"})
+		return
+	}
+
+	user, scopes, err := o.oidcSvc.UserInfo(ctx, accessToken)
+	if err != nil {
+		o.sendError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, userInfoFromVO(domainToUserVO(user), scopes))
+}
+
+// basicAuth 解析 client_secret_basic 方式携带的客户端凭证
+func (o *OIDCHandler) basicAuth(c *app.RequestContext) (string, string, bool) {
+	encoded, found := strings.CutPrefix(string(c.GetHeader("Authorization")), "Basic ")
+	if !found {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", false
+	}
+	id, secret, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return "", "", false
+	}
+	// RFC 6749 要求凭证先经过 form 编码
+	id, err = url.QueryUnescape(id)
+	if err != nil {
+		return "", "", false
+	}
+	secret, err = url.QueryUnescape(secret)
+	if err != nil {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+func (o *OIDCHandler) redirect(c *app.RequestContext, redirectURI string, params url.Values) {
+	if params.Get("state") == "" {
+		params.Del("state")
+	}
+	sep := "?"
+	if strings.Contains(redirectURI, "?") {
+		sep = "&"
+	}
+	c.Redirect(http.StatusFound, []byte(redirectURI+sep+params.Encode()))
+}
+
+// sendError 按 RFC 6749 的格式返回错误
+func (o *OIDCHandler) sendError(c *app.RequestContext, err error) {
+	var oauthErr *service.OAuthError
+	if !errors.As(err, &oauthErr) {
+		_, _, desc := errno.Decode(err)
+		c.JSON(http.StatusInternalServerError, vo.OAuthErrorVO{Error: "server_error", ErrorDescription: desc})
+		return
+	}
+
+	status := http.StatusBadRequest
+	switch oauthErr.Code {
+	case "invalid_client":
+		status = http.StatusUnauthorized
+		c.Header("WWW-Authenticate", `Basic realm="ucenter"`)
+	case "invalid_token":
+		status = http.StatusUnauthorized
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, vo.OAuthErrorVO{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+}
+
+// userInfoFromVO 按授权范围从用户视图模型中提取 OIDC 标准声明
+func userInfoFromVO(user *vo.UserVO, scopes []string) vo.UserInfoVO {
+	info := vo.UserInfoVO{Sub: strconv.FormatInt(user.ID, 10)}
+	if domain.HasScope(scopes, service.ScopeProfile) {
+		info.Name = user.Username
+		info.PreferredUsername = user.UserAccount
+		info.Picture = user.AvatarURL
+	}
+	if domain.HasScope(scopes, service.ScopeEmail) {
+		info.Email = user.Email
+	}
+	if domain.HasScope(scopes, service.ScopePhone) {
+		info.PhoneNumber = user.Phone
+	}
+	return info
+}
@@ -0,0 +1,57 @@
+package web
+
+import (
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/internal/web/dto"
+	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/coderlewin/ucenter/pkg/errno"
+)
+
+// PasswordResetHandler 忘记密码时通过邮件重置
+type PasswordResetHandler struct {
+	pwdResetSvc service.PasswordResetService
+}
+
+func NewPasswordResetHandler(pwdResetSvc service.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{pwdResetSvc: pwdResetSvc}
+}
+
+// ConfigRoutes 配置路由
+func (p *PasswordResetHandler) ConfigRoutes(h *route.RouterGroup) {
+	group := h.Group("/user/password")
+	{
+		group.POST("/forgot", p.forgot)
+		group.POST("/reset", p.reset)
+	}
+}
+
+// forgot 发送重置密码邮件
+func (p *PasswordResetHandler) forgot(ctx context.Context, c *app.RequestContext) {
+	var req dto.ForgotPasswordDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	if err := p.pwdResetSvc.SendResetEmail(ctx, req.Email); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
+
+// reset 使用邮件中的 token 重置密码
+func (p *PasswordResetHandler) reset(ctx context.Context, c *app.RequestContext) {
+	var req dto.ResetPasswordDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	if err := p.pwdResetSvc.Reset(ctx, req.Token, req.Password, req.CheckPassword); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
@@ -0,0 +1,122 @@
+package web
+
+import (
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/internal/web/dto"
+	"github.com/coderlewin/ucenter/internal/web/vo"
+	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"io"
+)
+
+// ProfileHandler 用户自行修改资料
+type ProfileHandler struct {
+	profileSvc service.ProfileService
+	avatarSvc  service.AvatarService
+	smsSvc     service.SMSService
+}
+
+func NewProfileHandler(profileSvc service.ProfileService, avatarSvc service.AvatarService,
+	smsSvc service.SMSService) *ProfileHandler {
+	return &ProfileHandler{profileSvc: profileSvc, avatarSvc: avatarSvc, smsSvc: smsSvc}
+}
+
+// ConfigRoutes 配置路由
+func (p *ProfileHandler) ConfigRoutes(h *route.RouterGroup) {
+	group := h.Group("/user/profile")
+	{
+		group.PUT("", p.update)
+		group.POST("/phone/code", p.sendPhoneCode)
+		group.GET("/email/confirm", p.confirmEmail)
+	}
+	h.POST("/user/avatar", p.uploadAvatar)
+}
+
+// update 修改当前用户的资料, 只修改请求中携带的字段
+func (p *ProfileHandler) update(ctx context.Context, c *app.RequestContext) {
+	var req dto.UpdateProfileDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	loginUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	user, pendingEmail, err := p.profileSvc.UpdateProfile(ctx, loginUser.ID, domain.UserProfile{
+		Username:  req.Username,
+		AvatarURL: req.AvatarURL,
+		Gender:    req.Gender,
+		Phone:     req.Phone,
+		PhoneCode: req.PhoneCode,
+		Email:     req.Email,
+	})
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, &vo.ProfileVO{UserVO: domainToUserVO(user), PendingEmail: pendingEmail})
+}
+
+// sendPhoneCode 修改手机号前向新手机号发送验证码
+func (p *ProfileHandler) sendPhoneCode(ctx context.Context, c *app.RequestContext) {
+	var req dto.SendSMSCodeDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	if err := p.smsSvc.SendBindCode(ctx, req.Phone); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
+
+// confirmEmail 访问修改邮箱的确认链接, 链接可能在未登录的设备上打开, 不需要登录
+func (p *ProfileHandler) confirmEmail(ctx context.Context, c *app.RequestContext) {
+	var req dto.VerifyEmailQuery
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	if err := p.profileSvc.ConfirmEmailChange(ctx, req.Token); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
+
+// uploadAvatar 上传头像, multipart 表单的 file 字段为图片文件
+func (p *ProfileHandler) uploadAvatar(ctx context.Context, c *app.RequestContext) {
+	loginUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription("请选择头像图片"), nil)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+
+	avatar, err := p.avatarSvc.Upload(ctx, loginUser.ID, data)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, &vo.AvatarVO{AvatarURL: avatar.URL, Sizes: avatar.Sizes})
+}
@@ -0,0 +1,90 @@
+package web
+
+import (
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/internal/web/dto"
+	"github.com/coderlewin/ucenter/internal/web/vo"
+	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/duke-git/lancet/v2/slice"
+)
+
+// SessionHandler 当前用户的登录设备管理
+type SessionHandler struct {
+	sessionSvc service.SessionService
+}
+
+func NewSessionHandler(sessionSvc service.SessionService) *SessionHandler {
+	return &SessionHandler{sessionSvc: sessionSvc}
+}
+
+// ConfigRoutes 配置路由
+func (s *SessionHandler) ConfigRoutes(h *route.RouterGroup) {
+	group := h.Group("/user/sessions")
+	{
+		group.GET("", s.list)
+		group.DELETE("/:ssid", s.revoke)
+		group.POST("/revoke_all", s.revokeAll)
+	}
+}
+
+// list 列出当前用户已登录的设备
+func (s *SessionHandler) list(ctx context.Context, c *app.RequestContext) {
+	loginUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	sessions, err := s.sessionSvc.List(ctx, loginUser.ID)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	current := c.GetString(constants.SessionID)
+	core.SendResponse(c, nil, slice.Map(sessions, func(_ int, item domain.LoginSession) vo.LoginSessionVO {
+		return vo.LoginSessionVO{
+			ID:           item.ID,
+			Type:         item.Type,
+			UserAgent:    item.UserAgent,
+			IP:           item.IP,
+			CreateTime:   item.CreateTime.UnixMilli(),
+			LastSeenTime: item.LastSeenTime.UnixMilli(),
+			Current:      item.ID == current,
+		}
+	}))
+}
+
+// revoke 下线指定设备, 只能下线自己的会话
+func (s *SessionHandler) revoke(ctx context.Context, c *app.RequestContext) {
+	var req dto.SessionIdInPathDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	loginUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if err := s.sessionSvc.RevokeForUser(ctx, loginUser.ID, req.ID); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
+
+// revokeAll 下线全部设备, 包括当前设备
+func (s *SessionHandler) revokeAll(ctx context.Context, c *app.RequestContext) {
+	loginUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if err := s.sessionSvc.RevokeAll(ctx, loginUser.ID); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
@@ -3,6 +3,7 @@ package web
 import (
 	"context"
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/cloudwego/hertz/pkg/route"
 	"github.com/coderlewin/ucenter/internal/constants"
 	"github.com/coderlewin/ucenter/internal/domain"
@@ -13,15 +14,41 @@ import (
 	"github.com/coderlewin/ucenter/pkg/core"
 	"github.com/coderlewin/ucenter/pkg/errno"
 	"github.com/duke-git/lancet/v2/slice"
+	"math"
 	"strings"
+	"time"
 )
 
 type UserHandler struct {
-	userSvc service.UserService
+	userSvc          service.UserService
+	jwtSvc           service.JWTService
+	sessionSvc       service.SessionService
+	mfaSvc           service.MFAService
+	loginLimitSvc    service.LoginLimitService
+	captchaSvc       service.CaptchaService
+	emailVerifySvc   service.EmailVerificationService
+	smsSvc           service.SMSService
+	defaultAvatarSvc service.DefaultAvatarService
+	authMode         AuthMode
 }
 
-func NewUserHandler(userSvc service.UserService) *UserHandler {
-	return &UserHandler{userSvc: userSvc}
+func NewUserHandler(userSvc service.UserService, jwtSvc service.JWTService,
+	sessionSvc service.SessionService, mfaSvc service.MFAService,
+	loginLimitSvc service.LoginLimitService, captchaSvc service.CaptchaService,
+	emailVerifySvc service.EmailVerificationService, smsSvc service.SMSService,
+	defaultAvatarSvc service.DefaultAvatarService, authMode AuthMode) *UserHandler {
+	return &UserHandler{
+		userSvc:          userSvc,
+		jwtSvc:           jwtSvc,
+		sessionSvc:       sessionSvc,
+		mfaSvc:           mfaSvc,
+		loginLimitSvc:    loginLimitSvc,
+		captchaSvc:       captchaSvc,
+		emailVerifySvc:   emailVerifySvc,
+		smsSvc:           smsSvc,
+		defaultAvatarSvc: defaultAvatarSvc,
+		authMode:         authMode,
+	}
 }
 
 // ConfigRoutes 配置路由
@@ -30,10 +57,19 @@ func (u *UserHandler) ConfigRoutes(h *route.RouterGroup) {
 	{
 		group.POST("/register", u.register)
 		group.POST("/login", u.login)
+		group.POST("/login/mfa", u.loginMFA)
+		group.POST("/login/sms/code", u.sendSMSCode)
+		group.POST("/login/sms", u.loginSMS)
 		group.GET("/current", u.getCurrentUser)
 		group.POST("/logout", u.logout)
+		group.POST("/password", u.changePassword)
+		if u.authMode.UseJWT() {
+			group.POST("/refresh_token", u.refreshToken)
+		}
 		group.Use(middleware.NewCheckRoleMiddlewareBuilder(constants.AdminRole).Build())
 		group.GET("/search", u.search)
+		group.GET("/login_lock", u.getLoginLocks)
+		group.DELETE("/login_lock", u.unlockLogin)
 		group.DELETE("/:id", u.delete)
 	}
 }
@@ -46,14 +82,43 @@ func (u *UserHandler) search(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
-	list, total, err := u.userSvc.List(ctx, req.Username, req.Current, req.Size)
+	filter, err := toUserFilter(req.UserFilterQuery)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	sorts, err := domain.ParseUserSorts(req.Sort)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	if req.Paging == "cursor" || req.Cursor != "" {
+		u.searchByCursor(ctx, c, domain.UserCursorQuery{
+			Filter: filter,
+			Sorts:  sorts,
+			Cursor: req.Cursor,
+			Size:   req.Size,
+			Count:  domain.CountMode(req.Count),
+		})
+		return
+	}
+	if req.Paging != "" && req.Paging != "offset" {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription("分页方式只能是 offset 或 cursor"), nil)
+		return
+	}
+	list, total, err := u.userSvc.List(ctx, domain.UserQuery{
+		Filter:  filter,
+		Sorts:   sorts,
+		Current: req.Current,
+		Size:    req.Size,
+	})
 	if err != nil {
 		core.SendResponse(c, err, nil)
 		return
 	}
 	// 将数据转换为 VO
 	records := slice.Map(list, func(index int, user domain.User) *vo.UserVO {
-		return u.domainToUserVO(user)
+		return domainToUserVO(user)
 	})
 
 	core.SendResponse(c, nil, vo.PageResult{
@@ -62,12 +127,31 @@ func (u *UserHandler) search(ctx context.Context, c *app.RequestContext) {
 	})
 }
 
+// searchByCursor 游标分页搜索用户
+func (u *UserHandler) searchByCursor(ctx context.Context, c *app.RequestContext, query domain.UserCursorQuery) {
+	page, err := u.userSvc.ListByCursor(ctx, query)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	result := vo.CursorPageResult{
+		Records: slice.Map(page.Users, func(index int, user domain.User) *vo.UserVO {
+			return domainToUserVO(user)
+		}),
+		NextCursor:  page.NextCursor,
+		HasMore:     page.NextCursor != "",
+		Approximate: page.Approximate,
+	}
+	if page.Total >= 0 {
+		result.Total = &page.Total
+	}
+	core.SendResponse(c, nil, result)
+}
+
 // getCurrentUser 获取当前用户信息
 func (u *UserHandler) getCurrentUser(ctx context.Context, c *app.RequestContext) {
-	value, exists := c.Get(constants.LoginUser)
-	loginUser := value.(*vo.UserVO)
-	if !exists {
-		core.SendResponse(c, errno.ErrUnauthorization, nil)
+	loginUser, ok := currentUser(c)
+	if !ok {
 		return
 	}
 	user, err := u.userSvc.GetCurrentUser(ctx, loginUser.ID)
@@ -75,7 +159,44 @@ func (u *UserHandler) getCurrentUser(ctx context.Context, c *app.RequestContext)
 		core.SendResponse(c, err, nil)
 		return
 	}
-	core.SendResponse(c, nil, u.domainToUserVO(user))
+	core.SendResponse(c, nil, domainToUserVO(user))
+}
+
+// getLoginLocks 查询账号或 IP 的登录失败次数和锁定状态
+func (u *UserHandler) getLoginLocks(ctx context.Context, c *app.RequestContext) {
+	var req dto.LoginLockQuery
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	locks, err := u.loginLimitSvc.GetLocks(ctx, req.Account, req.IP)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, slice.Map(locks, func(index int, lock domain.LoginLock) vo.LoginLockVO {
+		return vo.LoginLockVO{
+			Type:       lock.Type,
+			Target:     lock.Target,
+			Failures:   lock.Failures,
+			Locked:     lock.Locked(),
+			RetryAfter: int64(math.Ceil(lock.RetryAfter.Seconds())),
+		}
+	}))
+}
+
+// unlockLogin 解除账号或 IP 的登录锁定
+func (u *UserHandler) unlockLogin(ctx context.Context, c *app.RequestContext) {
+	var req dto.LoginLockQuery
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	if err := u.loginLimitSvc.Unlock(ctx, req.Account, req.IP); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
 }
 
 // delete 删除用户
@@ -86,10 +207,8 @@ func (u *UserHandler) delete(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 
-	value, exists := c.Get(constants.LoginUser)
-	loginUser := value.(*vo.UserVO)
-	if !exists {
-		core.SendResponse(c, errno.ErrUnauthorization, nil)
+	loginUser, ok := currentUser(c)
+	if !ok {
 		return
 	}
 
@@ -109,8 +228,64 @@ func (u *UserHandler) delete(ctx context.Context, c *app.RequestContext) {
 
 // logout 用户退出
 func (u *UserHandler) logout(ctx context.Context, c *app.RequestContext) {
-	err := u.userSvc.Logout(ctx, c)
+	// 通过 JWT 认证的请求, 需要将 token 标记为已退出
+	if _, exists := c.Get(constants.UserLoginState); exists && u.authMode.UseJWT() {
+		if err := u.jwtSvc.ClearToken(ctx, c); err != nil {
+			core.SendResponse(c, err, nil)
+			return
+		}
+	}
+	if u.authMode.UseSession() {
+		if ssid := core.GetSessionID(c); ssid != "" {
+			if err := u.sessionSvc.Revoke(ctx, ssid); err != nil {
+				core.SendResponse(c, err, nil)
+				return
+			}
+		}
+		if err := u.userSvc.Logout(ctx, c); err != nil {
+			core.SendResponse(c, err, nil)
+			return
+		}
+	}
+	core.SendResponse(c, nil, true)
+}
+
+// changePassword 修改密码, 其他设备下线, 当前设备重新保存登录态
+func (u *UserHandler) changePassword(ctx context.Context, c *app.RequestContext) {
+	var req dto.ChangePasswordDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	loginUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := u.userSvc.ChangePassword(ctx, loginUser.ID, req.OldPassword, domain.User{
+		UserPassword:  req.Password,
+		CheckPassword: req.CheckPassword,
+	}, c.ClientIP(), string(c.GetHeader("User-Agent")))
 	if err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	if err = u.setLoginState(ctx, c, user); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
+
+// refreshToken 使用 refresh token 换取新的 access token 和 refresh token
+func (u *UserHandler) refreshToken(ctx context.Context, c *app.RequestContext) {
+	// refresh token 同样通过 Authorization 请求头携带
+	tokenString := u.jwtSvc.ExtractTokenString(c)
+	if tokenString == "" {
+		core.SendResponse(c, errno.ErrUnauthorization.SetDescription("refresh token 不能为空"), nil)
+		return
+	}
+	if err := u.jwtSvc.RefreshToken(ctx, c, tokenString); err != nil {
 		core.SendResponse(c, err, nil)
 		return
 	}
@@ -124,21 +299,132 @@ func (u *UserHandler) login(ctx context.Context, c *app.RequestContext) {
 		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
 		return
 	}
+	ip := c.ClientIP()
+	if err := u.captchaSvc.CheckLogin(ctx, req.Account, ip, req.CaptchaID, req.CaptchaAnswer); err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
 	user, err := u.userSvc.Login(ctx, domain.User{
 		UserAccount:  req.Account,
 		UserPassword: req.Password,
-	})
+	}, ip)
 	if err != nil {
 		core.SendResponse(c, err, nil)
 		return
 	}
-	userVO := u.domainToUserVO(user)
-	err = core.SetUserLoginState(c, &userVO)
+	u.completeLogin(ctx, c, user)
+}
+
+// sendSMSCode 发送短信登录验证码
+func (u *UserHandler) sendSMSCode(ctx context.Context, c *app.RequestContext) {
+	var req dto.SendSMSCodeDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	if err := u.smsSvc.SendLoginCode(ctx, req.Phone); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
+
+// loginSMS 短信验证码登录, 手机号未注册时按配置自动注册
+func (u *UserHandler) loginSMS(ctx context.Context, c *app.RequestContext) {
+	var req dto.UserLoginSMSDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	user, err := u.smsSvc.LoginByCode(ctx, req.Phone, req.Code)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	u.completeLogin(ctx, c, user)
+}
+
+// completeLogin 第一步认证通过后, 启用了两步验证时返回挑战, 否则保存登录态
+func (u *UserHandler) completeLogin(ctx context.Context, c *app.RequestContext, user domain.User) {
+	enabled, err := u.mfaSvc.IsEnabled(ctx, user.ID)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	if enabled {
+		token, err := u.mfaSvc.CreateChallenge(ctx, user.ID)
+		if err != nil {
+			core.SendResponse(c, err, nil)
+			return
+		}
+		core.SendResponse(c, nil, vo.MFAChallengeVO{MFARequired: true, MFAToken: token})
+		return
+	}
+
+	err = u.setLoginState(ctx, c, user)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, domainToUserVO(user))
+}
+
+// loginMFA 登录第二步, 校验 TOTP 验证码或恢复码
+func (u *UserHandler) loginMFA(ctx context.Context, c *app.RequestContext) {
+	var req dto.UserLoginMFADTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	uid, err := u.mfaSvc.VerifyChallenge(ctx, req.MFAToken, req.Code)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	user, err := u.userSvc.GetCurrentUser(ctx, uid)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	if user.IsFreeze() {
+		core.SendResponse(c, errno.ErrForbidden.SetDescription("账号已被冻结"), nil)
+		return
+	}
+
+	err = u.setLoginState(ctx, c, user)
 	if err != nil {
 		core.SendResponse(c, err, nil)
 		return
 	}
-	core.SendResponse(c, nil, userVO)
+	core.SendResponse(c, nil, domainToUserVO(user))
+}
+
+// setLoginState 根据认证方式保存登录态
+// 会话中只保存用户 ID 和安全版本, 角色和状态在每次请求时重新加载
+func (u *UserHandler) setLoginState(ctx context.Context, c *app.RequestContext, user domain.User) error {
+	if u.authMode.UseSession() {
+		ssid, err := u.sessionSvc.Create(ctx, domain.LoginSession{
+			Type:      domain.LoginSessionCookie,
+			UserID:    user.ID,
+			UserAgent: string(c.GetHeader("User-Agent")),
+			IP:        c.ClientIP(),
+		}, core.SessionExpiration())
+		if err != nil {
+			return err
+		}
+		if err = core.SetUserLoginState(c, ssid, &dto.SessionState{
+			Id:              user.ID,
+			SecurityVersion: user.SecurityVersion,
+		}); err != nil {
+			return err
+		}
+	}
+	if u.authMode.UseJWT() {
+		if err := u.jwtSvc.SetLoginToken(ctx, c, user.ID, user.UserRole, user.SecurityVersion); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // register 用户注册
@@ -148,24 +434,45 @@ func (u *UserHandler) register(ctx context.Context, c *app.RequestContext) {
 		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
 		return
 	}
-	id, err := u.userSvc.Register(ctx, domain.User{
+	if err := u.captchaSvc.CheckRegister(ctx, req.CaptchaID, req.CaptchaAnswer); err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	ud := domain.User{
 		Username:      strings.ToUpper(req.Account),
 		UserAccount:   req.Account,
-		AvatarURL:     constants.DefaultAvatar,
 		UserPassword:  req.Password,
 		CheckPassword: req.CheckPassword,
 		PlanetCode:    req.PlanetCode,
-	})
+		Email:         strings.TrimSpace(req.Email),
+	}
+	ud.AvatarURL = u.defaultAvatarSvc.URL(ud.UserAccount, ud.Username)
+	// 开启邮箱验证时, 验证邮箱后才能登录
+	verifyEmail := u.emailVerifySvc.Enabled()
+	if verifyEmail {
+		if ud.Email == "" {
+			core.SendResponse(c, errno.ErrParameterInvalid.SetDescription("邮箱不能为空"), nil)
+			return
+		}
+		ud.UserStatus = constants.UserStatusPending
+	}
+	id, err := u.userSvc.Register(ctx, ud)
 	if err != nil {
 		core.SendResponse(c, err, nil)
 		return
 	}
+	if verifyEmail {
+		// 发送失败不影响注册, 用户可以重新发送
+		if err = u.emailVerifySvc.SendVerifyEmail(ctx, id); err != nil {
+			hlog.CtxErrorf(ctx, "send verify email failed, uid=%d, err=%v", id, err)
+		}
+	}
 	core.SendResponse(c, nil, id)
 }
 
 // domainToUserVO 领域模型转视图模型
-func (u *UserHandler) domainToUserVO(user domain.User) *vo.UserVO {
-	return &vo.UserVO{
+func domainToUserVO(user domain.User) *vo.UserVO {
+	userVO := &vo.UserVO{
 		ID:          user.ID,
 		Username:    user.Username,
 		UserAccount: user.UserAccount,
@@ -178,4 +485,50 @@ func (u *UserHandler) domainToUserVO(user domain.User) *vo.UserVO {
 		UserRole:    user.UserRole,
 		PlanetCode:  user.PlanetCode,
 	}
+	if user.IsFreeze() {
+		userVO.FreezeReason = user.FreezeReason
+		if !user.FreezeUntil.IsZero() {
+			userVO.FreezeUntil = &user.FreezeUntil
+		}
+	}
+	return userVO
+}
+
+// toUserFilter 转换筛选条件, 只有日期的 created_to 包含当天
+func toUserFilter(req dto.UserFilterQuery) (domain.UserFilter, error) {
+	filter := domain.UserFilter{
+		Username:   strings.TrimSpace(req.Username),
+		Account:    strings.TrimSpace(req.Account),
+		PlanetCode: strings.TrimSpace(req.PlanetCode),
+		Email:      strings.TrimSpace(req.Email),
+		Phone:      strings.TrimSpace(req.Phone),
+		Role:       req.Role,
+		Status:     req.Status,
+		Gender:     req.Gender,
+	}
+	var err error
+	if req.CreatedFrom != "" {
+		if filter.CreatedFrom, _, err = parseQueryTime(req.CreatedFrom); err != nil {
+			return filter, errno.ErrParameterInvalid.SetDescription("created_from 格式错误")
+		}
+	}
+	if req.CreatedTo != "" {
+		var dateOnly bool
+		if filter.CreatedTo, dateOnly, err = parseQueryTime(req.CreatedTo); err != nil {
+			return filter, errno.ErrParameterInvalid.SetDescription("created_to 格式错误")
+		}
+		if dateOnly {
+			filter.CreatedTo = filter.CreatedTo.AddDate(0, 0, 1)
+		}
+	}
+	return filter, nil
+}
+
+// parseQueryTime 解析 RFC3339 或 2006-01-02 格式的时间, 日期按本地时区解析
+func parseQueryTime(s string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t, false, err
 }
@@ -0,0 +1,292 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/domain"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/internal/web/dto"
+	"github.com/coderlewin/ucenter/internal/web/middleware"
+	"github.com/coderlewin/ucenter/internal/web/vo"
+	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/coderlewin/ucenter/pkg/spreadsheet"
+	"github.com/duke-git/lancet/v2/slice"
+	"io"
+	"strings"
+	"time"
+)
+
+// UserAdminHandler 管理员管理用户
+type UserAdminHandler struct {
+	userAdminSvc  service.UserAdminService
+	userImportSvc service.UserImportService
+	userExportSvc service.UserExportService
+}
+
+func NewUserAdminHandler(userAdminSvc service.UserAdminService, userImportSvc service.UserImportService,
+	userExportSvc service.UserExportService) *UserAdminHandler {
+	return &UserAdminHandler{userAdminSvc: userAdminSvc, userImportSvc: userImportSvc, userExportSvc: userExportSvc}
+}
+
+// ConfigRoutes 配置路由
+func (u *UserAdminHandler) ConfigRoutes(h *route.RouterGroup) {
+	group := h.Group("/user", middleware.NewCheckRoleMiddlewareBuilder(constants.AdminRole).Build())
+	{
+		group.POST("", u.create)
+		group.POST("/import", u.importUsers)
+		group.GET("/import/:id", u.getImportJob)
+		group.GET("/export", u.export)
+		group.POST("/:id/freeze", u.freeze)
+		group.POST("/:id/unfreeze", u.unfreeze)
+		group.PUT("/:id/role", u.changeRole)
+		group.PUT("/:id/profile", u.updateProfile)
+	}
+}
+
+// create 创建用户, 返回用户 ID
+func (u *UserAdminHandler) create(ctx context.Context, c *app.RequestContext) {
+	var req dto.AdminCreateUserDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	op, ok := u.operator(c)
+	if !ok {
+		return
+	}
+	id, err := u.userAdminSvc.Create(ctx, op, domain.User{
+		Username:     strings.TrimSpace(req.Username),
+		UserAccount:  req.Account,
+		UserPassword: req.Password,
+		PlanetCode:   req.PlanetCode,
+		Email:        strings.TrimSpace(req.Email),
+		UserRole:     req.Role,
+		UserStatus:   req.Status,
+	})
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, id)
+}
+
+// importUsers 上传 CSV 或 XLSX 批量导入用户, multipart 表单的 file 字段为文件, 返回后台任务
+func (u *UserAdminHandler) importUsers(ctx context.Context, c *app.RequestContext) {
+	var req dto.ImportUsersDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	op, ok := u.operator(c)
+	if !ok {
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription("请选择导入文件"), nil)
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+
+	job, err := u.userImportSvc.Import(ctx, op, data, req.DryRun)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, domainToImportJobVO(job))
+}
+
+// getImportJob 查询导入任务的进度和错误报告
+func (u *UserAdminHandler) getImportJob(ctx context.Context, c *app.RequestContext) {
+	var req dto.ImportJobIdDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	job, err := u.userImportSvc.GetJob(ctx, req.ID)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, domainToImportJobVO(job))
+}
+
+// export 按筛选条件导出用户, 边查询边写入响应, 不会一次性加载全部用户
+func (u *UserAdminHandler) export(ctx context.Context, c *app.RequestContext) {
+	var req dto.UserExportQuery
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	op, ok := u.operator(c)
+	if !ok {
+		return
+	}
+	filter, err := toUserFilter(req.UserFilterQuery)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	export := domain.UserExport{
+		Filter: filter,
+		Format: req.Format,
+		Unmask: req.Unmask,
+	}
+	if req.Columns != "" {
+		export.Columns = strings.Split(req.Columns, ",")
+	}
+	// 开始写入响应后无法再返回错误信息, 先校验参数
+	export, err = u.userExportSvc.Prepare(export)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		err := u.userExportSvc.Export(ctx, op, export, pw)
+		if err != nil {
+			hlog.CtxErrorf(ctx, "export users failed, err=%v", err)
+		}
+		_ = pw.CloseWithError(err)
+	}()
+	filename := fmt.Sprintf("users-%s.%s", time.Now().Format("20060102150405"), export.Format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.SetContentType(spreadsheet.ContentType(export.Format))
+	// 响应结束或客户端断开时会关闭 pr, 写入方随之退出
+	c.SetBodyStream(pr, -1)
+}
+
+// freeze 冻结用户, 用户会立即下线
+func (u *UserAdminHandler) freeze(ctx context.Context, c *app.RequestContext) {
+	var req dto.FreezeUserDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	op, ok := u.operator(c)
+	if !ok {
+		return
+	}
+	var until time.Time
+	if req.Until != nil {
+		until = *req.Until
+	}
+	if err := u.userAdminSvc.Freeze(ctx, op, req.ID, req.Reason, until); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
+
+// unfreeze 解冻用户
+func (u *UserAdminHandler) unfreeze(ctx context.Context, c *app.RequestContext) {
+	var req dto.IdInPathDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	op, ok := u.operator(c)
+	if !ok {
+		return
+	}
+	if err := u.userAdminSvc.Unfreeze(ctx, op, req.ID); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
+
+// changeRole 设置或撤销管理员
+func (u *UserAdminHandler) changeRole(ctx context.Context, c *app.RequestContext) {
+	var req dto.ChangeRoleDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	op, ok := u.operator(c)
+	if !ok {
+		return
+	}
+	if err := u.userAdminSvc.ChangeRole(ctx, op, req.ID, req.Role); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
+
+// updateProfile 修改任意用户的资料, 只修改请求中携带的字段
+func (u *UserAdminHandler) updateProfile(ctx context.Context, c *app.RequestContext) {
+	var req dto.AdminUpdateProfileDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	op, ok := u.operator(c)
+	if !ok {
+		return
+	}
+	user, err := u.userAdminSvc.UpdateProfile(ctx, op, req.ID, domain.UserProfile{
+		Username:  req.Username,
+		AvatarURL: req.AvatarURL,
+		Gender:    req.Gender,
+		Phone:     req.Phone,
+		Email:     req.Email,
+	})
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, domainToUserVO(user))
+}
+
+// operator 当前管理员及其客户端信息, 用于记录审计日志
+func (u *UserAdminHandler) operator(c *app.RequestContext) (domain.Operator, bool) {
+	loginUser, ok := currentUser(c)
+	if !ok {
+		return domain.Operator{}, false
+	}
+	return domain.Operator{
+		ID:        loginUser.ID,
+		IP:        c.ClientIP(),
+		UserAgent: string(c.GetHeader("User-Agent")),
+	}, true
+}
+
+func domainToImportJobVO(job domain.ImportJob) *vo.ImportJobVO {
+	jobVO := &vo.ImportJobVO{
+		ID:        job.ID,
+		DryRun:    job.DryRun,
+		Status:    job.Status,
+		Total:     job.Total,
+		Processed: job.Processed,
+		Succeeded: job.Succeeded,
+		Failed:    job.Failed,
+		Errors: slice.Map(job.Errors, func(index int, item domain.ImportRowError) vo.ImportRowErrorVO {
+			return vo.ImportRowErrorVO{Row: item.Row, Account: item.Account, Message: item.Message}
+		}),
+		CreateTime: job.CreateTime,
+	}
+	if jobVO.Errors == nil {
+		jobVO.Errors = []vo.ImportRowErrorVO{}
+	}
+	if !job.FinishTime.IsZero() {
+		jobVO.FinishTime = &job.FinishTime
+	}
+	return jobVO
+}
@@ -0,0 +1,135 @@
+package web
+
+import (
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/internal/web/dto"
+	"github.com/coderlewin/ucenter/internal/web/middleware"
+	"github.com/coderlewin/ucenter/internal/web/vo"
+	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/coderlewin/ucenter/pkg/errno"
+)
+
+// MFAHandler 两步验证的设置
+type MFAHandler struct {
+	mfaSvc service.MFAService
+}
+
+func NewMFAHandler(mfaSvc service.MFAService) *MFAHandler {
+	return &MFAHandler{mfaSvc: mfaSvc}
+}
+
+// ConfigRoutes 配置路由
+func (m *MFAHandler) ConfigRoutes(h *route.RouterGroup) {
+	group := h.Group("/user/mfa")
+	{
+		group.GET("", m.status)
+		group.POST("/totp/setup", m.setupTOTP)
+		group.POST("/totp/confirm", m.confirmTOTP)
+		group.POST("/recovery_codes", m.regenerateRecoveryCodes)
+		group.POST("/disable", m.disable)
+		group.Use(middleware.NewCheckRoleMiddlewareBuilder(constants.AdminRole).Build())
+		group.DELETE("/:id", m.reset)
+	}
+}
+
+// status 当前用户是否已启用两步验证
+func (m *MFAHandler) status(ctx context.Context, c *app.RequestContext) {
+	loginUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	enabled, err := m.mfaSvc.IsEnabled(ctx, loginUser.ID)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, vo.MFAStatusVO{Enabled: enabled})
+}
+
+// setupTOTP 生成 TOTP 密钥, 用户使用验证器应用扫码后需要确认
+func (m *MFAHandler) setupTOTP(ctx context.Context, c *app.RequestContext) {
+	loginUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	secret, uri, err := m.mfaSvc.SetupTOTP(ctx, loginUser.ID)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, vo.TOTPSetupVO{Secret: secret, URI: uri})
+}
+
+// confirmTOTP 确认启用 TOTP, 返回只展示一次的恢复码
+func (m *MFAHandler) confirmTOTP(ctx context.Context, c *app.RequestContext) {
+	var req dto.MFACodeDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	loginUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	codes, err := m.mfaSvc.ConfirmTOTP(ctx, loginUser.ID, req.Code)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, vo.RecoveryCodesVO{RecoveryCodes: codes})
+}
+
+// regenerateRecoveryCodes 重新生成恢复码
+func (m *MFAHandler) regenerateRecoveryCodes(ctx context.Context, c *app.RequestContext) {
+	var req dto.MFACodeDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	loginUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	codes, err := m.mfaSvc.RegenerateRecoveryCodes(ctx, loginUser.ID, req.Code)
+	if err != nil {
+		core.SendResponse(c, err, nil)
+		return
+	}
+	core.SendResponse(c, nil, vo.RecoveryCodesVO{RecoveryCodes: codes})
+}
+
+// disable 关闭两步验证
+func (m *MFAHandler) disable(ctx context.Context, c *app.RequestContext) {
+	var req dto.MFACodeDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	loginUser, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if err := m.mfaSvc.Disable(ctx, loginUser.ID, req.Code); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
+
+// reset 管理员重置用户的两步验证, 用于用户丢失验证器和恢复码的情况
+func (m *MFAHandler) reset(ctx context.Context, c *app.RequestContext) {
+	var req dto.IdInPathDTO
+	if err := c.BindAndValidate(&req); err != nil {
+		core.SendResponse(c, errno.ErrParameterInvalid.SetDescription(err.Error()), nil)
+		return
+	}
+	if err := m.mfaSvc.Reset(ctx, req.ID); err != nil {
+		core.SendResponse(c, err, false)
+		return
+	}
+	core.SendResponse(c, nil, true)
+}
@@ -0,0 +1,8 @@
+package vo
+
+type AvatarVO struct {
+	// AvatarURL 用户头像地址, 即最大尺寸的地址
+	AvatarURL string `json:"avatar_url"`
+	// Sizes 各尺寸的头像地址, key 为边长
+	Sizes map[int]string `json:"sizes"`
+}
@@ -0,0 +1,7 @@
+package vo
+
+type CaptchaVO struct {
+	CaptchaID string `json:"captcha_id"`
+	// Image data URI 格式的 PNG 图片, 可直接作为 img 的 src
+	Image string `json:"image"`
+}
@@ -0,0 +1,10 @@
+package vo
+
+type LoginLockVO struct {
+	Type     string `json:"type"`
+	Target   string `json:"target"`
+	Failures int64  `json:"failures"`
+	Locked   bool   `json:"locked"`
+	// RetryAfter 剩余锁定秒数
+	RetryAfter int64 `json:"retry_after"`
+}
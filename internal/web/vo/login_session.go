@@ -0,0 +1,14 @@
+package vo
+
+type LoginSessionVO struct {
+	ID string `json:"id"`
+	// Type 认证方式, session 或 jwt
+	Type       string `json:"type"`
+	UserAgent  string `json:"user_agent"`
+	IP         string `json:"ip"`
+	CreateTime int64  `json:"create_time"`
+	// LastSeenTime 最后活跃时间, 精确到分钟
+	LastSeenTime int64 `json:"last_seen_time"`
+	// Current 是否为发起请求的会话
+	Current bool `json:"current"`
+}
@@ -4,3 +4,15 @@ type PageResult struct {
 	Records any   `json:"records"`
 	Total   int64 `json:"total"`
 }
+
+// CursorPageResult 游标分页的结果
+type CursorPageResult struct {
+	Records any `json:"records"`
+	// NextCursor 下一页的游标, 为空表示没有下一页
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+	// Total 总数, 未要求统计时不返回
+	Total *int64 `json:"total,omitempty"`
+	// Approximate 为 true 时 Total 是估算值
+	Approximate bool `json:"approximate,omitempty"`
+}
@@ -0,0 +1,24 @@
+package vo
+
+import "time"
+
+type ImportJobVO struct {
+	ID     string `json:"id"`
+	DryRun bool   `json:"dry_run"`
+	// Status running-进行中 finished-已完成 failed-异常中断
+	Status     string             `json:"status"`
+	Total      int                `json:"total"`
+	Processed  int                `json:"processed"`
+	Succeeded  int                `json:"succeeded"`
+	Failed     int                `json:"failed"`
+	Errors     []ImportRowErrorVO `json:"errors"`
+	CreateTime time.Time          `json:"create_time"`
+	FinishTime *time.Time         `json:"finish_time,omitempty"`
+}
+
+type ImportRowErrorVO struct {
+	// Row 表格中的行号, 表头为第 1 行
+	Row     int    `json:"row"`
+	Account string `json:"account"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,21 @@
+package vo
+
+// MFAChallengeVO 密码校验通过但需要完成两步验证时的登录响应
+type MFAChallengeVO struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+}
+
+type MFAStatusVO struct {
+	Enabled bool `json:"enabled"`
+}
+
+type TOTPSetupVO struct {
+	Secret string `json:"secret"`
+	// URI otpauth 格式, 可直接作为二维码内容
+	URI string `json:"uri"`
+}
+
+type RecoveryCodesVO struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
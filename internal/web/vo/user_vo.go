@@ -16,4 +16,15 @@ type UserVO struct {
 	CreateTime  time.Time `json:"create_time"`
 	UserRole    int32     `json:"user_role"`
 	PlanetCode  string    `json:"planet_code"`
+	// FreezeReason 冻结原因, 未冻结时为空
+	FreezeReason string `json:"freeze_reason,omitempty"`
+	// FreezeUntil 冻结截止时间, 永久冻结或未冻结时为空
+	FreezeUntil *time.Time `json:"freeze_until,omitempty"`
+}
+
+// ProfileVO 修改资料的结果
+type ProfileVO struct {
+	*UserVO
+	// PendingEmail 等待确认的新邮箱, 确认链接已发送到该邮箱
+	PendingEmail string `json:"pending_email,omitempty"`
 }
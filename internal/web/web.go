@@ -1,9 +1,24 @@
 package web
 
 import (
+	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/web/vo"
+	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/coderlewin/ucenter/pkg/errno"
 )
 
 type Router interface {
 	ConfigRoutes(h *route.RouterGroup)
 }
+
+// currentUser 返回认证中间件保存的当前用户, 未登录时直接响应错误
+func currentUser(c *app.RequestContext) (*vo.UserVO, bool) {
+	value, exists := c.Get(constants.LoginUser)
+	if !exists {
+		core.SendResponse(c, errno.ErrUnauthorization, nil)
+		return nil, false
+	}
+	return value.(*vo.UserVO), true
+}
@@ -0,0 +1,32 @@
+package web
+
+import (
+	"context"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/coderlewin/ucenter/internal/service"
+	"net/http"
+)
+
+// WellKnownHandler 提供 /.well-known 下的公开元数据
+type WellKnownHandler struct {
+	jwtSvc service.JWTService
+}
+
+func NewWellKnownHandler(jwtSvc service.JWTService) *WellKnownHandler {
+	return &WellKnownHandler{jwtSvc: jwtSvc}
+}
+
+// ConfigRoutes 配置路由, 需要挂载在根路径下
+func (w *WellKnownHandler) ConfigRoutes(h *route.RouterGroup) {
+	group := h.Group("/.well-known")
+	{
+		group.GET("/jwks.json", w.jwks)
+	}
+}
+
+// jwks 返回校验 token 签名的公钥集合, 按 RFC 7517 格式直接输出
+func (w *WellKnownHandler) jwks(ctx context.Context, c *app.RequestContext) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, w.jwtSvc.JWKS())
+}
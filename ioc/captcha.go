@@ -0,0 +1,51 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/pkg/captcha"
+	"github.com/spf13/viper"
+	"time"
+)
+
+func InitCaptchaOptions() service.CaptchaOptions {
+	viper.SetDefault("captcha.type", "digit")
+	viper.SetDefault("captcha.length", 4)
+	viper.SetDefault("captcha.width", 120)
+	viper.SetDefault("captcha.height", 40)
+	viper.SetDefault("captcha.expiration", 5*time.Minute)
+	viper.SetDefault("captcha.register", service.CaptchaPolicyAlways)
+	viper.SetDefault("captcha.login", service.CaptchaPolicyAfterFailures)
+	viper.SetDefault("captcha.login-failures", 3)
+
+	var gen captcha.Generator
+	switch typ := viper.GetString("captcha.type"); typ {
+	case "digit":
+		gen = captcha.NewDigitGenerator(viper.GetInt("captcha.length"))
+	case "math":
+		gen = captcha.NewMathGenerator()
+	default:
+		panic(fmt.Errorf("不支持的验证码类型 %s", typ))
+	}
+
+	opts := service.CaptchaOptions{
+		Generator:      gen,
+		Width:          viper.GetInt("captcha.width"),
+		Height:         viper.GetInt("captcha.height"),
+		Expiration:     viper.GetDuration("captcha.expiration"),
+		RegisterPolicy: viper.GetString("captcha.register"),
+		LoginPolicy:    viper.GetString("captcha.login"),
+		LoginFailures:  viper.GetInt64("captcha.login-failures"),
+	}
+	switch opts.RegisterPolicy {
+	case service.CaptchaPolicyAlways, service.CaptchaPolicyNever:
+	default:
+		panic(fmt.Errorf("不支持的注册验证码策略 %s", opts.RegisterPolicy))
+	}
+	switch opts.LoginPolicy {
+	case service.CaptchaPolicyAlways, service.CaptchaPolicyNever, service.CaptchaPolicyAfterFailures:
+	default:
+		panic(fmt.Errorf("不支持的登录验证码策略 %s", opts.LoginPolicy))
+	}
+	return opts
+}
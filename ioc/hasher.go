@@ -0,0 +1,73 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/internal/constants"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/pkg/hasher"
+	"github.com/coderlewin/ucenter/pkg/pwdpolicy"
+	"github.com/spf13/viper"
+)
+
+func InitPasswordHasher() hasher.PasswordHasher {
+	// 历史数据使用 MD5 加盐, 保留用于校验并在登录时升级
+	legacy := hasher.NewLegacyMD5Scheme(constants.PwdSalt)
+
+	switch scheme := viper.GetString("password.scheme"); scheme {
+	case "", "argon2id":
+		opts := hasher.DefaultArgon2idOptions()
+		if viper.IsSet("password.argon2id.memory") {
+			opts.Memory = viper.GetUint32("password.argon2id.memory")
+		}
+		if viper.IsSet("password.argon2id.iterations") {
+			opts.Iterations = viper.GetUint32("password.argon2id.iterations")
+		}
+		if viper.IsSet("password.argon2id.parallelism") {
+			opts.Parallelism = uint8(viper.GetUint("password.argon2id.parallelism"))
+		}
+		return hasher.NewPasswordHasher(hasher.NewArgon2idScheme(opts), hasher.NewBcryptScheme(0), legacy)
+	case "bcrypt":
+		primary := hasher.NewBcryptScheme(viper.GetInt("password.bcrypt.cost"))
+		return hasher.NewPasswordHasher(primary, hasher.NewArgon2idScheme(hasher.DefaultArgon2idOptions()), legacy)
+	default:
+		panic(fmt.Errorf("不支持的密码哈希算法 %s", scheme))
+	}
+}
+
+func InitPasswordPolicyOptions() service.PasswordPolicyOptions {
+	viper.SetDefault("password.policy.min-length", 8)
+	viper.SetDefault("password.policy.max-length", 64)
+	viper.SetDefault("password.policy.disallow-account", true)
+	viper.SetDefault("password.policy.min-strength", 1)
+	viper.SetDefault("password.policy.history-size", 5)
+
+	opts := pwdpolicy.Options{
+		MinLength:       viper.GetInt("password.policy.min-length"),
+		MaxLength:       viper.GetInt("password.policy.max-length"),
+		RequiredClasses: viper.GetStringSlice("password.policy.required-classes"),
+		DisallowAccount: viper.GetBool("password.policy.disallow-account"),
+		MinStrength:     viper.GetInt("password.policy.min-strength"),
+	}
+	// bcrypt 会拒绝超过 72 字节的密码, 需要在策略中提前限制
+	if viper.GetString("password.scheme") == "bcrypt" &&
+		(opts.MaxLength <= 0 || opts.MaxLength > hasher.BcryptMaxPasswordBytes) {
+		panic(fmt.Errorf("使用 bcrypt 时 password.policy.max-length 必须在 1-%d 之间", hasher.BcryptMaxPasswordBytes))
+	}
+	if path := viper.GetString("password.policy.breached-file"); path != "" {
+		breached, err := pwdpolicy.LoadBreachedFile(path)
+		if err != nil {
+			panic(fmt.Errorf("加载泄露密码列表失败, 原因 %w", err))
+		}
+		hlog.Infof("loaded %d breached passwords from %s", breached.Len(), path)
+		opts.Breached = breached
+	}
+	policy, err := pwdpolicy.New(opts)
+	if err != nil {
+		panic(err)
+	}
+	return service.PasswordPolicyOptions{
+		Policy:      policy,
+		HistorySize: viper.GetInt("password.policy.history-size"),
+	}
+}
@@ -2,46 +2,106 @@ package ioc
 
 import (
 	"context"
+	"fmt"
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/internal/service"
 	"github.com/coderlewin/ucenter/internal/web"
 	"github.com/coderlewin/ucenter/internal/web/middleware"
-	"github.com/hertz-contrib/sessions"
-	"github.com/hertz-contrib/sessions/cookie"
+	"github.com/coderlewin/ucenter/pkg/ratelimit"
 	"github.com/spf13/viper"
 	"time"
 )
 
-func InitWebServer(mws []app.HandlerFunc, userHdl *web.UserHandler) *server.Hertz {
+func InitWebServer(mws []app.HandlerFunc, userHdl *web.UserHandler, sessionHdl *web.SessionHandler,
+	profileHdl *web.ProfileHandler, userAdminHdl *web.UserAdminHandler,
+	defaultAvatarHdl *web.DefaultAvatarHandler, mfaHdl *web.MFAHandler,
+	captchaHdl *web.CaptchaHandler, pwdResetHdl *web.PasswordResetHandler,
+	emailVerifyHdl *web.EmailVerificationHandler, wellKnownHdl *web.WellKnownHandler, oidcHdl *web.OIDCHandler, oauthClientHdl *web.OAuthClientHandler) *server.Hertz {
 	engine := server.Default(
 		server.WithHostPorts(viper.GetString("server.port")),
+		server.WithMaxRequestBodySize(maxRequestBodySize()),
 	)
+	engine.SetClientIPFunc(clientIPFunc())
 
 	engine.Use(mws...)
 
+	localStorageRoutes(engine)
+
+	root := engine.Group("")
+	wellKnownHdl.ConfigRoutes(root)
+	oidcHdl.ConfigRoutes(root)
+
 	g := engine.Group(viper.GetString("server.prefix"))
 	userHdl.ConfigRoutes(g)
+	sessionHdl.ConfigRoutes(g)
+	profileHdl.ConfigRoutes(g)
+	userAdminHdl.ConfigRoutes(g)
+	defaultAvatarHdl.ConfigRoutes(g)
+	mfaHdl.ConfigRoutes(g)
+	captchaHdl.ConfigRoutes(g)
+	pwdResetHdl.ConfigRoutes(g)
+	emailVerifyHdl.ConfigRoutes(g)
+	oauthClientHdl.ConfigRoutes(g)
 
 	return engine
 }
 
-func CommonMiddlewares() []app.HandlerFunc {
-	return []app.HandlerFunc{
-		sessionHandlerFunc(),
-		accessLog(),
-		middleware.NewCheckSessionAuthMiddlewareBuilder().Build(),
+// maxRequestBodySize 请求体的最大字节数, 与 Hertz 的默认值相同
+func maxRequestBodySize() int {
+	viper.SetDefault("server.max-request-body-size", 4<<20)
+	return viper.GetInt("server.max-request-body-size")
+}
+
+// clientIPFunc 默认不信任任何代理, 部署在反向代理之后时需要配置代理的地址
+func clientIPFunc() app.ClientIP {
+	fn, err := middleware.NewClientIPFunc(viper.GetStringSlice("server.trusted-proxies"))
+	if err != nil {
+		panic(err)
 	}
+	return fn
 }
 
-func sessionHandlerFunc() app.HandlerFunc {
-	store := cookie.NewStore(
-		[]byte("moyn8y9abnd7q4zkq2m73yw8tu9j5ixm"),
-		[]byte("o6jdlo2cb9f9pb6h46fjmllw481ldebj"),
-	)
+func InitAuthMode() web.AuthMode {
+	mode := web.AuthMode(viper.GetString("auth.mode"))
+	if mode == "" {
+		return web.AuthModeSession
+	}
+	if !mode.IsValid() {
+		panic(fmt.Errorf("不支持的认证方式 %s", mode))
+	}
+	return mode
+}
 
-	// cookie 名字是 ssid
-	return sessions.New("ssid", store)
+func CommonMiddlewares(authMode web.AuthMode, jwtSvc service.JWTService, loginStateSvc service.LoginStateService,
+	limiter ratelimit.Limiter) []app.HandlerFunc {
+	mws := []app.HandlerFunc{
+		sessionHandlerFunc(),
+		accessLog(),
+	}
+	prefixes := publicPrefixes()
+	switch authMode {
+	case web.AuthModeJWT:
+		mws = append(mws, middleware.NewCheckJWTAuthMiddlewareBuilder(jwtSvc, loginStateSvc).
+			PublicPrefixes(prefixes...).Build())
+	case web.AuthModeBoth:
+		// 携带了 token 的请求优先使用 JWT 认证, 否则使用会话认证
+		mws = append(mws,
+			middleware.NewCheckJWTAuthMiddlewareBuilder(jwtSvc, loginStateSvc).Optional().
+				PublicPrefixes(prefixes...).Build(),
+			middleware.NewCheckSessionAuthMiddlewareBuilder(jwtSvc, loginStateSvc).
+				PublicPrefixes(prefixes...).Build(),
+		)
+	default:
+		mws = append(mws, middleware.NewCheckSessionAuthMiddlewareBuilder(jwtSvc, loginStateSvc).
+			PublicPrefixes(prefixes...).Build())
+	}
+	// 放在认证之后, 才能按登录用户限流
+	if viper.GetBool("rate-limit.enabled") {
+		mws = append(mws, rateLimitMiddleware(limiter).Build())
+	}
+	return mws
 }
 
 func accessLog() app.HandlerFunc {
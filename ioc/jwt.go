@@ -0,0 +1,82 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/pkg/keyring"
+	"github.com/spf13/viper"
+	"os"
+	"time"
+)
+
+type jwtKeyConfig struct {
+	Kid            string `mapstructure:"kid"`
+	Alg            string `mapstructure:"alg"`
+	PrivateKey     string `mapstructure:"private-key"`
+	PrivateKeyFile string `mapstructure:"private-key-file"`
+	NotBefore      string `mapstructure:"not-before"`
+	ExpiresAt      string `mapstructure:"expires-at"`
+}
+
+func InitKeyRing() *keyring.KeyRing {
+	var cfgs []jwtKeyConfig
+	if err := viper.UnmarshalKey("jwt.keys", &cfgs); err != nil {
+		panic(fmt.Errorf("读取 JWT 密钥配置失败, 原因 %w", err))
+	}
+
+	// 未配置密钥时生成临时密钥, 重启后已签发的 token 全部失效, 仅用于本地开发
+	if len(cfgs) == 0 {
+		hlog.Warn("jwt.keys is not configured, using an ephemeral EdDSA key")
+		key, err := keyring.GenerateKey("ephemeral", keyring.AlgEdDSA)
+		if err != nil {
+			panic(err)
+		}
+		ring, err := keyring.New(key)
+		if err != nil {
+			panic(err)
+		}
+		return ring
+	}
+
+	keys := make([]keyring.Key, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		key, err := loadJWTKey(cfg)
+		if err != nil {
+			panic(fmt.Errorf("加载 JWT 密钥 %s 失败, 原因 %w", cfg.Kid, err))
+		}
+		keys = append(keys, key)
+	}
+	ring, err := keyring.New(keys...)
+	if err != nil {
+		panic(fmt.Errorf("初始化 JWT 密钥环失败, 原因 %w", err))
+	}
+	return ring
+}
+
+func loadJWTKey(cfg jwtKeyConfig) (keyring.Key, error) {
+	data := []byte(cfg.PrivateKey)
+	if cfg.PrivateKeyFile != "" {
+		var err error
+		data, err = os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return keyring.Key{}, err
+		}
+	}
+	signer, err := keyring.ParsePrivateKey(data)
+	if err != nil {
+		return keyring.Key{}, err
+	}
+
+	key := keyring.Key{ID: cfg.Kid, Algorithm: cfg.Alg, Private: signer}
+	if cfg.NotBefore != "" {
+		if key.NotBefore, err = time.Parse(time.RFC3339, cfg.NotBefore); err != nil {
+			return keyring.Key{}, err
+		}
+	}
+	if cfg.ExpiresAt != "" {
+		if key.ExpiresAt, err = time.Parse(time.RFC3339, cfg.ExpiresAt); err != nil {
+			return keyring.Key{}, err
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,22 @@
+package ioc
+
+import (
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/spf13/viper"
+	"time"
+)
+
+func InitLoginLimitOptions() service.LoginLimitOptions {
+	viper.SetDefault("login-limit.account-max-failures", 5)
+	viper.SetDefault("login-limit.ip-max-failures", 20)
+	viper.SetDefault("login-limit.base-lock-duration", time.Minute)
+	viper.SetDefault("login-limit.max-lock-duration", time.Hour)
+	viper.SetDefault("login-limit.failure-window", 15*time.Minute)
+	return service.LoginLimitOptions{
+		AccountMaxFailures: viper.GetInt64("login-limit.account-max-failures"),
+		IPMaxFailures:      viper.GetInt64("login-limit.ip-max-failures"),
+		BaseLockDuration:   viper.GetDuration("login-limit.base-lock-duration"),
+		MaxLockDuration:    viper.GetDuration("login-limit.max-lock-duration"),
+		FailureWindow:      viper.GetDuration("login-limit.failure-window"),
+	}
+}
@@ -0,0 +1,66 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/pkg/mail"
+	"github.com/spf13/viper"
+	"time"
+)
+
+func InitMailer() mail.Mailer {
+	viper.SetDefault("mail.driver", "memory")
+	viper.SetDefault("mail.smtp.port", 587)
+	viper.SetDefault("mail.smtp.tls", mail.TLSModeStartTLS)
+	viper.SetDefault("mail.smtp.timeout", 10*time.Second)
+
+	switch driver := viper.GetString("mail.driver"); driver {
+	case "smtp":
+		mailer, err := mail.NewSMTPMailer(mail.SMTPOptions{
+			Host:     viper.GetString("mail.smtp.host"),
+			Port:     viper.GetInt("mail.smtp.port"),
+			Username: viper.GetString("mail.smtp.username"),
+			Password: viper.GetString("mail.smtp.password"),
+			From:     viper.GetString("mail.from"),
+			TLSMode:  viper.GetString("mail.smtp.tls"),
+			Timeout:  viper.GetDuration("mail.smtp.timeout"),
+		})
+		if err != nil {
+			panic(err)
+		}
+		return mailer
+	case "memory":
+		hlog.Warn("mail.driver 为 memory, 邮件不会真正发送")
+		return mail.NewMemoryMailer()
+	default:
+		panic(fmt.Errorf("不支持的邮件发送方式 %s", driver))
+	}
+}
+
+func InitPasswordResetOptions() service.PasswordResetOptions {
+	viper.SetDefault("password-reset.url", "http://localhost:3000/reset-password?token=%s")
+	viper.SetDefault("password-reset.expiration", 30*time.Minute)
+	viper.SetDefault("password-reset.resend-interval", time.Minute)
+	return service.PasswordResetOptions{
+		URL:            viper.GetString("password-reset.url"),
+		Expiration:     viper.GetDuration("password-reset.expiration"),
+		ResendInterval: viper.GetDuration("password-reset.resend-interval"),
+	}
+}
+
+func InitEmailVerificationOptions() service.EmailVerificationOptions {
+	viper.SetDefault("email-verification.enabled", false)
+	viper.SetDefault("email-verification.url", "http://localhost:8080/api/user/email/verify?token=%s")
+	viper.SetDefault("email-verification.change-url", "http://localhost:8080/api/user/profile/email/confirm?token=%s")
+	viper.SetDefault("email-verification.expiration", 24*time.Hour)
+	viper.SetDefault("email-verification.resend-interval", time.Minute)
+	opts := service.EmailVerificationOptions{
+		Enabled:        viper.GetBool("email-verification.enabled"),
+		URL:            viper.GetString("email-verification.url"),
+		ChangeURL:      viper.GetString("email-verification.change-url"),
+		Expiration:     viper.GetDuration("email-verification.expiration"),
+		ResendInterval: viper.GetDuration("email-verification.resend-interval"),
+	}
+	return opts
+}
@@ -0,0 +1,18 @@
+package ioc
+
+import (
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/spf13/viper"
+	"time"
+)
+
+func InitMFAOptions() service.MFAOptions {
+	viper.SetDefault("mfa.issuer", "UCenter")
+	viper.SetDefault("mfa.challenge-expiration", 5*time.Minute)
+	viper.SetDefault("mfa.max-challenge-attempts", 5)
+	return service.MFAOptions{
+		Issuer:               viper.GetString("mfa.issuer"),
+		ChallengeExpiration:  viper.GetDuration("mfa.challenge-expiration"),
+		MaxChallengeAttempts: viper.GetInt64("mfa.max-challenge-attempts"),
+	}
+}
@@ -0,0 +1,22 @@
+package ioc
+
+import (
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/spf13/viper"
+	"time"
+)
+
+func InitOIDCOptions() service.OIDCOptions {
+	viper.SetDefault("oidc.issuer", "http://localhost:8080")
+	viper.SetDefault("oidc.require-pkce", true)
+	viper.SetDefault("oidc.code-expiration", 10*time.Minute)
+	viper.SetDefault("oidc.access-token-expiration", time.Hour)
+	viper.SetDefault("oidc.id-token-expiration", time.Hour)
+	return service.OIDCOptions{
+		Issuer:                viper.GetString("oidc.issuer"),
+		CodeExpiration:        viper.GetDuration("oidc.code-expiration"),
+		AccessTokenExpiration: viper.GetDuration("oidc.access-token-expiration"),
+		IDTokenExpiration:     viper.GetDuration("oidc.id-token-expiration"),
+		RequirePKCE:           viper.GetBool("oidc.require-pkce"),
+	}
+}
@@ -0,0 +1,35 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/coderlewin/ucenter/internal/web/middleware"
+	"github.com/coderlewin/ucenter/pkg/ratelimit"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+// InitRateLimiter Redis 不可用时降级为单机限流
+func InitRateLimiter(cmd redis.Cmdable) ratelimit.Limiter {
+	return ratelimit.NewFallbackLimiter(
+		ratelimit.NewRedisSlideWindowLimiter(cmd),
+		ratelimit.NewLocalSlideWindowLimiter(),
+	)
+}
+
+func rateLimitMiddleware(limiter ratelimit.Limiter) *middleware.RateLimitMiddlewareBuilder {
+	var rules []middleware.RateLimitRule
+	if err := viper.UnmarshalKey("rate-limit.rules", &rules); err != nil {
+		panic(fmt.Errorf("读取限流规则失败: %w", err))
+	}
+	for _, rule := range rules {
+		switch rule.Key {
+		case "", middleware.RateLimitByIP, middleware.RateLimitByUser, middleware.RateLimitByRoute:
+		default:
+			panic(fmt.Errorf("不支持的限流维度 %s", rule.Key))
+		}
+		if rule.Limit <= 0 || rule.Window <= 0 {
+			panic(fmt.Errorf("限流规则 %s 的 limit 和 window 必须大于 0", rule.Path))
+		}
+	}
+	return middleware.NewRateLimitMiddlewareBuilder(limiter, rules)
+}
@@ -0,0 +1,136 @@
+package ioc
+
+import (
+	"crypto/rand"
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/pkg/core"
+	"github.com/duke-git/lancet/v2/slice"
+	"github.com/hertz-contrib/sessions"
+	"github.com/hertz-contrib/sessions/cookie"
+	"github.com/hertz-contrib/sessions/redis"
+	"github.com/spf13/viper"
+	"net/http"
+	"strings"
+)
+
+// leakedSessionKeys 曾经作为默认配置提交到仓库中的密钥, 已经公开, 不能再使用
+var leakedSessionKeys = []string{
+	"moyn8y9abnd7q4zkq2m73yw8tu9j5ixm",
+	"o6jdlo2cb9f9pb6h46fjmllw481ldebj",
+}
+
+type sessionKeyConfig struct {
+	Auth       string `mapstructure:"auth"`
+	Encryption string `mapstructure:"encryption"`
+}
+
+func sessionHandlerFunc() app.HandlerFunc {
+	viper.SetDefault("session.store", "redis")
+	viper.SetDefault("session.name", "ssid")
+	viper.SetDefault("session.redis.key-prefix", "ucenter:session:")
+	viper.SetDefault("session.redis.pool-size", 10)
+	viper.SetDefault("session.cookie.path", "/")
+	viper.SetDefault("session.cookie.max-age", 86400)
+	viper.SetDefault("session.cookie.http-only", true)
+	viper.SetDefault("session.cookie.same-site", "lax")
+
+	opts := sessions.Options{
+		Path:     viper.GetString("session.cookie.path"),
+		Domain:   viper.GetString("session.cookie.domain"),
+		MaxAge:   viper.GetInt("session.cookie.max-age"),
+		Secure:   viper.GetBool("session.cookie.secure"),
+		HttpOnly: viper.GetBool("session.cookie.http-only"),
+		SameSite: parseSameSite(viper.GetString("session.cookie.same-site")),
+	}
+	if opts.MaxAge <= 0 {
+		panic(fmt.Errorf("session.cookie.max-age 必须大于 0"))
+	}
+	// 浏览器要求 SameSite=None 的 cookie 必须是 Secure 的
+	if opts.SameSite == http.SameSiteNoneMode && !opts.Secure {
+		panic(fmt.Errorf("session.cookie.same-site 为 none 时必须开启 secure"))
+	}
+	core.SetCookieOptions(opts)
+
+	keyPairs := sessionKeyPairs()
+	var store sessions.Store
+	switch typ := viper.GetString("session.store"); typ {
+	case "redis":
+		rs, err := redis.NewStoreWithDB(viper.GetInt("session.redis.pool-size"), "tcp",
+			viper.GetString("redis.addr"), viper.GetString("redis.pass"), viper.GetString("redis.db"), keyPairs...)
+		if err != nil {
+			panic(fmt.Errorf("初始化 Redis 会话存储失败, 原因 %w", err))
+		}
+		if err = redis.SetKeyPrefix(rs, viper.GetString("session.redis.key-prefix")); err != nil {
+			panic(err)
+		}
+		store = rs
+	case "cookie":
+		// 会话数据全部保存在客户端, 无法在服务端删除, 仅用于本地开发
+		store = cookie.NewStore(keyPairs...)
+	default:
+		panic(fmt.Errorf("不支持的会话存储 %s", typ))
+	}
+	store.Options(opts)
+
+	return sessions.New(viper.GetString("session.name"), store)
+}
+
+// sessionKeyPairs 读取 cookie 的签名和加密密钥, 第一对用于签发, 全部用于校验, 以便轮换
+func sessionKeyPairs() [][]byte {
+	var cfgs []sessionKeyConfig
+	if err := viper.UnmarshalKey("session.keys", &cfgs); err != nil {
+		panic(fmt.Errorf("读取会话密钥配置失败, 原因 %w", err))
+	}
+
+	// 未配置密钥时生成临时密钥, 重启后已登录的用户全部失效, 仅用于本地开发
+	if len(cfgs) == 0 {
+		hlog.Warn("session.keys is not configured, using ephemeral keys")
+		return [][]byte{randomKey(32), randomKey(32)}
+	}
+
+	pairs := make([][]byte, 0, len(cfgs)*2)
+	for i, cfg := range cfgs {
+		if slice.Contain(leakedSessionKeys, cfg.Auth) || slice.Contain(leakedSessionKeys, cfg.Encryption) {
+			panic(fmt.Errorf("第 %d 个会话密钥已经公开, 请重新生成", i+1))
+		}
+		if len(cfg.Auth) < 32 {
+			panic(fmt.Errorf("第 %d 个会话签名密钥长度不能少于 32 个字符", i+1))
+		}
+		switch len(cfg.Encryption) {
+		case 0, 16, 24, 32:
+		default:
+			panic(fmt.Errorf("第 %d 个会话加密密钥长度必须为 16、24 或 32 个字符", i+1))
+		}
+		var enc []byte
+		if cfg.Encryption != "" {
+			enc = []byte(cfg.Encryption)
+		}
+		pairs = append(pairs, []byte(cfg.Auth), enc)
+	}
+	return pairs
+}
+
+func parseSameSite(mode string) http.SameSite {
+	switch strings.ToLower(mode) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	case "lax":
+		return http.SameSiteLaxMode
+	case "":
+		return http.SameSiteDefaultMode
+	default:
+		panic(fmt.Errorf("不支持的 SameSite 模式 %s", mode))
+	}
+}
+
+func randomKey(n int) []byte {
+	key := make([]byte, n)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}
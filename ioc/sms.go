@@ -0,0 +1,38 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/pkg/sms"
+	"github.com/spf13/viper"
+	"time"
+)
+
+func InitSMSSender() sms.Sender {
+	viper.SetDefault("sms.provider", "log")
+	switch provider := viper.GetString("sms.provider"); provider {
+	case "log":
+		return sms.NewLogSender()
+	case "memory":
+		return sms.NewMemorySender()
+	default:
+		panic(fmt.Errorf("不支持的短信服务商 %s", provider))
+	}
+}
+
+func InitSMSOptions() service.SMSOptions {
+	viper.SetDefault("sms.default-country-code", "86")
+	viper.SetDefault("sms.code-expiration", 5*time.Minute)
+	viper.SetDefault("sms.resend-interval", time.Minute)
+	viper.SetDefault("sms.daily-limit", 10)
+	viper.SetDefault("sms.max-verify-attempts", 3)
+	viper.SetDefault("sms.auto-register", true)
+	return service.SMSOptions{
+		DefaultCountryCode: viper.GetString("sms.default-country-code"),
+		CodeExpiration:     viper.GetDuration("sms.code-expiration"),
+		ResendInterval:     viper.GetDuration("sms.resend-interval"),
+		DailyLimit:         viper.GetInt64("sms.daily-limit"),
+		MaxVerifyAttempts:  viper.GetInt64("sms.max-verify-attempts"),
+		AutoRegister:       viper.GetBool("sms.auto-register"),
+	}
+}
@@ -0,0 +1,132 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/coderlewin/ucenter/pkg/storage"
+	"github.com/spf13/viper"
+	"sort"
+	"strings"
+	"time"
+)
+
+func InitBlobStorage() storage.BlobStorage {
+	viper.SetDefault("storage.driver", "local")
+	viper.SetDefault("storage.local.dir", "data/uploads")
+	viper.SetDefault("storage.local.path", "/uploads")
+	viper.SetDefault("storage.local.base-url", "http://localhost:8080/uploads")
+	viper.SetDefault("storage.s3.region", "us-east-1")
+	viper.SetDefault("storage.s3.timeout", 30*time.Second)
+
+	switch driver := viper.GetString("storage.driver"); driver {
+	case "local":
+		hlog.Warn("storage.driver 为 local, 多实例部署时需要共享存储目录")
+		s, err := storage.NewLocalStorage(storage.LocalOptions{
+			Dir:     viper.GetString("storage.local.dir"),
+			BaseURL: viper.GetString("storage.local.base-url"),
+		})
+		if err != nil {
+			panic(err)
+		}
+		return s
+	case "s3":
+		s, err := storage.NewS3Storage(storage.S3Options{
+			Endpoint:  viper.GetString("storage.s3.endpoint"),
+			Region:    viper.GetString("storage.s3.region"),
+			Bucket:    viper.GetString("storage.s3.bucket"),
+			AccessKey: viper.GetString("storage.s3.access-key"),
+			SecretKey: viper.GetString("storage.s3.secret-key"),
+			PathStyle: viper.GetBool("storage.s3.path-style"),
+			BaseURL:   viper.GetString("storage.s3.base-url"),
+			Timeout:   viper.GetDuration("storage.s3.timeout"),
+		})
+		if err != nil {
+			panic(err)
+		}
+		return s
+	default:
+		panic(fmt.Errorf("不支持的存储方式 %s", driver))
+	}
+}
+
+// localStoragePath 本地存储时 ucenter 提供文件访问的路由, 其他存储方式时为空
+func localStoragePath() string {
+	if viper.GetString("storage.driver") != "local" {
+		return ""
+	}
+	return "/" + strings.Trim(viper.GetString("storage.local.path"), "/")
+}
+
+// localStorageRoutes 本地存储时由 ucenter 自身提供文件访问
+func localStorageRoutes(engine *server.Hertz) {
+	prefix := localStoragePath()
+	if prefix == "" {
+		return
+	}
+	engine.StaticFS(prefix, &app.FS{
+		Root:        viper.GetString("storage.local.dir"),
+		PathRewrite: app.NewPathSlashesStripper(strings.Count(prefix, "/")),
+	})
+}
+
+func InitAvatarOptions() service.AvatarOptions {
+	viper.SetDefault("avatar.max-size", 2<<20)
+	viper.SetDefault("avatar.max-pixels", 25_000_000)
+	viper.SetDefault("avatar.sizes", []int{256, 128, 64})
+	opts := service.AvatarOptions{
+		MaxSize:   viper.GetInt64("avatar.max-size"),
+		MaxPixels: viper.GetInt("avatar.max-pixels"),
+		Sizes:     viper.GetIntSlice("avatar.sizes"),
+	}
+	if len(opts.Sizes) == 0 {
+		panic(fmt.Errorf("avatar.sizes 不能为空"))
+	}
+	for _, size := range opts.Sizes {
+		if size <= 0 || size > 1024 {
+			panic(fmt.Errorf("avatar.sizes 中的尺寸 %d 必须在 1-1024 之间", size))
+		}
+	}
+	if opts.MaxSize > int64(maxRequestBodySize()) {
+		panic(fmt.Errorf("avatar.max-size 不能大于 server.max-request-body-size"))
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(opts.Sizes)))
+	return opts
+}
+
+func InitDefaultAvatarOptions() service.DefaultAvatarOptions {
+	viper.SetDefault("avatar.default.style", service.DefaultAvatarStyleIdenticon)
+	viper.SetDefault("avatar.default.base-url", "http://localhost:8080/api/avatar")
+	viper.SetDefault("avatar.default.size", 256)
+	opts := service.DefaultAvatarOptions{
+		Style:   viper.GetString("avatar.default.style"),
+		BaseURL: viper.GetString("avatar.default.base-url"),
+		URL:     viper.GetString("avatar.default.url"),
+		Size:    viper.GetInt("avatar.default.size"),
+	}
+	switch opts.Style {
+	case service.DefaultAvatarStyleIdenticon, service.DefaultAvatarStyleInitials:
+		if opts.Size < 16 || opts.Size > 1024 {
+			panic(fmt.Errorf("avatar.default.size 必须在 16-1024 之间"))
+		}
+	case service.DefaultAvatarStyleURL:
+		if opts.URL == "" {
+			panic(fmt.Errorf("avatar.default.style 为 url 时必须配置 avatar.default.url"))
+		}
+	default:
+		panic(fmt.Errorf("不支持的默认头像样式 %s", opts.Style))
+	}
+	return opts
+}
+
+// publicPrefixes 不需要认证的路径前缀, 头像需要能被其他站点直接引用
+func publicPrefixes() []string {
+	// 默认头像由 ucenter 实时生成
+	prefixes := []string{strings.TrimRight(viper.GetString("server.prefix"), "/") + "/avatar/"}
+	if path := localStoragePath(); path != "" {
+		prefixes = append(prefixes, path+"/")
+	}
+	return prefixes
+}
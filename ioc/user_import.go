@@ -0,0 +1,21 @@
+package ioc
+
+import (
+	"fmt"
+	"github.com/coderlewin/ucenter/internal/service"
+	"github.com/spf13/viper"
+	"time"
+)
+
+func InitUserImportOptions() service.UserImportOptions {
+	viper.SetDefault("user-import.max-rows", 5000)
+	viper.SetDefault("user-import.job-expiration", 24*time.Hour)
+	opts := service.UserImportOptions{
+		MaxRows:       viper.GetInt("user-import.max-rows"),
+		JobExpiration: viper.GetDuration("user-import.job-expiration"),
+	}
+	if opts.MaxRows <= 0 {
+		panic(fmt.Errorf("user-import.max-rows 必须大于 0"))
+	}
+	return opts
+}
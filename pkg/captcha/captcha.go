@@ -0,0 +1,78 @@
+// Package captcha 在本地生成图形验证码, 不依赖外部服务.
+package captcha
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Challenge 是一道验证码题目.
+type Challenge struct {
+	Text   string // 图片上展示的内容
+	Answer string // 正确答案
+}
+
+// Generator 生成验证码题目.
+type Generator interface {
+	Generate() (Challenge, error)
+}
+
+// NewDigitGenerator 创建数字验证码生成器, 答案即图片上的 length 位数字.
+func NewDigitGenerator(length int) Generator {
+	return digitGenerator{length: length}
+}
+
+type digitGenerator struct {
+	length int
+}
+
+func (d digitGenerator) Generate() (Challenge, error) {
+	b := make([]byte, d.length)
+	for i := range b {
+		n, err := randInt(10)
+		if err != nil {
+			return Challenge{}, err
+		}
+		b[i] = byte('0' + n)
+	}
+	return Challenge{Text: string(b), Answer: string(b)}, nil
+}
+
+// NewMathGenerator 创建算术验证码生成器, 题目为 10 以内的加减法, 答案非负.
+func NewMathGenerator() Generator {
+	return mathGenerator{}
+}
+
+type mathGenerator struct{}
+
+func (mathGenerator) Generate() (Challenge, error) {
+	a, err := randInt(10)
+	if err != nil {
+		return Challenge{}, err
+	}
+	b, err := randInt(10)
+	if err != nil {
+		return Challenge{}, err
+	}
+	op, err := randInt(2)
+	if err != nil {
+		return Challenge{}, err
+	}
+	if op == 0 {
+		return Challenge{Text: fmt.Sprintf("%d+%d=?", a, b), Answer: strconv.Itoa(a + b)}, nil
+	}
+	if a < b {
+		a, b = b, a
+	}
+	return Challenge{Text: fmt.Sprintf("%d-%d=?", a, b), Answer: strconv.Itoa(a - b)}, nil
+}
+
+func randInt(max int64) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(max))
+	if err != nil {
+		return 0, err
+	}
+	return int(n.Int64()), nil
+}
@@ -0,0 +1,64 @@
+package captcha
+
+import (
+	"bytes"
+	"image/png"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMathGenerator(t *testing.T) {
+	gen := NewMathGenerator()
+	for i := 0; i < 100; i++ {
+		ch, err := gen.Generate()
+		require.NoError(t, err)
+
+		expr := strings.TrimSuffix(ch.Text, "=?")
+		var a, b, want int
+		if l, r, ok := strings.Cut(expr, "+"); ok {
+			a, _ = strconv.Atoi(l)
+			b, _ = strconv.Atoi(r)
+			want = a + b
+		} else {
+			l, r, _ := strings.Cut(expr, "-")
+			a, _ = strconv.Atoi(l)
+			b, _ = strconv.Atoi(r)
+			want = a - b
+		}
+		assert.Equal(t, strconv.Itoa(want), ch.Answer, ch.Text)
+		assert.GreaterOrEqual(t, want, 0, ch.Text)
+	}
+}
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		name    string
+		text    string
+		width   int
+		height  int
+		wantErr bool
+	}{
+		{name: "数字", text: "0123", width: 120, height: 40},
+		{name: "算术", text: "9-3=?", width: 120, height: 40},
+		{name: "不支持的字符", text: "abcd", width: 120, height: 40, wantErr: true},
+		{name: "图片过小", text: "0123", width: 20, height: 10, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := Render(tc.text, tc.width, tc.height)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			img, err := png.Decode(bytes.NewReader(data))
+			require.NoError(t, err)
+			assert.Equal(t, tc.width, img.Bounds().Dx())
+			assert.Equal(t, tc.height, img.Bounds().Dy())
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package captcha
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand"
+)
+
+// noiseLines 干扰线数量.
+const noiseLines = 4
+
+// Render 将 text 绘制为带干扰的 PNG 图片.
+func Render(text string, width, height int) ([]byte, error) {
+	chars := []rune(text)
+	if len(chars) == 0 {
+		return nil, fmt.Errorf("captcha: empty text")
+	}
+	for _, ch := range chars {
+		if _, ok := glyphs[ch]; !ok {
+			return nil, fmt.Errorf("captcha: unsupported character %q", ch)
+		}
+	}
+
+	// 每个字符占 glyphWidth+1 列, 上下左右各留出 4 像素的抖动空间
+	scale := min((height-8)/glyphHeight, (width-8)/(len(chars)*(glyphWidth+1)))
+	if scale < 1 {
+		return nil, fmt.Errorf("captcha: image %dx%d is too small for %d characters", width, height, len(chars))
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	bg := color.RGBA{R: uint8(230 + rand.Intn(26)), G: uint8(230 + rand.Intn(26)), B: uint8(230 + rand.Intn(26)), A: 255}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, bg)
+		}
+	}
+
+	// 干扰点
+	for i := 0; i < width*height/20; i++ {
+		img.Set(rand.Intn(width), rand.Intn(height), randomColor(120, 200))
+	}
+
+	textWidth := len(chars) * (glyphWidth + 1) * scale
+	offsetX := (width - textWidth) / 2
+	offsetY := (height - glyphHeight*scale) / 2
+	for i, ch := range chars {
+		x := offsetX + i*(glyphWidth+1)*scale + rand.Intn(5) - 2
+		y := offsetY + rand.Intn(9) - 4
+		drawGlyph(img, glyphs[ch], x, y, scale, randomColor(20, 110))
+	}
+
+	// 干扰线
+	for i := 0; i < noiseLines; i++ {
+		drawLine(img, rand.Intn(width), rand.Intn(height), rand.Intn(width), rand.Intn(height), randomColor(60, 160))
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func drawGlyph(img *image.RGBA, glyph [glyphHeight]string, x, y, scale int, c color.Color) {
+	for row, line := range glyph {
+		for col, dot := range line {
+			if dot != '#' {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.Set(x+col*scale+dx, y+row*scale+dy, c)
+				}
+			}
+		}
+	}
+}
+
+// drawLine 使用 Bresenham 算法画线
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func randomColor(low, high int) color.RGBA {
+	n := func() uint8 { return uint8(low + rand.Intn(high-low)) }
+	return color.RGBA{R: n(), G: n(), B: n(), A: 255}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
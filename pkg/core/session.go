@@ -3,17 +3,35 @@ package core
 import (
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/coderlewin/ucenter/internal/constants"
-	"github.com/coderlewin/ucenter/internal/web/vo"
+	"github.com/coderlewin/ucenter/internal/web/dto"
 	"github.com/coderlewin/ucenter/pkg/errno"
 	"github.com/hertz-contrib/sessions"
+	"time"
 )
 
-func SetUserLoginState(c *app.RequestContext, data any) error {
+// cookieOptions 登录态 cookie 的属性, 启动时通过 SetCookieOptions 修改
+var cookieOptions = sessions.Options{
+	Path:     "/",
+	MaxAge:   86400,
+	HttpOnly: true,
+}
+
+// SetCookieOptions 设置登录态 cookie 的属性
+func SetCookieOptions(opts sessions.Options) {
+	cookieOptions = opts
+}
+
+// SessionExpiration 登录态的有效期
+func SessionExpiration() time.Duration {
+	return time.Duration(cookieOptions.MaxAge) * time.Second
+}
+
+func SetUserLoginState(c *app.RequestContext, ssid string, data any) error {
 	session := sessions.Default(c)
-	sessionTTL := 86400
 	session.Set(constants.UserLoginState, data)
+	session.Set(constants.SessionID, ssid)
 	// 设置过期时间
-	session.Options(sessions.Options{MaxAge: sessionTTL})
+	session.Options(cookieOptions)
 	err := session.Save()
 	if err != nil {
 		return err
@@ -25,7 +43,11 @@ func SetUserLoginState(c *app.RequestContext, data any) error {
 func RemoveUserLoginState(c *app.RequestContext) error {
 	session := sessions.Default(c)
 	session.Delete(constants.UserLoginState)
-	session.Options(sessions.Options{MaxAge: -1})
+	session.Delete(constants.SessionID)
+	// 保持 path 和 domain 不变, 浏览器才会删除同一个 cookie
+	opts := cookieOptions
+	opts.MaxAge = -1
+	session.Options(opts)
 	err := session.Save()
 	if err != nil {
 		return err
@@ -33,11 +55,22 @@ func RemoveUserLoginState(c *app.RequestContext) error {
 	return nil
 }
 
-func GetUserLoginState(c *app.RequestContext) (*vo.UserVO, error) {
+func GetUserLoginState(c *app.RequestContext) (*dto.SessionState, error) {
 	session := sessions.Default(c)
 	obj := session.Get(constants.UserLoginState)
 	if obj == nil {
 		return nil, errno.ErrUnauthorization.SetDescription("未登录")
 	}
-	return obj.(*vo.UserVO), nil
+	// 旧版本保存的登录态需要重新登录
+	state, ok := obj.(*dto.SessionState)
+	if !ok {
+		return nil, errno.ErrUnauthorization.SetDescription("登录已过期")
+	}
+	return state, nil
+}
+
+// GetSessionID 返回会话对应的登录会话标识
+func GetSessionID(c *app.RequestContext) string {
+	ssid, _ := sessions.Default(c).Get(constants.SessionID).(string)
+	return ssid
 }
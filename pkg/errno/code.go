@@ -45,4 +45,28 @@ var (
 		Msg:  "实体对象已存在",
 		Desc: "",
 	}
+
+	ErrTooManyAttempts = &Errno{
+		Code: 42900,
+		Msg:  "尝试次数过多, 请稍后重试",
+		Desc: "",
+	}
+
+	ErrTooManyRequests = &Errno{
+		Code: 42901,
+		Msg:  "请求过于频繁, 请稍后重试",
+		Desc: "",
+	}
+
+	ErrCaptchaInvalid = &Errno{
+		Code: 40003,
+		Msg:  "图形验证码错误",
+		Desc: "",
+	}
+
+	ErrEmailUnverified = &Errno{
+		Code: 40301,
+		Msg:  "邮箱未验证",
+		Desc: "",
+	}
 )
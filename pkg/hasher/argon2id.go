@@ -0,0 +1,117 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const argon2idPrefix = "$argon2id$"
+
+// Argon2idOptions 定义 argon2id 算法的参数.
+type Argon2idOptions struct {
+	Memory      uint32 // 内存开销, 单位 KiB
+	Iterations  uint32 // 迭代次数
+	Parallelism uint8  // 并行度
+	SaltLength  uint32 // 盐长度, 单位字节
+	KeyLength   uint32 // 派生密钥长度, 单位字节
+}
+
+// DefaultArgon2idOptions 返回 OWASP 推荐的 argon2id 参数.
+func DefaultArgon2idOptions() Argon2idOptions {
+	return Argon2idOptions{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// NewArgon2idScheme 创建 argon2id 算法, 生成 PHC 格式的哈希:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func NewArgon2idScheme(opts Argon2idOptions) Scheme {
+	return &argon2idScheme{opts: opts}
+}
+
+type argon2idScheme struct {
+	opts Argon2idOptions
+}
+
+func (s *argon2idScheme) Name() string {
+	return "argon2id"
+}
+
+func (s *argon2idScheme) Hash(plain string) (string, error) {
+	salt := make([]byte, s.opts.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(plain), salt, s.opts.Iterations, s.opts.Memory, s.opts.Parallelism, s.opts.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version,
+		s.opts.Memory, s.opts.Iterations, s.opts.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (s *argon2idScheme) Verify(plain, encoded string) (bool, error) {
+	opts, salt, key, err := s.decode(encoded)
+	if err != nil {
+		return false, err
+	}
+	actual := argon2.IDKey([]byte(plain), salt, opts.Iterations, opts.Memory, opts.Parallelism, opts.KeyLength)
+	return subtle.ConstantTimeCompare(key, actual) == 1, nil
+}
+
+func (s *argon2idScheme) Identify(encoded string) bool {
+	return strings.HasPrefix(encoded, argon2idPrefix)
+}
+
+func (s *argon2idScheme) NeedsRehash(encoded string) bool {
+	opts, salt, _, err := s.decode(encoded)
+	if err != nil {
+		return true
+	}
+	return opts.Memory != s.opts.Memory ||
+		opts.Iterations != s.opts.Iterations ||
+		opts.Parallelism != s.opts.Parallelism ||
+		opts.KeyLength != s.opts.KeyLength ||
+		uint32(len(salt)) != s.opts.SaltLength
+}
+
+// decode 解析 PHC 格式的哈希, 返回其中的参数、盐和派生密钥.
+func (s *argon2idScheme) decode(encoded string) (Argon2idOptions, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return Argon2idOptions{}, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2idOptions{}, nil, nil, ErrInvalidHash
+	}
+
+	var opts Argon2idOptions
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &opts.Memory, &opts.Iterations, &opts.Parallelism); err != nil {
+		return Argon2idOptions{}, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2idOptions{}, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Argon2idOptions{}, nil, nil, ErrInvalidHash
+	}
+	opts.SaltLength = uint32(len(salt))
+	opts.KeyLength = uint32(len(key))
+	return opts, salt, key, nil
+}
@@ -0,0 +1,61 @@
+package hasher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptMaxPasswordBytes bcrypt 只接受不超过 72 字节的密码
+const BcryptMaxPasswordBytes = 72
+
+// NewBcryptScheme 创建 bcrypt 算法, cost 小于 bcrypt.MinCost 时使用默认值.
+func NewBcryptScheme(cost int) Scheme {
+	if cost < bcrypt.MinCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &bcryptScheme{cost: cost}
+}
+
+type bcryptScheme struct {
+	cost int
+}
+
+func (s *bcryptScheme) Name() string {
+	return "bcrypt"
+}
+
+func (s *bcryptScheme) Hash(plain string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), s.cost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", ErrPasswordTooLong
+	}
+	return string(hash), err
+}
+
+func (s *bcryptScheme) Verify(plain, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(plain))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+	return true, nil
+}
+
+func (s *bcryptScheme) Identify(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+func (s *bcryptScheme) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return true
+	}
+	return cost != s.cost
+}
@@ -0,0 +1,84 @@
+package hasher
+
+import (
+	"errors"
+)
+
+var (
+	// ErrUnknownScheme 表示存储的密码哈希无法被任何已注册的算法识别.
+	ErrUnknownScheme = errors.New("hasher: unknown password hash scheme")
+	// ErrInvalidHash 表示存储的密码哈希格式错误, 无法解析.
+	ErrInvalidHash = errors.New("hasher: invalid password hash")
+	// ErrPasswordTooLong 表示明文密码超出算法支持的长度, 如 bcrypt 最多 72 字节.
+	ErrPasswordTooLong = errors.New("hasher: password too long")
+)
+
+// Scheme 定义单一的密码哈希算法.
+// 算法生成的哈希需要是自描述的（包含算法标识与参数），以便后续校验和升级.
+type Scheme interface {
+	// Name 返回算法名称, 如 argon2id、bcrypt.
+	Name() string
+	// Hash 对明文密码进行哈希.
+	Hash(plain string) (string, error)
+	// Verify 校验明文密码与哈希是否匹配.
+	Verify(plain, encoded string) (bool, error)
+	// Identify 判断该哈希是否由本算法生成.
+	Identify(encoded string) bool
+	// NeedsRehash 判断该哈希的参数是否已落后于当前配置.
+	NeedsRehash(encoded string) bool
+}
+
+// PasswordHasher 对业务层屏蔽具体的密码哈希算法.
+// 新密码统一使用主算法生成, 校验时根据哈希前缀自动选择对应的算法.
+type PasswordHasher interface {
+	Hash(plain string) (string, error)
+	Verify(plain, encoded string) (bool, error)
+	// NeedsRehash 判断该哈希是否需要使用主算法重新生成.
+	NeedsRehash(encoded string) bool
+}
+
+// NewPasswordHasher 创建 PasswordHasher, primary 用于生成新的哈希,
+// legacy 仅用于校验历史数据.
+func NewPasswordHasher(primary Scheme, legacy ...Scheme) PasswordHasher {
+	return &passwordHasher{
+		primary: primary,
+		schemes: append([]Scheme{primary}, legacy...),
+	}
+}
+
+type passwordHasher struct {
+	primary Scheme
+	schemes []Scheme
+}
+
+func (h *passwordHasher) Hash(plain string) (string, error) {
+	return h.primary.Hash(plain)
+}
+
+func (h *passwordHasher) Verify(plain, encoded string) (bool, error) {
+	scheme, ok := h.identify(encoded)
+	if !ok {
+		return false, ErrUnknownScheme
+	}
+	return scheme.Verify(plain, encoded)
+}
+
+func (h *passwordHasher) NeedsRehash(encoded string) bool {
+	scheme, ok := h.identify(encoded)
+	if !ok {
+		return true
+	}
+	if scheme.Name() != h.primary.Name() {
+		return true
+	}
+	return scheme.NeedsRehash(encoded)
+}
+
+func (h *passwordHasher) identify(encoded string) (Scheme, bool) {
+	for _, scheme := range h.schemes {
+		if scheme.Identify(encoded) {
+			return scheme, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,80 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordHasher(t *testing.T) {
+	fastArgon2id := Argon2idOptions{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	testCases := []struct {
+		name    string
+		primary Scheme
+		// 期望的哈希前缀
+		wantPrefix string
+	}{
+		{
+			name:       "argon2id",
+			primary:    NewArgon2idScheme(fastArgon2id),
+			wantPrefix: "$argon2id$v=19$m=1024,t=1,p=1$",
+		},
+		{
+			name:       "bcrypt",
+			primary:    NewBcryptScheme(4),
+			wantPrefix: "$2a$04$",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewPasswordHasher(tc.primary, NewLegacyMD5Scheme("coderlu"))
+			encoded, err := h.Hash("12345678")
+			require.NoError(t, err)
+			assert.Contains(t, encoded, tc.wantPrefix)
+
+			ok, err := h.Verify("12345678", encoded)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = h.Verify("87654321", encoded)
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			assert.False(t, h.NeedsRehash(encoded))
+		})
+	}
+}
+
+func TestPasswordHasher_Legacy(t *testing.T) {
+	h := NewPasswordHasher(NewBcryptScheme(4), NewArgon2idScheme(DefaultArgon2idOptions()), NewLegacyMD5Scheme("coderlu"))
+
+	// 历史 MD5 密码可以校验, 但需要升级
+	ok, err := h.Verify("12345678", "9825417a996f1b031543e79ab88ec7ea")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, h.NeedsRehash("9825417a996f1b031543e79ab88ec7ea"))
+
+	// 参数变化后需要升级
+	old, err := NewBcryptScheme(5).Hash("12345678")
+	require.NoError(t, err)
+	assert.True(t, h.NeedsRehash(old))
+
+	_, err = h.Verify("12345678", "plaintext")
+	assert.Equal(t, ErrUnknownScheme, err)
+}
+
+func TestPasswordHasher_Errors(t *testing.T) {
+	h := NewPasswordHasher(NewBcryptScheme(4), NewArgon2idScheme(DefaultArgon2idOptions()))
+
+	// bcrypt 最多支持 72 字节, 多字节字符按字节计算
+	_, err := h.Hash(strings.Repeat("密", 25))
+	assert.ErrorIs(t, err, ErrPasswordTooLong)
+
+	_, err = h.Verify("12345678", "$argon2id$v=19$m=1024,t=1,p=1$!!!$!!!")
+	assert.ErrorIs(t, err, ErrInvalidHash)
+
+	_, err = h.Verify("12345678", "$2a$04$short")
+	assert.ErrorIs(t, err, ErrInvalidHash)
+}
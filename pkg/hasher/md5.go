@@ -0,0 +1,41 @@
+package hasher
+
+import (
+	"crypto/subtle"
+	"regexp"
+
+	"github.com/duke-git/lancet/v2/cryptor"
+)
+
+var md5HexPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// NewLegacyMD5Scheme 创建历史遗留的 MD5 加盐算法, 仅用于校验旧数据, 不应作为主算法使用.
+// 旧数据为不带任何前缀的 32 位十六进制字符串.
+func NewLegacyMD5Scheme(salt string) Scheme {
+	return &legacyMD5Scheme{salt: salt}
+}
+
+type legacyMD5Scheme struct {
+	salt string
+}
+
+func (s *legacyMD5Scheme) Name() string {
+	return "md5"
+}
+
+func (s *legacyMD5Scheme) Hash(plain string) (string, error) {
+	return cryptor.Md5String(s.salt + plain), nil
+}
+
+func (s *legacyMD5Scheme) Verify(plain, encoded string) (bool, error) {
+	actual := cryptor.Md5String(s.salt + plain)
+	return subtle.ConstantTimeCompare([]byte(actual), []byte(encoded)) == 1, nil
+}
+
+func (s *legacyMD5Scheme) Identify(encoded string) bool {
+	return md5HexPattern.MatchString(encoded)
+}
+
+func (s *legacyMD5Scheme) NeedsRehash(encoded string) bool {
+	return false
+}
@@ -0,0 +1,103 @@
+// Package identicon 根据种子生成确定的默认头像, 相同的种子总是得到相同的图片.
+package identicon
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// grid 图案由 grid x grid 个格子组成, 左右对称.
+const grid = 5
+
+// background 格子以外的背景色.
+var background = color.RGBA{R: 240, G: 240, B: 240, A: 255}
+
+// Render 生成 size x size 的 PNG 图案头像, 颜色和图案都由 seed 的哈希决定.
+func Render(seed string, size int) ([]byte, error) {
+	if size < grid {
+		return nil, fmt.Errorf("identicon: size %d is too small", size)
+	}
+	sum := sha256.Sum256([]byte(seed))
+	fg := Color(seed)
+
+	// 四周留出一个格子一半的边距, 剩余的像素平分到两侧
+	cell := size * 2 / (grid*2 + 1)
+	pad := (size - cell*grid) / 2
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = background.R, background.G, background.B, background.A
+	}
+	for row := 0; row < grid; row++ {
+		for col := 0; col < (grid+1)/2; col++ {
+			// 每个格子取哈希中一个字节的最低位
+			if sum[3+row*3+col]&1 == 0 {
+				continue
+			}
+			fillCell(img, pad, cell, row, col, fg)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fillCell 填充左半边的格子, 并按像素镜像到右半边, 边距不能平分时图案仍然严格对称
+func fillCell(img *image.RGBA, pad, cell, row, col int, c color.RGBA) {
+	size := img.Bounds().Dx()
+	for y := pad + row*cell; y < pad+(row+1)*cell; y++ {
+		for x := pad + col*cell; x < pad+(col+1)*cell; x++ {
+			img.SetRGBA(x, y, c)
+			img.SetRGBA(size-1-x, y, c)
+		}
+	}
+}
+
+// Color 返回由 seed 决定的前景色, 饱和度和亮度固定在适中的范围内, 保证与白色文字和浅色背景都有对比.
+func Color(seed string) color.RGBA {
+	sum := sha256.Sum256([]byte(seed))
+	hue := float64(int(sum[0])<<8|int(sum[1])) / 65536 * 360
+	return hslToRGB(hue, 0.55, 0.5)
+}
+
+// hslToRGB 将色相 h(0-360)、饱和度 s 和亮度 l(0-1) 转换为 RGB.
+func hslToRGB(h, s, l float64) color.RGBA {
+	c := (1 - abs(2*l-1)) * s
+	hp := h / 60
+	x := c * (1 - abs(mod2(hp)-1))
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g, b = c, x, 0
+	case hp < 2:
+		r, g, b = x, c, 0
+	case hp < 3:
+		r, g, b = 0, c, x
+	case hp < 4:
+		r, g, b = 0, x, c
+	case hp < 5:
+		r, g, b = x, 0, c
+	default:
+		r, g, b = c, 0, x
+	}
+	m := l - c/2
+	return color.RGBA{R: uint8((r + m) * 255), G: uint8((g + m) * 255), B: uint8((b + m) * 255), A: 255}
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// mod2 返回 v 除以 2 的余数, v 不小于 0.
+func mod2(v float64) float64 {
+	return v - float64(int(v/2)*2)
+}
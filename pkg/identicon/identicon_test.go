@@ -0,0 +1,59 @@
+package identicon
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	a, err := Render("coderlu", 120)
+	require.NoError(t, err)
+	b, err := Render("coderlu", 120)
+	require.NoError(t, err)
+	c, err := Render("another", 120)
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+
+	img, err := png.Decode(bytes.NewReader(a))
+	require.NoError(t, err)
+	assert.Equal(t, 120, img.Bounds().Dx())
+	// 左右对称
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 60; x++ {
+			require.Equal(t, img.At(x, y), img.At(119-x, y))
+		}
+	}
+
+	_, err = Render("coderlu", 4)
+	assert.Error(t, err)
+}
+
+func TestInitials(t *testing.T) {
+	testCases := []struct {
+		text string
+		want string
+	}{
+		{text: "coderlu", want: "CO"},
+		{text: " a.b ", want: "AB"},
+		{text: "用户1234", want: "用"},
+		{text: "x用户", want: "X"},
+		{text: "...", want: "?"},
+		{text: "<script>", want: "SC"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			assert.Equal(t, tc.want, InitialsOf(tc.text))
+		})
+	}
+
+	svg := string(Initials("coderlu", "coderlu", 64))
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.Contains(t, svg, ">CO</text>")
+	assert.Equal(t, svg, string(Initials("coderlu", "coderlu", 64)))
+}
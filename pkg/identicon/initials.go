@@ -0,0 +1,49 @@
+package identicon
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Initials 生成 size x size 的 SVG 首字母头像, 背景色由 seed 决定.
+// text 中只取前两个字母或数字, 中文等字符只取第一个.
+func Initials(text, seed string, size int) []byte {
+	text = InitialsOf(text)
+	bg := Color(seed)
+	fontSize := size * 2 / 5
+	if len([]rune(text)) > 1 {
+		fontSize = size / 3
+	}
+	svg := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+
+		`<rect width="100%%" height="100%%" fill="#%02x%02x%02x"/>`+
+		`<text x="50%%" y="50%%" dy=".35em" text-anchor="middle" fill="#ffffff" `+
+		`font-family="-apple-system, 'Segoe UI', 'PingFang SC', 'Microsoft YaHei', sans-serif" font-size="%d">%s</text>`+
+		`</svg>`, size, size, size, size, bg.R, bg.G, bg.B, fontSize, html.EscapeString(text))
+	return []byte(svg)
+}
+
+// InitialsOf 取出显示的首字母, 没有可显示的字符时返回 ?
+func InitialsOf(text string) string {
+	var res []rune
+	for _, r := range strings.TrimSpace(text) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+		if r > unicode.MaxASCII {
+			if len(res) == 0 {
+				return string(r)
+			}
+			break
+		}
+		res = append(res, unicode.ToUpper(r))
+		if len(res) == 2 {
+			break
+		}
+	}
+	if len(res) == 0 {
+		return "?"
+	}
+	return string(res)
+}
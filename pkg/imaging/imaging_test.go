@@ -0,0 +1,96 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	got, format, err := Decode(buf.Bytes(), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, FormatPNG, format)
+	assert.Equal(t, image.Rect(0, 0, 40, 20), got.Bounds())
+
+	_, _, err = Decode(buf.Bytes(), 799)
+	assert.ErrorIs(t, err, ErrTooLarge)
+	_, _, err = Decode([]byte("<svg></svg>"), 1000)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestCropSquareAndResize(t *testing.T) {
+	// 左半边红色, 右半边蓝色, 中间 20x20 的区域各占一半
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 20 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	square := CropSquare(img)
+	assert.Equal(t, image.Rect(0, 0, 20, 20), square.Bounds())
+
+	small := Resize(square, 2, 2)
+	assert.Equal(t, color.RGBA{R: 255, A: 255}, small.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, small.RGBAAt(1, 1))
+
+	// 缩放到 1 列时取两种颜色的平均值
+	avg := Resize(square, 1, 1)
+	assert.Equal(t, color.RGBA{R: 127, B: 127, A: 255}, avg.RGBAAt(0, 0))
+
+	large := Resize(square, 40, 40)
+	assert.Equal(t, image.Rect(0, 0, 40, 40), large.Bounds())
+	assert.Equal(t, color.RGBA{B: 255, A: 255}, large.RGBAAt(39, 0))
+}
+
+func TestDecodeOrientation(t *testing.T) {
+	// 宽 30 高 10, 顺时针旋转 90 度后宽 10 高 30
+	img := image.NewRGBA(image.Rect(0, 0, 30, 10))
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	data := withOrientation(buf.Bytes(), 6)
+
+	got, format, err := Decode(data, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, FormatJPEG, format)
+	assert.Equal(t, image.Rect(0, 0, 10, 30), got.Bounds())
+
+	// 重新编码后不再包含 EXIF
+	out, err := Encode(got, format)
+	require.NoError(t, err)
+	assert.Equal(t, 1, jpegOrientation(out))
+	assert.False(t, bytes.Contains(out, []byte("Exif")))
+}
+
+// withOrientation 在 SOI 之后插入只包含方向标签的 EXIF 段
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry[0:], 0x0112)
+	binary.BigEndian.PutUint16(entry[2:], 3)
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	tiff = append(append(tiff, entry...), 0, 0, 0, 0)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+	res := append([]byte{}, data[:2]...)
+	res = append(res, segment...)
+	return append(res, data[2:]...)
+}
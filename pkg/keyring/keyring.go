@@ -0,0 +1,224 @@
+package keyring
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrNoSigningKey 表示当前没有处于有效期内的签名密钥.
+	ErrNoSigningKey = errors.New("keyring: no active signing key")
+	// ErrKeyNotFound 表示 kid 对应的密钥不存在或已过期.
+	ErrKeyNotFound = errors.New("keyring: key not found")
+)
+
+// 支持的签名算法.
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// Key 定义密钥环中的一把签名密钥.
+type Key struct {
+	ID        string        // 密钥标识, 写入 JWT 头部的 kid
+	Algorithm string        // 签名算法, RS256 或 EdDSA
+	Private   crypto.Signer // 私钥, 用于签名
+	NotBefore time.Time     // 开始用于签名的时间, 零值表示立即生效
+	ExpiresAt time.Time     // 不再用于校验的时间, 零值表示永不过期
+}
+
+// Public 返回该密钥的公钥.
+func (k Key) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+// SigningMethod 返回该密钥对应的 JWT 签名算法.
+func (k Key) SigningMethod() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+func (k Key) activeAt(now time.Time) bool {
+	return !now.Before(k.NotBefore) && !k.expiredAt(now)
+}
+
+func (k Key) expiredAt(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+// KeyRing 管理用于签发和校验 JWT 的非对称密钥.
+// 轮换时先加入一把 NotBefore 在未来的新密钥, 使其提前出现在 JWKS 中,
+// 到期后自动切换为签名密钥; 旧密钥在 ExpiresAt 之前仍可用于校验已签发的 token.
+type KeyRing struct {
+	keys []Key
+	now  func() time.Time
+}
+
+// New 创建密钥环, keys 不能为空且 kid 不能重复.
+func New(keys ...Key) (*KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("keyring: at least one key is required")
+	}
+	seen := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		if k.ID == "" {
+			return nil, errors.New("keyring: key id is required")
+		}
+		if _, ok := seen[k.ID]; ok {
+			return nil, fmt.Errorf("keyring: duplicate key id %s", k.ID)
+		}
+		seen[k.ID] = struct{}{}
+		if err := checkAlgorithm(k.Algorithm, k.Private); err != nil {
+			return nil, fmt.Errorf("keyring: key %s: %w", k.ID, err)
+		}
+	}
+
+	sorted := make([]Key, len(keys))
+	copy(sorted, keys)
+	// 按生效时间从新到旧排序, 便于选出最新的签名密钥
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].NotBefore.After(sorted[j].NotBefore)
+	})
+	return &KeyRing{keys: sorted, now: time.Now}, nil
+}
+
+// SigningKey 返回当前用于签名的密钥, 即已生效且未过期的密钥中最新的一把.
+func (r *KeyRing) SigningKey() (Key, error) {
+	now := r.now()
+	for _, k := range r.keys {
+		if k.activeAt(now) {
+			return k, nil
+		}
+	}
+	return Key{}, ErrNoSigningKey
+}
+
+// VerificationKey 根据 kid 返回用于校验签名的密钥.
+func (r *KeyRing) VerificationKey(kid string) (Key, error) {
+	now := r.now()
+	for _, k := range r.keys {
+		if k.ID == kid && !k.expiredAt(now) {
+			return k, nil
+		}
+	}
+	return Key{}, ErrKeyNotFound
+}
+
+// PublicKeys 返回所有未过期的密钥, 包括尚未开始签名的新密钥.
+func (r *KeyRing) PublicKeys() []Key {
+	now := r.now()
+	res := make([]Key, 0, len(r.keys))
+	for _, k := range r.keys {
+		if !k.expiredAt(now) {
+			res = append(res, k)
+		}
+	}
+	return res
+}
+
+// Sign 使用当前的签名密钥签发 JWT, 头部携带 kid 以便校验方选择公钥, typ 用于区分不同用途的 token.
+func (r *KeyRing) Sign(typ string, claims jwt.Claims) (string, error) {
+	key, err := r.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.SigningMethod(), claims)
+	token.Header["kid"] = key.ID
+	token.Header["typ"] = typ
+	return token.SignedString(key.Private)
+}
+
+// Parse 根据头部的 kid 选择公钥校验 Sign 签发的 JWT, 并校验 token 类型.
+func (r *KeyRing) Parse(tokenString string, typ string, claims jwt.Claims, opts ...jwt.ParserOption) error {
+	opts = append(opts, jwt.WithValidMethods([]string{AlgRS256, AlgEdDSA}))
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if t, _ := token.Header["typ"].(string); t != typ {
+			return nil, fmt.Errorf("unexpected token type %s", t)
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, err := r.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return key.Public(), nil
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return jwt.ErrTokenSignatureInvalid
+	}
+	return nil
+}
+
+// ParsePrivateKey 解析 PEM 格式的私钥, 支持 PKCS#8 以及 PKCS#1 格式的 RSA 私钥.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("keyring: invalid PEM data")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("keyring: unsupported private key type")
+	}
+	return signer, nil
+}
+
+// GenerateKey 生成一把新的密钥, 用于未配置密钥时的本地开发.
+func GenerateKey(id, alg string) (Key, error) {
+	var (
+		signer crypto.Signer
+		err    error
+	)
+	switch alg {
+	case AlgRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		err = fmt.Errorf("keyring: unsupported algorithm %s", alg)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{ID: id, Algorithm: alg, Private: signer}, nil
+}
+
+// checkAlgorithm 校验私钥类型与签名算法是否匹配.
+func checkAlgorithm(alg string, signer crypto.Signer) error {
+	if signer == nil {
+		return errors.New("private key is required")
+	}
+	switch alg {
+	case AlgRS256:
+		if _, ok := signer.(*rsa.PrivateKey); !ok {
+			return errors.New("RS256 requires an RSA private key")
+		}
+	case AlgEdDSA:
+		if _, ok := signer.Public().(ed25519.PublicKey); !ok {
+			return errors.New("EdDSA requires an Ed25519 private key")
+		}
+	default:
+		return fmt.Errorf("unsupported algorithm %s", alg)
+	}
+	return nil
+}
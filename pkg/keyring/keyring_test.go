@@ -0,0 +1,111 @@
+package keyring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRing_Rotation(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	oldKey, err := GenerateKey("old", AlgEdDSA)
+	require.NoError(t, err)
+	oldKey.ExpiresAt = now.Add(48 * time.Hour)
+
+	newKey, err := GenerateKey("new", AlgRS256)
+	require.NoError(t, err)
+	newKey.NotBefore = now.Add(24 * time.Hour)
+
+	ring, err := New(oldKey, newKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		now  time.Time
+		// 预期的签名密钥
+		wantSigning string
+		// 预期 JWKS 中公开的密钥
+		wantPublished []string
+	}{
+		{
+			name:          "新密钥提前公开",
+			now:           now,
+			wantSigning:   "old",
+			wantPublished: []string{"new", "old"},
+		},
+		{
+			name:          "新密钥生效后旧密钥仍可校验",
+			now:           now.Add(36 * time.Hour),
+			wantSigning:   "new",
+			wantPublished: []string{"new", "old"},
+		},
+		{
+			name:          "旧密钥过期",
+			now:           now.Add(72 * time.Hour),
+			wantSigning:   "new",
+			wantPublished: []string{"new"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ring.now = func() time.Time { return tc.now }
+
+			key, err := ring.SigningKey()
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantSigning, key.ID)
+
+			var published []string
+			for _, jwk := range ring.JWKS().Keys {
+				published = append(published, jwk.Kid)
+				_, err = ring.VerificationKey(jwk.Kid)
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantPublished, published)
+		})
+	}
+
+	ring.now = func() time.Time { return now.Add(72 * time.Hour) }
+	_, err = ring.VerificationKey("old")
+	assert.Equal(t, ErrKeyNotFound, err)
+}
+
+func TestNew_AlgorithmMismatch(t *testing.T) {
+	key, err := GenerateKey("k1", AlgEdDSA)
+	require.NoError(t, err)
+	key.Algorithm = AlgRS256
+	_, err = New(key)
+	assert.Error(t, err)
+}
+
+func TestKeyRing_SignParse(t *testing.T) {
+	key, err := GenerateKey("k1", AlgEdDSA)
+	require.NoError(t, err)
+	ring, err := New(key)
+	require.NoError(t, err)
+	other, err := GenerateKey("k1", AlgEdDSA)
+	require.NoError(t, err)
+	otherRing, err := New(other)
+	require.NoError(t, err)
+
+	token, err := ring.Sign("ev+jwt", jwt.RegisteredClaims{Subject: "1", Audience: jwt.ClaimStrings{"aud"}})
+	require.NoError(t, err)
+
+	var claims jwt.RegisteredClaims
+	require.NoError(t, ring.Parse(token, "ev+jwt", &claims, jwt.WithAudience("aud")))
+	assert.Equal(t, "1", claims.Subject)
+
+	// 类型不同的 token 不能混用
+	assert.Error(t, ring.Parse(token, "at+jwt", &jwt.RegisteredClaims{}))
+	// aud 不匹配
+	assert.Error(t, ring.Parse(token, "ev+jwt", &jwt.RegisteredClaims{}, jwt.WithAudience("other")))
+	// kid 相同但密钥不同
+	assert.Error(t, otherRing.Parse(token, "ev+jwt", &jwt.RegisteredClaims{}))
+	// 不接受 HS256 签名的 token
+	hs, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "1"}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	assert.Error(t, ring.Parse(hs, "JWT", &jwt.RegisteredClaims{}))
+}
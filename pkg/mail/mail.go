@@ -0,0 +1,17 @@
+// Package mail 定义发送邮件的接口, 提供 SMTP 和内存两种实现.
+package mail
+
+import "context"
+
+// Message 是一封待发送的邮件.
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+	HTML    bool // Body 是否为 HTML
+}
+
+// Mailer 发送邮件.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
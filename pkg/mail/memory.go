@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryMailer 只把邮件保存在内存中, 用于开发和测试.
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemoryMailer 创建内存邮件发送器.
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(_ context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages 返回已发送的全部邮件.
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := make([]Message, len(m.messages))
+	copy(res, m.messages)
+	return res
+}
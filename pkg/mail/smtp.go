@@ -0,0 +1,163 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP 连接的加密方式.
+const (
+	TLSModeNone     = "none"     // 不加密
+	TLSModeStartTLS = "starttls" // 明文连接后升级, 通常是 587 端口
+	TLSModeTLS      = "tls"      // 直接建立 TLS 连接, 通常是 465 端口
+)
+
+// SMTPOptions 定义 SMTP 服务器的连接参数.
+type SMTPOptions struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string // 发件人, 如 UCenter <noreply@example.com>
+	TLSMode  string
+	Timeout  time.Duration
+}
+
+// NewSMTPMailer 创建 SMTP 邮件发送器, 每次发送建立新的连接.
+func NewSMTPMailer(opts SMTPOptions) (Mailer, error) {
+	if _, err := mail.ParseAddress(opts.From); err != nil {
+		return nil, fmt.Errorf("mail: invalid from address %q: %w", opts.From, err)
+	}
+	switch opts.TLSMode {
+	case TLSModeNone, TLSModeStartTLS, TLSModeTLS:
+	default:
+		return nil, fmt.Errorf("mail: unsupported tls mode %q", opts.TLSMode)
+	}
+	return &smtpMailer{opts: opts}, nil
+}
+
+type smtpMailer struct {
+	opts SMTPOptions
+}
+
+func (s *smtpMailer) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("mail: no recipients")
+	}
+	from, _ := mail.ParseAddress(s.opts.From)
+	data, err := buildMessage(s.opts.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if s.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.opts.Timeout)
+		defer cancel()
+	}
+	addr := net.JoinHostPort(s.opts.Host, strconv.Itoa(s.opts.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	tlsConfig := &tls.Config{ServerName: s.opts.Host}
+	if s.opts.TLSMode == TLSModeTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, s.opts.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if s.opts.TLSMode == TLSModeStartTLS {
+		if err = client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if s.opts.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", s.opts.Username, s.opts.Password, s.opts.Host)); err != nil {
+			return err
+		}
+	}
+	if err = client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range msg.To {
+		if err = client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMessage 按 RFC 5322 生成邮件内容, 正文使用 base64 编码以支持中文.
+func buildMessage(from string, msg Message, now time.Time) ([]byte, error) {
+	for _, addr := range append([]string{from}, msg.To...) {
+		if strings.ContainsAny(addr, "\r\n") {
+			return nil, fmt.Errorf("mail: invalid address %q", addr)
+		}
+	}
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return nil, err
+	}
+	domain := fromAddr.Address[strings.LastIndex(fromAddr.Address, "@")+1:]
+
+	contentType := "text/plain"
+	if msg.HTML {
+		contentType = "text/html"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("From: " + fromAddr.String() + "\r\n")
+	buf.WriteString("To: " + strings.Join(msg.To, ", ") + "\r\n")
+	buf.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Subject) + "\r\n")
+	buf.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("Message-ID: <" + hex.EncodeToString(id) + "@" + domain + ">\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: " + contentType + "; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+	buf.WriteString("\r\n")
+
+	// 每行不超过 76 个字符
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes(), nil
+}
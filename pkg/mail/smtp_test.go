@@ -0,0 +1,66 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMessage(t *testing.T) {
+	testCases := []struct {
+		name     string
+		msg      Message
+		wantType string
+		wantErr  bool
+	}{
+		{
+			name:     "纯文本",
+			msg:      Message{To: []string{"a@example.com", "b@example.com"}, Subject: "重置密码", Body: strings.Repeat("你好", 50)},
+			wantType: "text/plain; charset=UTF-8",
+		},
+		{
+			name:     "HTML",
+			msg:      Message{To: []string{"a@example.com"}, Subject: "hello", Body: "<p>hi</p>", HTML: true},
+			wantType: "text/html; charset=UTF-8",
+		},
+		{
+			name:    "收件人包含换行",
+			msg:     Message{To: []string{"a@example.com\r\nBcc: c@example.com"}, Subject: "hello", Body: "hi"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := buildMessage("UCenter <noreply@example.com>", tc.msg, time.Now())
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			parsed, err := mail.ReadMessage(bytes.NewReader(data))
+			require.NoError(t, err)
+			subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+			require.NoError(t, err)
+			assert.Equal(t, tc.msg.Subject, subject)
+			assert.Equal(t, tc.wantType, parsed.Header.Get("Content-Type"))
+			to, err := parsed.Header.AddressList("To")
+			require.NoError(t, err)
+			assert.Len(t, to, len(tc.msg.To))
+
+			body, err := io.ReadAll(parsed.Body)
+			require.NoError(t, err)
+			decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(body), "\r\n", ""))
+			require.NoError(t, err)
+			assert.Equal(t, tc.msg.Body, string(decoded))
+		})
+	}
+}
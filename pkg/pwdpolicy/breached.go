@@ -0,0 +1,69 @@
+package pwdpolicy
+
+import (
+	"bufio"
+	"hash/fnv"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// BreachedList 已泄露的密码集合.
+// 只保存密码的 64 位哈希并排序, 百万条约占 8MB 内存, 误判的概率可以忽略.
+type BreachedList struct {
+	hashes []uint64
+}
+
+// LoadBreachedList 从 r 中按行读取密码, 忽略空行和以 # 开头的注释
+func LoadBreachedList(r io.Reader) (*BreachedList, error) {
+	var hashes []uint64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hashes = append(hashes, hashPassword(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	// 去重
+	n := 0
+	for i, h := range hashes {
+		if i == 0 || h != hashes[n-1] {
+			hashes[n] = h
+			n++
+		}
+	}
+	return &BreachedList{hashes: hashes[:n:n]}, nil
+}
+
+// LoadBreachedFile 从本地文件加载已泄露的密码
+func LoadBreachedFile(path string) (*BreachedList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadBreachedList(f)
+}
+
+func (b *BreachedList) Contains(password string) bool {
+	h := hashPassword(password)
+	i := sort.Search(len(b.hashes), func(i int) bool { return b.hashes[i] >= h })
+	return i < len(b.hashes) && b.hashes[i] == h
+}
+
+func (b *BreachedList) Len() int {
+	return len(b.hashes)
+}
+
+func hashPassword(password string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(password))
+	return h.Sum64()
+}
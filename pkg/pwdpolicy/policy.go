@@ -0,0 +1,118 @@
+package pwdpolicy
+
+import (
+	"fmt"
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 字符类型
+const (
+	ClassLower  = "lower"
+	ClassUpper  = "upper"
+	ClassDigit  = "digit"
+	ClassSymbol = "symbol"
+)
+
+var classNames = map[string]string{
+	ClassLower:  "小写字母",
+	ClassUpper:  "大写字母",
+	ClassDigit:  "数字",
+	ClassSymbol: "特殊字符",
+}
+
+type Options struct {
+	MinLength int
+	// MaxLength 按 UTF-8 编码的字节数计算, 与 bcrypt 等哈希算法的输入限制一致, 为 0 时不限制
+	MaxLength int
+	// RequiredClasses 密码必须包含的字符类型
+	RequiredClasses []string
+	// DisallowAccount 密码中不能包含账号, 忽略大小写
+	DisallowAccount bool
+	// MinStrength 最低强度, 取值 0-4, 见 Strength
+	MinStrength int
+	// Breached 已泄露的密码, 为 nil 时不检查
+	Breached *BreachedList
+}
+
+// Policy 密码策略, 只校验密码本身, 历史密码由调用方检查
+type Policy struct {
+	opts Options
+}
+
+func New(opts Options) (*Policy, error) {
+	if opts.MinLength < 1 {
+		return nil, fmt.Errorf("pwdpolicy: 最小长度不能小于 1")
+	}
+	if opts.MaxLength > 0 && opts.MaxLength < opts.MinLength {
+		return nil, fmt.Errorf("pwdpolicy: 最大长度不能小于最小长度")
+	}
+	for _, class := range opts.RequiredClasses {
+		if _, ok := classNames[class]; !ok {
+			return nil, fmt.Errorf("pwdpolicy: 不支持的字符类型 %s", class)
+		}
+	}
+	if opts.MinStrength < 0 || opts.MinStrength > MaxStrength {
+		return nil, fmt.Errorf("pwdpolicy: 最低强度必须在 0-%d 之间", MaxStrength)
+	}
+	return &Policy{opts: opts}, nil
+}
+
+// Validate 校验密码是否符合策略, 不符合时返回带有原因的 errno.ErrParameterInvalid
+func (p *Policy) Validate(password, account string) error {
+	if reason := p.Check(password, account); reason != "" {
+		return errno.ErrParameterInvalid.SetDescription(reason)
+	}
+	return nil
+}
+
+// Check 返回密码不符合策略的原因, 符合时返回空字符串. 不修改共享的错误变量, 可以并发调用
+func (p *Policy) Check(password, account string) string {
+	if utf8.RuneCountInString(password) < p.opts.MinLength {
+		return fmt.Sprintf("密码长度不能少于 %d 位", p.opts.MinLength)
+	}
+	// 中文等字符占用多个字节
+	if p.opts.MaxLength > 0 && len(password) > p.opts.MaxLength {
+		return fmt.Sprintf("密码长度不能超过 %d 个字节", p.opts.MaxLength)
+	}
+
+	classes := charClasses(password)
+	for _, class := range p.opts.RequiredClasses {
+		if !classes[class] {
+			return "密码必须包含" + classNames[class]
+		}
+	}
+
+	if p.opts.DisallowAccount && account != "" &&
+		strings.Contains(strings.ToLower(password), strings.ToLower(account)) {
+		return "密码不能包含账号"
+	}
+
+	if p.opts.Breached != nil && p.opts.Breached.Contains(password) {
+		return "该密码已在公开的泄露数据中出现, 请更换"
+	}
+
+	if Strength(password) < p.opts.MinStrength {
+		return "密码强度太弱, 请避免使用常见单词、重复或连续的字符"
+	}
+	return ""
+}
+
+func charClasses(password string) map[string]bool {
+	classes := make(map[string]bool, len(classNames))
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			classes[ClassLower] = true
+		case unicode.IsUpper(r):
+			classes[ClassUpper] = true
+		case unicode.IsDigit(r):
+			classes[ClassDigit] = true
+		default:
+			classes[ClassSymbol] = true
+		}
+	}
+	return classes
+}
@@ -0,0 +1,74 @@
+package pwdpolicy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coderlewin/ucenter/pkg/errno"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	breached, err := LoadBreachedList(strings.NewReader("# 注释\nP@ssw0rd2024\n\nP@ssw0rd2024\nSummer#2023\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, breached.Len())
+
+	p, err := New(Options{
+		MinLength:       8,
+		MaxLength:       64,
+		RequiredClasses: []string{ClassLower, ClassDigit},
+		DisallowAccount: true,
+		MinStrength:     2,
+		Breached:        breached,
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		password string
+		account  string
+		wantDesc string
+	}{
+		{name: "符合策略", password: "kite7-mango-vault", account: "lewin"},
+		{name: "长度过短", password: "ab1", wantDesc: "密码长度不能少于 8 位"},
+		{name: "长度过长", password: strings.Repeat("a1", 33), wantDesc: "密码长度不能超过 64 个字节"},
+		// 22 个字符共 66 个字节
+		{name: "多字节字符按字节计算", password: strings.Repeat("风筝7", 11), wantDesc: "密码长度不能超过 64 个字节"},
+		{name: "最小长度按字符计算", password: "kite风筝7", wantDesc: "密码长度不能少于 8 位"},
+		{name: "缺少数字", password: "kite-mango-vault", wantDesc: "密码必须包含数字"},
+		{name: "包含账号", password: "xLewin-2024-kite", account: "lewin", wantDesc: "密码不能包含账号"},
+		{name: "已泄露", password: "P@ssw0rd2024", wantDesc: "该密码已在公开的泄露数据中出现, 请更换"},
+		{name: "强度太弱", password: "password123456", wantDesc: "密码强度太弱, 请避免使用常见单词、重复或连续的字符"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := p.Validate(tc.password, tc.account)
+			if tc.wantDesc == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, errno.ErrParameterInvalid, err)
+			assert.Equal(t, tc.wantDesc, errno.ErrParameterInvalid.Desc)
+		})
+	}
+}
+
+func TestStrength(t *testing.T) {
+	testCases := []struct {
+		password string
+		want     int
+	}{
+		{password: "12345678", want: 0},
+		{password: "Password1!", want: 0},
+		{password: "qwerty123456", want: 0},
+		{password: "lewin123", want: 1},
+		{password: "x7#Kp9!qLm2$", want: 4},
+		{password: "correct horse battery staple", want: 4},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.password, func(t *testing.T) {
+			assert.Equal(t, tc.want, Strength(tc.password))
+		})
+	}
+}
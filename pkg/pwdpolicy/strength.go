@@ -0,0 +1,89 @@
+package pwdpolicy
+
+import (
+	"math"
+	"strings"
+)
+
+// MaxStrength 密码强度的最大值
+const MaxStrength = 4
+
+// commonWords 密码中常见的单词和键盘序列, 出现时只按字典大小计算熵
+var commonWords = []string{
+	"password", "passwd", "qwerty", "asdfgh", "zxcvbn", "qazwsx", "1qaz2wsx",
+	"admin", "root", "user", "login", "welcome", "letmein", "iloveyou", "love",
+	"monkey", "dragon", "master", "sunshine", "princess", "football", "baseball",
+	"superman", "batman", "shadow", "trustno1", "hello", "secret", "abc", "test",
+}
+
+// strengthBits 各强度等级的最低熵, 单位 bit
+var strengthBits = [MaxStrength]float64{25, 40, 55, 70}
+
+// Strength 粗略估计密码强度, 取值 0-4.
+// 按字符集大小计算熵, 常见单词、重复字符和连续字符只计入很少的熵.
+func Strength(password string) int {
+	bits := entropy(password)
+	score := 0
+	for score < MaxStrength && bits >= strengthBits[score] {
+		score++
+	}
+	return score
+}
+
+func entropy(password string) float64 {
+	runes := []rune(password)
+	lower := []rune(strings.ToLower(password))
+	pool := poolSize(charClasses(password))
+	wordBits := math.Log2(float64(len(commonWords)))
+
+	var bits float64
+	for i := 0; i < len(runes); {
+		if n := matchWord(lower[i:]); n > 0 {
+			bits += wordBits
+			i += n
+			continue
+		}
+		if i > 0 && isPattern(lower[i-1], lower[i]) {
+			// 重复或连续的字符, 如 aaa、123、cba
+			bits += 1
+		} else {
+			bits += math.Log2(float64(pool))
+		}
+		i++
+	}
+	return bits
+}
+
+// matchWord 返回以 s 开头的最长常见单词的长度
+func matchWord(s []rune) int {
+	text := string(s)
+	longest := 0
+	for _, w := range commonWords {
+		if len(w) > longest && strings.HasPrefix(text, w) {
+			longest = len(w)
+		}
+	}
+	return longest
+}
+
+func isPattern(prev, cur rune) bool {
+	d := cur - prev
+	return d >= -1 && d <= 1
+}
+
+func poolSize(classes map[string]bool) int {
+	size := 0
+	if classes[ClassLower] {
+		size += 26
+	}
+	if classes[ClassUpper] {
+		size += 26
+	}
+	if classes[ClassDigit] {
+		size += 10
+	}
+	if classes[ClassSymbol] {
+		size += 33
+	}
+	return size
+}
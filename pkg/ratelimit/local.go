@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval 清理过期限流对象的间隔.
+const sweepInterval = time.Minute
+
+// NewLocalSlideWindowLimiter 创建基于内存的滑动窗口限流器, 限流状态只在当前实例内有效.
+func NewLocalSlideWindowLimiter() Limiter {
+	return &localSlideWindowLimiter{
+		windows: make(map[string]*localWindow),
+		now:     time.Now,
+	}
+}
+
+type localSlideWindowLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]*localWindow
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type localWindow struct {
+	// requests 窗口内放行的请求时间, 按时间升序
+	requests []time.Time
+	// expireAt 最后一个请求移出窗口的时间
+	expireAt time.Time
+}
+
+func (l *localSlideWindowLimiter) Limit(_ context.Context, key string, rule Rule) (Result, error) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	w, ok := l.windows[key]
+	if !ok {
+		w = &localWindow{}
+		l.windows[key] = w
+	}
+
+	// 移除窗口之外的请求
+	start := now.Add(-rule.Window)
+	i := 0
+	for i < len(w.requests) && !w.requests[i].After(start) {
+		i++
+	}
+	w.requests = w.requests[i:]
+
+	res := Result{Limit: rule.Limit}
+	if int64(len(w.requests)) < rule.Limit {
+		w.requests = append(w.requests, now)
+		w.expireAt = now.Add(rule.Window)
+		res.Allowed = true
+	}
+	res.Remaining = rule.Limit - int64(len(w.requests))
+	res.Reset = rule.Window
+	if len(w.requests) > 0 {
+		res.Reset = w.requests[0].Add(rule.Window).Sub(now)
+	}
+	return res, nil
+}
+
+// sweep 定期清理已经没有请求的限流对象, 避免内存无限增长
+func (l *localSlideWindowLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, w := range l.windows {
+		if !w.expireAt.After(now) {
+			delete(l.windows, key)
+		}
+	}
+}
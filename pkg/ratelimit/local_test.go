@@ -0,0 +1,43 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalSlideWindowLimiter_Limit(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewLocalSlideWindowLimiter().(*localSlideWindowLimiter)
+	limiter.now = func() time.Time { return now }
+	rule := Rule{Limit: 2, Window: time.Minute}
+
+	testCases := []struct {
+		name string
+		// 距离第一个请求的时间
+		offset        time.Duration
+		wantAllowed   bool
+		wantRemaining int64
+		wantReset     time.Duration
+	}{
+		{name: "第一个请求", offset: 0, wantAllowed: true, wantRemaining: 1, wantReset: time.Minute},
+		{name: "第二个请求", offset: 10 * time.Second, wantAllowed: true, wantRemaining: 0, wantReset: 50 * time.Second},
+		{name: "超过限制", offset: 30 * time.Second, wantAllowed: false, wantRemaining: 0, wantReset: 30 * time.Second},
+		{name: "第一个请求移出窗口", offset: time.Minute, wantAllowed: true, wantRemaining: 0, wantReset: 10 * time.Second},
+		{name: "仍然超过限制", offset: time.Minute + 5*time.Second, wantAllowed: false, wantRemaining: 0, wantReset: 5 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter.now = func() time.Time { return now.Add(tc.offset) }
+			res, err := limiter.Limit(context.Background(), "key", rule)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantAllowed, res.Allowed)
+			assert.Equal(t, tc.wantRemaining, res.Remaining)
+			assert.Equal(t, tc.wantReset, res.Reset)
+		})
+	}
+}
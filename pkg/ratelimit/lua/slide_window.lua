@@ -0,0 +1,28 @@
+-- KEYS[1]: 限流对象
+-- ARGV[1]: 窗口大小, 单位毫秒
+-- ARGV[2]: 窗口内允许的最大请求数
+-- ARGV[3]: 当前时间, 单位毫秒
+-- ARGV[4]: 本次请求的唯一标识
+-- 返回 {是否放行, 剩余请求数, 距离最早的请求过期的毫秒数}
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+-- 移除窗口之外的请求
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+    redis.call('ZADD', key, now, ARGV[4])
+    redis.call('PEXPIRE', key, window)
+    count = count + 1
+    allowed = 1
+end
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if #oldest > 0 then
+    reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, limit - count, reset}
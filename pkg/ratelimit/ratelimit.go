@@ -0,0 +1,46 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// Rule 定义一个限流窗口内允许的请求数.
+type Rule struct {
+	Limit  int64         // 窗口内允许的最大请求数
+	Window time.Duration // 滑动窗口大小
+}
+
+// Result 是一次限流判断的结果.
+type Result struct {
+	Allowed   bool          // 是否放行
+	Limit     int64         // 窗口内允许的最大请求数
+	Remaining int64         // 窗口内剩余的请求数
+	Reset     time.Duration // 距离窗口内最早的请求过期, 即恢复一个配额的时长
+}
+
+// Limiter 判断 key 对应的请求是否超过限流规则.
+type Limiter interface {
+	Limit(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+// NewFallbackLimiter 创建一个带降级的限流器, primary 出错时(如 Redis 不可用)使用 fallback 判断.
+func NewFallbackLimiter(primary Limiter, fallback Limiter) Limiter {
+	return &fallbackLimiter{primary: primary, fallback: fallback}
+}
+
+type fallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+}
+
+func (f *fallbackLimiter) Limit(ctx context.Context, key string, rule Rule) (Result, error) {
+	res, err := f.primary.Limit(ctx, key, rule)
+	if err == nil {
+		return res, nil
+	}
+	hlog.CtxWarnf(ctx, "rate limiter failed, fallback to local limiter, key=%s, err=%v", key, err)
+	return f.fallback.Limit(ctx, key, rule)
+}
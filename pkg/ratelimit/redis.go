@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+//go:embed lua/slide_window.lua
+var luaSlideWindow string
+
+// NewRedisSlideWindowLimiter 创建基于 Redis 有序集合的滑动窗口限流器, 多个实例共享限流状态.
+func NewRedisSlideWindowLimiter(cmd redis.Cmdable) Limiter {
+	return &redisSlideWindowLimiter{cmd: cmd, now: time.Now}
+}
+
+type redisSlideWindowLimiter struct {
+	cmd redis.Cmdable
+	now func() time.Time
+}
+
+func (r *redisSlideWindowLimiter) Limit(ctx context.Context, key string, rule Rule) (Result, error) {
+	now := r.now().UnixMilli()
+	res, err := r.cmd.Eval(ctx, luaSlideWindow, []string{key},
+		rule.Window.Milliseconds(), rule.Limit, now, fmt.Sprintf("%d:%s", now, uuid.NewString())).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(res) != 3 {
+		return Result{}, fmt.Errorf("ratelimit: unexpected script result %v", res)
+	}
+	return Result{
+		Allowed:   res[0] == 1,
+		Limit:     rule.Limit,
+		Remaining: res[1],
+		Reset:     time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,48 @@
+package sms
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidPhone 表示手机号格式错误.
+var ErrInvalidPhone = errors.New("sms: invalid phone number")
+
+// NormalizePhone 将手机号规范化为 E.164 格式, 如 +8613800138000.
+// 未携带国家码的号码使用 defaultCountryCode, 中国大陆号码额外校验 11 位手机号格式.
+func NormalizePhone(raw string, defaultCountryCode string) (string, error) {
+	var b strings.Builder
+	for i, ch := range strings.TrimSpace(raw) {
+		switch {
+		case ch >= '0' && ch <= '9':
+			b.WriteRune(ch)
+		case ch == '+' && i == 0:
+			b.WriteRune(ch)
+		case ch == ' ' || ch == '-' || ch == '(' || ch == ')' || ch == '.':
+			// 忽略常见的分隔符
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	phone := b.String()
+
+	switch {
+	case strings.HasPrefix(phone, "+"):
+	case strings.HasPrefix(phone, "00"):
+		phone = "+" + phone[2:]
+	default:
+		phone = "+" + defaultCountryCode + strings.TrimPrefix(phone, "0")
+	}
+
+	// E.164 最多 15 位数字, 国家码不以 0 开头
+	digits := phone[1:]
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+	if national, ok := strings.CutPrefix(digits, "86"); ok {
+		if len(national) != 11 || national[0] != '1' {
+			return "", ErrInvalidPhone
+		}
+	}
+	return phone, nil
+}
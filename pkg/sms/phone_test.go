@@ -0,0 +1,34 @@
+package sms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	testCases := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr error
+	}{
+		{name: "国内手机号", raw: "13800138000", want: "+8613800138000"},
+		{name: "带分隔符", raw: "138-0013 8000", want: "+8613800138000"},
+		{name: "带国家码", raw: "+86 138 0013 8000", want: "+8613800138000"},
+		{name: "00 开头的国际号码", raw: "0044 20 7946 0958", want: "+442079460958"},
+		{name: "国外号码", raw: "+1 (415) 555-2671", want: "+14155552671"},
+		{name: "国内号码位数错误", raw: "1380013800", wantErr: ErrInvalidPhone},
+		{name: "国内固话", raw: "+86 20 12345678", wantErr: ErrInvalidPhone},
+		{name: "包含字母", raw: "1380013800a", wantErr: ErrInvalidPhone},
+		{name: "加号不在开头", raw: "86+13800138000", wantErr: ErrInvalidPhone},
+		{name: "超过 15 位", raw: "+1234567890123456", wantErr: ErrInvalidPhone},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NormalizePhone(tc.raw, "86")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
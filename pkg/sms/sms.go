@@ -0,0 +1,60 @@
+// Package sms 定义发送短信的接口, 并提供用于开发和测试的实现.
+package sms
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// Sender 发送短信, 不同的短信服务商各自实现.
+type Sender interface {
+	// Send 向 E.164 格式的手机号发送短信
+	Send(ctx context.Context, phone string, content string) error
+}
+
+// NewLogSender 创建只打印日志的短信发送器, 用于本地开发.
+func NewLogSender() Sender {
+	return logSender{}
+}
+
+type logSender struct{}
+
+func (logSender) Send(ctx context.Context, phone string, content string) error {
+	hlog.CtxInfof(ctx, "[sms] phone=%s content=%s", phone, content)
+	return nil
+}
+
+// Message 是一条已发送的短信.
+type Message struct {
+	Phone   string
+	Content string
+}
+
+// MemorySender 只把短信保存在内存中, 用于测试.
+type MemorySender struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemorySender 创建内存短信发送器.
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+func (m *MemorySender) Send(_ context.Context, phone string, content string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, Message{Phone: phone, Content: content})
+	return nil
+}
+
+// Messages 返回已发送的全部短信.
+func (m *MemorySender) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := make([]Message, len(m.messages))
+	copy(res, m.messages)
+	return res
+}
@@ -0,0 +1,63 @@
+// Package spreadsheet 读写 CSV 和 XLSX 表格, 只提供导入导出数据需要的最小功能.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// 支持的表格格式.
+const (
+	FormatCSV   = "csv"
+	FormatXLSX  = "xlsx"
+	FormatJSONL = "jsonl" // 每行一个 JSON 对象, 只用于写入
+)
+
+// ErrTooManyRows 表格的行数超过限制.
+var ErrTooManyRows = errors.New("spreadsheet: too many rows")
+
+// Detect 按文件内容判断格式, XLSX 是 zip 压缩包, 其余按 CSV 处理.
+func Detect(data []byte) string {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return FormatXLSX
+	}
+	return FormatCSV
+}
+
+// Read 读取表格中的全部行, 包括表头. XLSX 只读取第一个工作表.
+// 行数超过 maxRows 时返回 ErrTooManyRows.
+func Read(data []byte, maxRows int) ([][]string, error) {
+	if Detect(data) == FormatXLSX {
+		return ReadXLSX(data, maxRows)
+	}
+	return ReadCSV(data, maxRows)
+}
+
+// ReadCSV 读取 UTF-8 编码的 CSV, 忽略 Excel 导出时添加的 BOM, 各行的列数可以不同.
+func ReadCSV(data []byte, maxRows int) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("spreadsheet: csv is not utf-8 encoded")
+	}
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	var rows [][]string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("spreadsheet: %w", err)
+		}
+		if len(rows) >= maxRows {
+			return nil, ErrTooManyRows
+		}
+		rows = append(rows, record)
+	}
+}
@@ -0,0 +1,121 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCSV(t *testing.T) {
+	data := []byte("\xef\xbb\xbfaccount,password\ncoderlu,\"a,b\"\n\nsingle\n")
+	rows, err := Read(data, 10)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"account", "password"}, {"coderlu", "a,b"}, {"single"}}, rows)
+
+	_, err = Read(data, 2)
+	assert.ErrorIs(t, err, ErrTooManyRows)
+	_, err = ReadCSV([]byte("\xff\xfe"), 10)
+	assert.Error(t, err)
+}
+
+func TestReadXLSX(t *testing.T) {
+	data := buildXLSX(t, map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="用户" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId3" Type="worksheet" Target="worksheets/users.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst><si><t>account</t></si><si><t>password</t></si>` +
+			`<si><r><t>coder</t></r><r><t>lu</t></r></si></sst>`,
+		"xl/worksheets/users.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>` +
+			`<row r="3"><c r="A3" t="s"><v>2</v></c><c r="C3" t="inlineStr"><is><t>inline</t></is></c><c r="D3"><v>12345</v></c></row>` +
+			`</sheetData></worksheet>`,
+	})
+	assert.Equal(t, FormatXLSX, Detect(data))
+
+	rows, err := Read(data, 10)
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"account", "password"}, nil, {"coderlu", "", "inline", "12345"}}, rows)
+
+	_, err = Read(data, 2)
+	assert.ErrorIs(t, err, ErrTooManyRows)
+}
+
+func TestColumnIndex(t *testing.T) {
+	testCases := map[string]int{"A1": 0, "Z9": 25, "AA10": 26, "XFD1": 16383}
+	for ref, want := range testCases {
+		got, err := columnIndex(ref)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, ref)
+	}
+	_, err := columnIndex("12")
+	assert.Error(t, err)
+}
+
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestWriter(t *testing.T) {
+	header := []string{"id", "username", "create_time"}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := [][]any{
+		{int64(1), "coderlu", created},
+		{int64(2), "=HYPERLINK(\"x\")", time.Time{}},
+	}
+
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, format, header)
+			require.NoError(t, err)
+			for _, row := range rows {
+				require.NoError(t, w.Write(row))
+			}
+			require.NoError(t, w.Close())
+
+			// 写入的文件可以再读出来
+			got, err := Read(buf.Bytes(), 10)
+			require.NoError(t, err)
+			want := [][]string{header, {"1", "coderlu", "2024-01-02T03:04:05Z"}, {"2", "=HYPERLINK(\"x\")"}}
+			if format == FormatCSV {
+				want[2] = []string{"2", "'=HYPERLINK(\"x\")", ""}
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+
+	t.Run(FormatJSONL, func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, FormatJSONL, header)
+		require.NoError(t, err)
+		for _, row := range rows {
+			require.NoError(t, w.Write(row))
+		}
+		require.NoError(t, w.Close())
+		assert.Equal(t, `{"id":1,"username":"coderlu","create_time":"2024-01-02T03:04:05Z"}`+"\n"+
+			`{"id":2,"username":"=HYPERLINK(\"x\")","create_time":""}`+"\n", buf.String())
+	})
+}
+
+func TestEscapeFormula(t *testing.T) {
+	assert.Equal(t, "+8613800000000", escapeFormula("+8613800000000"))
+	assert.Equal(t, "-1.5", escapeFormula("-1.5"))
+	assert.Equal(t, "'-cmd", escapeFormula("-cmd"))
+	assert.Equal(t, "'@SUM(A1)", escapeFormula("@SUM(A1)"))
+	assert.Equal(t, "coderlu", escapeFormula("coderlu"))
+}
@@ -0,0 +1,224 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxPartSize 解压后单个 XML 文件的最大字节数, 防止压缩炸弹.
+const maxPartSize = 64 << 20
+
+// ReadXLSX 读取 XLSX 第一个工作表中的全部行, 空行保留为空切片, 使行号与表格一致.
+// 只读取单元格的值, 公式取缓存的计算结果, 日期等数字格式不做转换.
+func ReadXLSX(data []byte, maxRows int) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("spreadsheet: invalid xlsx: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var sharedStrings []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if sharedStrings, err = readSharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+	sheet, ok := files[firstSheetPath(files)]
+	if !ok {
+		return nil, fmt.Errorf("spreadsheet: xlsx has no worksheet")
+	}
+	return readSheet(sheet, sharedStrings, maxRows)
+}
+
+// firstSheetPath 通过 workbook.xml 和它的关系文件找到第一个工作表, 找不到时使用默认路径.
+func firstSheetPath(files map[string]*zip.File) string {
+	const fallback = "xl/worksheets/sheet1.xml"
+	var workbook struct {
+		Sheets []struct {
+			RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	wf, ok1 := files["xl/workbook.xml"]
+	rf, ok2 := files["xl/_rels/workbook.xml.rels"]
+	if !ok1 || !ok2 || decodePart(wf, &workbook) != nil || decodePart(rf, &rels) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].RID {
+			continue
+		}
+		// Target 一般相对于 xl 目录, 也可能是以 / 开头的绝对路径
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/")
+		}
+		return path.Join("xl", rel.Target)
+	}
+	return fallback
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	var sst struct {
+		Items []struct {
+			T string `xml:"t"`
+			R []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := decodePart(f, &sst); err != nil {
+		return nil, err
+	}
+	res := make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		// 富文本由多段 r 组成
+		if len(item.R) > 0 {
+			var sb strings.Builder
+			for _, r := range item.R {
+				sb.WriteString(r.T)
+			}
+			res[i] = sb.String()
+			continue
+		}
+		res[i] = item.T
+	}
+	return res, nil
+}
+
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+type xlsxRow struct {
+	Num   int        `xml:"r,attr"`
+	Cells []xlsxCell `xml:"c"`
+}
+
+func readSheet(f *zip.File, sharedStrings []string, maxRows int) ([][]string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// 逐行解码, 不需要把整个工作表读入内存
+	dec := xml.NewDecoder(io.LimitReader(rc, maxPartSize))
+	var rows [][]string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("spreadsheet: invalid worksheet: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row xlsxRow
+		if err = dec.DecodeElement(&row, &start); err != nil {
+			return nil, fmt.Errorf("spreadsheet: invalid worksheet: %w", err)
+		}
+		num := row.Num
+		if num <= len(rows) {
+			num = len(rows) + 1
+		}
+		if num > maxRows {
+			return nil, ErrTooManyRows
+		}
+		for len(rows) < num-1 {
+			rows = append(rows, nil)
+		}
+		record, err := rowValues(row, sharedStrings)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, record)
+	}
+}
+
+func rowValues(row xlsxRow, sharedStrings []string) ([]string, error) {
+	var record []string
+	for _, cell := range row.Cells {
+		col := len(record)
+		if cell.Ref != "" {
+			var err error
+			if col, err = columnIndex(cell.Ref); err != nil {
+				return nil, err
+			}
+		}
+		// 单元格的列数不能超过 Excel 的上限 XFD
+		if col >= 16384 {
+			return nil, fmt.Errorf("spreadsheet: invalid cell reference %q", cell.Ref)
+		}
+		for len(record) < col {
+			record = append(record, "")
+		}
+		var value string
+		switch cell.Type {
+		case "s":
+			i, err := strconv.Atoi(cell.Value)
+			if err != nil || i < 0 || i >= len(sharedStrings) {
+				return nil, fmt.Errorf("spreadsheet: invalid shared string index %q", cell.Value)
+			}
+			value = sharedStrings[i]
+		case "inlineStr":
+			value = cell.Inline
+		default:
+			value = cell.Value
+		}
+		if col < len(record) {
+			record[col] = value
+		} else {
+			record = append(record, value)
+		}
+	}
+	return record, nil
+}
+
+// columnIndex 将 B2 这样的单元格引用转换为从 0 开始的列号.
+func columnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		n++
+	}
+	if n == 0 || n > 3 {
+		return 0, fmt.Errorf("spreadsheet: invalid cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+func decodePart(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err = xml.NewDecoder(io.LimitReader(rc, maxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("spreadsheet: invalid %s: %w", f.Name, err)
+	}
+	return nil
+}
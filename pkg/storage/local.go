@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LocalOptions 定义本地存储的参数.
+type LocalOptions struct {
+	Dir     string // 保存文件的目录
+	BaseURL string // 访问地址的前缀, 如 http://localhost:8080/uploads
+}
+
+// NewLocalStorage 创建本地文件系统存储, 目录不存在时自动创建.
+// 文件需要由 Web 服务器以 BaseURL 对外提供访问.
+func NewLocalStorage(opts LocalOptions) (BlobStorage, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("storage: empty dir")
+	}
+	dir, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &localStorage{dir: dir, baseURL: opts.BaseURL}, nil
+}
+
+type localStorage struct {
+	dir     string
+	baseURL string
+}
+
+func (l *localStorage) Put(_ context.Context, key string, data []byte, _ string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	name := filepath.Join(l.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	// 先写临时文件再重命名, 避免读到写了一半的文件
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+func (l *localStorage) Delete(_ context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(l.dir, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *localStorage) URL(key string) string {
+	return joinURL(l.baseURL, key)
+}
@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Options 定义 S3 兼容存储的参数, 也适用于 MinIO 等兼容服务.
+type S3Options struct {
+	Endpoint  string // 服务地址, 如 https://s3.us-east-1.amazonaws.com 或 http://localhost:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle 为 true 时使用 endpoint/bucket/key 形式的地址, MinIO 等本地服务通常需要开启
+	PathStyle bool
+	// BaseURL 访问地址的前缀, 为空时使用存储桶地址, 通过 CDN 访问时设置为 CDN 地址
+	BaseURL string
+	Timeout time.Duration
+}
+
+// NewS3Storage 创建 S3 兼容存储, 请求使用 AWS Signature Version 4 签名.
+// 存储桶需要允许匿名读取, 或者通过 BaseURL 指向的 CDN 对外提供访问.
+func NewS3Storage(opts S3Options) (BlobStorage, error) {
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("storage: invalid s3 endpoint %q", opts.Endpoint)
+	}
+	if opts.Bucket == "" || opts.Region == "" {
+		return nil, fmt.Errorf("storage: s3 bucket and region are required")
+	}
+	if opts.AccessKey == "" || opts.SecretKey == "" {
+		return nil, fmt.Errorf("storage: s3 access key and secret key are required")
+	}
+	bucketURL := *endpoint
+	bucketURL.Path = strings.TrimRight(bucketURL.Path, "/")
+	if opts.PathStyle {
+		bucketURL.Path += "/" + opts.Bucket
+	} else {
+		bucketURL.Host = opts.Bucket + "." + bucketURL.Host
+	}
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = bucketURL.String()
+	}
+	return &s3Storage{
+		opts:      opts,
+		bucketURL: &bucketURL,
+		baseURL:   baseURL,
+		client:    &http.Client{Timeout: opts.Timeout},
+		now:       time.Now,
+	}, nil
+}
+
+type s3Storage struct {
+	opts      S3Options
+	bucketURL *url.URL
+	baseURL   string
+	client    *http.Client
+	now       func() time.Time
+}
+
+func (s *s3Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	sum := sha256.Sum256(data)
+	return s.do(req, hex.EncodeToString(sum[:]))
+}
+
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	// 对象不存在时 S3 同样返回 204
+	return s.do(req, emptyPayloadHash)
+}
+
+func (s *s3Storage) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
+
+func (s *s3Storage) objectURL(key string) string {
+	u := *s.bucketURL
+	u.Path += "/" + key
+	return u.String()
+}
+
+func (s *s3Storage) do(req *http.Request, payloadHash string) error {
+	signV4(req, payloadHash, s.opts.Region, s.opts.AccessKey, s.opts.SecretKey, s.now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("storage: s3 %s %s: %s %s", req.Method, req.URL.Path, resp.Status, body)
+	}
+	return nil
+}
+
+// emptyPayloadHash 空请求体的 SHA256.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// signV4 按 AWS Signature Version 4 为请求添加 Authorization 请求头, 签名包含 host 和全部已设置的请求头.
+func signV4(req *http.Request, payloadHash, region, accessKey, secretKey string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape 除 A-Z a-z 0-9 - _ . ~ 外全部编码, 空格编码为 %20.
+func awsEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,41 @@
+// Package storage 定义保存文件的接口, 提供本地文件系统和 S3 兼容两种实现.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrInvalidKey key 为空、以 / 开头或包含 .. 等路径片段.
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// BlobStorage 按 key 保存文件, key 使用 / 分隔, 如 avatars/1/a.png.
+type BlobStorage interface {
+	// Put 保存文件, key 已存在时覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete 删除文件, key 不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// URL 返回文件的公开访问地址
+	URL(key string) string
+}
+
+// checkKey 拒绝可能逃逸出存储目录的 key.
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key {
+		return fmt.Errorf("%w %q", ErrInvalidKey, key)
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "." || seg == ".." {
+			return fmt.Errorf("%w %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// joinURL 拼接访问地址的前缀和 key.
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}